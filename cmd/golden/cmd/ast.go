@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/astjson"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/helpers/debug"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
+
+type Ast struct{}
+
+func (c *Ast) Name() string {
+	return "ast"
+}
+
+func (c *Ast) Description() string {
+	return "Prints the typed AST of the modules"
+}
+
+func (c *Ast) Help() string {
+	return "Prints the typed AST of each module of the project, use --json to print a versioned JSON document per module"
+}
+
+func (c *Ast) Run() error {
+	flagJson := flag.Bool("json", false, "print the AST as JSON")
+	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no file specified")
+	}
+
+	file, _ := fs.GetAbsolutePath(args[0])
+	opts := builder.NewBuildOptions(file)
+	opts.NoCache = true
+	if !*flagJson {
+		opts.OnWarning.Subscribe(printWarning)
+	}
+	opts.OnTypeCheckReady.Subscribe(func(mod *builder.File, root *ast.Module, scope *env.Scope) {
+		if !*flagJson {
+			debug.PrettyPrintAst(mod, root)
+			return
+		}
+
+		data, err := astjson.Encode(root)
+		if err != nil {
+			errors.Rethrow(err)
+		}
+		fmt.Println(string(data))
+	})
+
+	if flagWorkingDir != nil {
+		abs, _ := fs.GetAbsolutePath(*flagWorkingDir)
+		opts.WorkingDir = abs
+	}
+
+	b := builder.NewBuilder(opts)
+	if _, err := b.Check(); err != nil {
+		errors.PrettyPrint(err)
+	}
+	return nil
+}
@@ -31,8 +31,12 @@ func (c *Build) Run() error {
 	flagDebug := flag.Bool("debug", false, "enable debug information")
 	flagLevel := flag.String("log-level", "error", "log level")
 	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flagEmit := flag.String("emit", "", "print an intermediate representation (ir)")
 	flagTarget := flag.String("target", "go", "output backend")
 	flagOutput := flag.String("output", "", "output file")
+	flagOptimizations := registerOptimizationFlags()
+	flagCache := registerCacheFlags()
+	flagDiagnostics := registerDiagnosticFlags()
 	flag.Parse()
 
 	args := flag.Args()
@@ -51,6 +55,21 @@ func (c *Build) Run() error {
 		opts.OnTypeCheckReady.Subscribe(printTypedAst)
 	}
 
+	switch *flagEmit {
+	case "":
+	case "ir":
+		opts.OnIRReady.Subscribe(debug.PrettyPrintIR)
+	default:
+		return fmt.Errorf("unknown emit option %s", *flagEmit)
+	}
+
+	flagOptimizations.apply(opts)
+	flagCache.apply(opts)
+	if err := flagDiagnostics.apply(opts); err != nil {
+		return err
+	}
+	opts.OnWarning.Subscribe(printWarning)
+
 	if flagTarget != nil {
 		switch *flagTarget {
 		case "go":
@@ -77,6 +96,8 @@ func (c *Build) Run() error {
 		errors.PrettyPrint(err)
 		return nil
 	}
+	flagOptimizations.report(res)
+	flagCache.report(res)
 	fmt.Println("Build completed in", res.Elapsed)
 	return nil
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+	"github.com/renatopp/golden/internal/helpers/logger"
+)
+
+type Check struct{}
+
+func (c *Check) Name() string {
+	return "check"
+}
+
+func (c *Check) Description() string {
+	return "Checks the project without building it"
+}
+
+func (c *Check) Help() string {
+	return "Parses and checks the types of the project without generating code, exiting with an error if any diagnostic is reported. Projects without a main function are checked as libraries"
+}
+
+func (c *Check) Run() error {
+	flagLevel := flag.String("log-level", "error", "log level")
+	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flagDiagnostics := registerDiagnosticFlags()
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no file specified")
+	}
+
+	logger.SetLevel(logger.LevelFromString(*flagLevel))
+
+	file, _ := fs.GetAbsolutePath(args[0])
+	opts := builder.NewBuildOptions(file)
+	if err := flagDiagnostics.apply(opts); err != nil {
+		return err
+	}
+
+	diagnostics := 0
+	opts.OnWarning.Subscribe(func(w errors.GoldenError) {
+		diagnostics++
+		printWarning(w)
+	})
+
+	if flagWorkingDir != nil {
+		abs, _ := fs.GetAbsolutePath(*flagWorkingDir)
+		opts.WorkingDir = abs
+	}
+
+	res, err := builder.NewBuilder(opts).Check()
+	if err != nil {
+		diagnostics++
+		errors.PrettyPrint(err)
+		fmt.Print("\n\n")
+	}
+	if diagnostics > 0 {
+		return fmt.Errorf("check failed with %d diagnostic(s)", diagnostics)
+	}
+	fmt.Println("Check completed in", res.Elapsed)
+	return nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/astjson"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/debug"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
+
+// Stages of the compiler that can be printed, with their supported formats.
+var debugStages = map[string][]debug.Format{
+	"tokens": {debug.FormatText, debug.FormatJson, debug.FormatSexpr},
+	"ast":    debug.Formats,
+	"deps":   debug.Formats,
+	"typed":  debug.Formats,
+	"scopes": {debug.FormatText, debug.FormatJson, debug.FormatSexpr},
+	"ir":     debug.Formats,
+}
+
+type Debug struct{}
+
+func (c *Debug) Name() string {
+	return "debug"
+}
+
+func (c *Debug) Description() string {
+	return "Prints a stage of the compiler"
+}
+
+func (c *Debug) Help() string {
+	return "Prints a stage of the compiler for each module of the project: golden debug tokens|ast|scopes|deps|typed|ir [--format text|json|sexpr|dot] <file>"
+}
+
+func (c *Debug) Run() error {
+	flagFormat := flag.String("format", "text", "output format: text, json, sexpr or dot")
+	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flagOptimizations := registerOptimizationFlags()
+	flag.Parse()
+
+	// the stage comes before the flags, which are parsed again after it
+	args := flag.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no stage specified")
+	}
+	stage := args[0]
+	if err := flag.CommandLine.Parse(args[1:]); err != nil {
+		return err
+	}
+	args = flag.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no file specified")
+	}
+
+	formats, ok := debugStages[stage]
+	if !ok {
+		return fmt.Errorf("unknown stage '%s', expected one of tokens, ast, scopes, deps, typed or ir", stage)
+	}
+	format, ok := debug.ParseFormat(*flagFormat)
+	if !ok || !slices.Contains(formats, format) {
+		names := []string{}
+		for _, f := range formats {
+			names = append(names, string(f))
+		}
+		return fmt.Errorf("stage '%s' cannot be printed as '%s', expected one of %s", stage, *flagFormat, strings.Join(names, ", "))
+	}
+
+	file, _ := fs.GetAbsolutePath(args[0])
+	opts := builder.NewBuildOptions(file)
+	flagOptimizations.apply(opts)
+	if format == debug.FormatText {
+		opts.OnWarning.Subscribe(printWarning)
+	}
+
+	out := os.Stdout
+	must := func(err error) {
+		if err != nil {
+			errors.Rethrow(err)
+		}
+	}
+	// modules of the standard library are only shown in the dependency graph
+	switch stage {
+	case "tokens":
+		opts.OnTokensReady.Subscribe(func(mod *builder.File, tokens []*token.Token) {
+			if !fs.IsStdPath(mod.Path) {
+				must(debug.PrintTokens(out, mod, tokens, format))
+			}
+		})
+	case "ast":
+		opts.OnAstReady.Subscribe(func(mod *builder.File, root *ast.Module) {
+			if !fs.IsStdPath(mod.Path) {
+				must(printDebugAst(mod, root, format))
+			}
+		})
+	case "deps":
+		opts.OnDependencyGraphReady.Subscribe(func(order []*builder.File) {
+			must(debug.PrintDependencyGraph(out, order, format))
+		})
+	case "typed":
+		opts.OnTypeCheckReady.Subscribe(func(mod *builder.File, root *ast.Module, _ *env.Scope) {
+			if !fs.IsStdPath(mod.Path) {
+				must(printDebugAst(mod, root, format))
+			}
+		})
+	case "scopes":
+		opts.OnTypeCheckReady.Subscribe(func(mod *builder.File, _ *ast.Module, scope *env.Scope) {
+			if !fs.IsStdPath(mod.Path) {
+				must(debug.PrintScope(out, mod, scope, format))
+			}
+		})
+	case "ir":
+		opts.OnIRReady.Subscribe(func(file *builder.File, mod *ir.Module) {
+			if !fs.IsStdPath(file.Path) {
+				must(debug.PrintIR(out, mod, format))
+			}
+		})
+	}
+
+	if flagWorkingDir != nil {
+		abs, _ := fs.GetAbsolutePath(*flagWorkingDir)
+		opts.WorkingDir = abs
+	}
+
+	b := builder.NewBuilder(opts)
+	var err error
+	if stage == "ir" {
+		_, err = b.Lower()
+	} else {
+		_, err = b.Check()
+	}
+	if err != nil {
+		errors.PrettyPrint(err)
+		fmt.Print("\n\n")
+		return fmt.Errorf("could not print the %s stage", stage)
+	}
+	return nil
+}
+
+func printDebugAst(mod *builder.File, root *ast.Module, format debug.Format) error {
+	if format != debug.FormatJson {
+		return debug.PrintAst(os.Stdout, mod.Path, root, format)
+	}
+
+	data, err := astjson.Encode(root)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+type Explain struct{}
+
+func (c *Explain) Name() string {
+	return "explain"
+}
+
+func (c *Explain) Description() string {
+	return "Explains an error code"
+}
+
+func (c *Explain) Help() string {
+	return "Prints the detailed explanation of an error code, such as G0301, with examples. Without a code, lists all the codes"
+}
+
+func (c *Explain) Run() error {
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		for _, code := range errors.Codes() {
+			fmt.Printf("%s  %s\n", code, code.Name())
+		}
+		return nil
+	}
+
+	code, ok := errors.ParseCode(args[0])
+	if !ok {
+		return fmt.Errorf("unknown error code '%s'", args[0])
+	}
+	text, ok := code.Explain()
+	if !ok {
+		return fmt.Errorf("no explanation for error code '%s'", code)
+	}
+	fmt.Print(text)
+	return nil
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/helpers/diff"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
+
+// Maximum number of times the project is checked again after applying fixes.
+// Each round fixes at least one diagnostic, but the compilation stops at the
+// first error, so errors are fixed one per round.
+const maxFixRounds = 50
+
+type Fix struct{}
+
+func (c *Fix) Name() string {
+	return "fix"
+}
+
+func (c *Fix) Description() string {
+	return "Applies the suggested fixes"
+}
+
+func (c *Fix) Help() string {
+	return "Applies the fixes suggested by the diagnostics of the project and prints the changes, use --dry-run to only print them. Fails when diagnostics without fixes remain"
+}
+
+func (c *Fix) Run() error {
+	flagDryRun := flag.Bool("dry-run", false, "print the changes without writing them")
+	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no file specified")
+	}
+
+	file, _ := fs.GetAbsolutePath(args[0])
+	workingDir, _ := fs.GetAbsolutePath(*flagWorkingDir)
+
+	// fixes are applied to the overlay and checked again, the files are only
+	// written after the last round, keeping the fixes even when diagnostics
+	// without fixes remain
+	original := map[string][]byte{}
+	overlay := map[string][]byte{}
+	applied := 0
+	var remaining []errors.GoldenError
+	for round := 0; ; round++ {
+		remaining = checkWithOverlay(file, workingDir, overlay)
+		if round == maxFixRounds {
+			break
+		}
+		diagnostics := remaining
+
+		edits := map[string][]errors.Edit{}
+		chosen := []errors.Edit{}
+		for _, d := range diagnostics {
+			if len(d.Fixes) == 0 || d.Fixes[0].Overlaps(chosen) {
+				continue
+			}
+			fix := d.Fixes[0]
+			// only files of the project are changed
+			if slices.ContainsFunc(fix.Edits, func(e errors.Edit) bool { return !fs.IsPathInside(workingDir, e.Loc.Filename) }) {
+				continue
+			}
+			chosen = append(chosen, fix.Edits...)
+			for _, e := range fix.Edits {
+				edits[e.Loc.Filename] = append(edits[e.Loc.Filename], e)
+			}
+			fmt.Printf("%s: %s\n", d.Code, fix.Msg)
+			applied++
+		}
+		if len(chosen) == 0 {
+			break
+		}
+
+		for path, fileEdits := range edits {
+			source, ok := overlay[path]
+			if !ok {
+				bytes, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				original[path] = bytes
+				source = bytes
+			}
+			fixed, err := errors.ApplyEdits(source, fileEdits)
+			if err != nil {
+				return err
+			}
+			overlay[path] = fixed
+		}
+	}
+
+	paths := []string{}
+	for path := range overlay {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		fmt.Println()
+		fmt.Print(diff.Unified(path, string(original[path]), string(overlay[path]), 3))
+		if !*flagDryRun {
+			if err := os.WriteFile(path, overlay[path], 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case applied == 0:
+		fmt.Println("No fixes to apply")
+	case *flagDryRun:
+		fmt.Printf("%d fix(es) to apply in %d file(s)\n", applied, len(paths))
+	default:
+		fmt.Printf("Applied %d fix(es) in %d file(s)\n", applied, len(paths))
+	}
+
+	if len(remaining) > 0 {
+		fmt.Println()
+		for _, d := range remaining {
+			printWarning(d)
+		}
+		return fmt.Errorf("%d diagnostic(s) remain without fixes", len(remaining))
+	}
+	return nil
+}
+
+// Checks the project with the overlay, returning the warnings and the error,
+// if any, as diagnostics.
+func checkWithOverlay(file, workingDir string, overlay map[string][]byte) []errors.GoldenError {
+	opts := builder.NewBuildOptions(file)
+	opts.WorkingDir = workingDir
+	opts.Overlay = overlay
+	opts.NoCache = true
+
+	diagnostics := []errors.GoldenError{}
+	opts.OnWarning.Subscribe(func(w errors.GoldenError) { diagnostics = append(diagnostics, w) })
+
+	_, err := builder.NewBuilder(opts).Check()
+	if err != nil {
+		diagnostics = append(diagnostics, errors.ToGoldenError(err))
+	}
+	return diagnostics
+}
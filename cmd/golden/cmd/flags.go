@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/renatopp/golden/internal/helpers/debug"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/str"
+)
+
+// Flags controlling the optimization passes, shared by build and run.
+type optimizationFlags struct {
+	o0         *bool
+	o1         *bool
+	o2         *bool
+	passes     *string
+	printAfter *string
+	timePasses *bool
+	reportDead *bool
+}
+
+func registerOptimizationFlags() *optimizationFlags {
+	return &optimizationFlags{
+		o0:         flag.Bool("O0", false, "disable optimizations, only lowering passes run"),
+		o1:         flag.Bool("O1", false, "enable the default optimizations"),
+		o2:         flag.Bool("O2", false, "enable aggressive optimizations"),
+		passes:     flag.String("passes", "", "comma-separated list of passes to run, replacing the optimization level"),
+		printAfter: flag.String("print-after", "", "comma-separated list of passes to print the AST after, use 'ir' to print the IR"),
+		timePasses: flag.Bool("time-passes", false, "print the time spent in each pass"),
+		reportDead: flag.Bool("report-dead-code", false, "print the code removed by dead code elimination"),
+	}
+}
+
+func (f *optimizationFlags) apply(opts *builder.BuildOptions) {
+	switch {
+	case *f.o0:
+		opts.OptimizationLevel = optimizations.LevelNone
+	case *f.o1:
+		opts.OptimizationLevel = optimizations.LevelDefault
+	case *f.o2:
+		opts.OptimizationLevel = optimizations.LevelAggressive
+	}
+
+	opts.Passes = splitList(*f.passes)
+
+	printAfter := splitList(*f.printAfter)
+	if slices.Contains(printAfter, "ir") {
+		opts.OnIRReady.Subscribe(debug.PrettyPrintIR)
+	}
+	if *f.reportDead {
+		opts.OnPassReady.Subscribe(printDeadCodeReport)
+	}
+	if len(printAfter) > 0 {
+		opts.OnPassReady.Subscribe(func(file *builder.File, r *optimizations.PassReport) {
+			if slices.Contains(printAfter, r.Pass.Name) {
+				printPassReport(file, r)
+			}
+		})
+	}
+}
+
+func (f *optimizationFlags) report(res *builder.BuildResult) {
+	if !*f.timePasses || res == nil {
+		return
+	}
+
+	fmt.Println("Pass timings:")
+	for _, t := range res.PassTimings {
+		fmt.Printf("- %s %s\n", str.PadRight(t.Pass.Name, 20), t.Elapsed)
+	}
+	println()
+}
+
+// Flags controlling the module cache, shared by build and run.
+type cacheFlags struct {
+	noCache *bool
+	stats   *bool
+}
+
+func registerCacheFlags() *cacheFlags {
+	return &cacheFlags{
+		noCache: flag.Bool("no-cache", false, "compile every module from the source, ignoring the module cache, which is only used when no whole-program pass is enabled, as with -O0"),
+		stats:   flag.Bool("cache-stats", false, "print the number of modules reused from the module cache"),
+	}
+}
+
+func (f *cacheFlags) apply(opts *builder.BuildOptions) {
+	opts.NoCache = *f.noCache
+}
+
+func (f *cacheFlags) report(res *builder.BuildResult) {
+	if !*f.stats || res == nil {
+		return
+	}
+	fmt.Printf("Module cache: %d hit(s), %d miss(es)\n", res.CacheHits, res.CacheMisses)
+}
+
+// Flags controlling the reported diagnostics, shared by build and run.
+type diagnosticFlags struct {
+	suppress *string
+}
+
+func registerDiagnosticFlags() *diagnosticFlags {
+	return &diagnosticFlags{
+		suppress: flag.String("suppress", "", "comma-separated list of warning codes to suppress, such as G0601"),
+	}
+}
+
+func (f *diagnosticFlags) apply(opts *builder.BuildOptions) error {
+	for _, item := range splitList(*f.suppress) {
+		code, ok := errors.ParseCode(item)
+		if !ok {
+			return fmt.Errorf("unknown diagnostic code '%s'", item)
+		}
+		opts.Suppress = append(opts.Suppress, code)
+	}
+	return nil
+}
+
+func printPassReport(file *builder.File, r *optimizations.PassReport) {
+	fmt.Printf("After pass '%s' (%s):\n", r.Pass.Name, r.Elapsed)
+	for _, remark := range r.Remarks {
+		fmt.Printf("- %s\n", remark)
+	}
+	debug.PrettyPrintAst(file, r.Module)
+}
+
+func printDeadCodeReport(file *builder.File, r *optimizations.PassReport) {
+	if r.Pass.Name != "dce" || len(r.Remarks) == 0 {
+		return
+	}
+	fmt.Printf("Dead code removed from %s:\n", file.Path)
+	for _, remark := range r.Remarks {
+		fmt.Printf("- %s\n", remark)
+	}
+	println()
+}
+
+func splitList(s string) []string {
+	res := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
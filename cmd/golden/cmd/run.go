@@ -37,7 +37,11 @@ func (c *Run) Run() error {
 	flagDebug := flag.Bool("debug", false, "enable debug information")
 	flagLevel := flag.String("log-level", "error", "log level")
 	flagWorkingDir := flag.String("working-dir", ".", "working directory")
+	flagEmit := flag.String("emit", "", "print an intermediate representation (ir)")
 	flagTarget := flag.String("target", "eval", "output backend")
+	flagOptimizations := registerOptimizationFlags()
+	flagCache := registerCacheFlags()
+	flagDiagnostics := registerDiagnosticFlags()
 	flag.Parse()
 
 	args := flag.Args()
@@ -57,6 +61,21 @@ func (c *Run) Run() error {
 		opts.OnOptimizationReady.Subscribe(debug.PrettyPrintAst)
 	}
 
+	switch *flagEmit {
+	case "":
+	case "ir":
+		opts.OnIRReady.Subscribe(debug.PrettyPrintIR)
+	default:
+		return fmt.Errorf("unknown emit option %s", *flagEmit)
+	}
+
+	flagOptimizations.apply(opts)
+	flagCache.apply(opts)
+	if err := flagDiagnostics.apply(opts); err != nil {
+		return err
+	}
+	opts.OnWarning.Subscribe(printWarning)
+
 	if flagTarget != nil {
 		switch *flagTarget {
 		case "go":
@@ -81,6 +100,8 @@ func (c *Run) Run() error {
 		errors.PrettyPrint(err)
 		return nil
 	}
+	flagOptimizations.report(res)
+	flagCache.report(res)
 	fmt.Println("Run completed in", res.Elapsed)
 
 	return nil
@@ -96,6 +117,11 @@ func printDependencyGraph(order []*builder.File) {
 	println()
 }
 
+func printWarning(w errors.GoldenError) {
+	errors.PrettyPrint(w)
+	fmt.Print("\n\n")
+}
+
 func printTypedAst(mod *builder.File, a *ast.Module, scope *env.Scope) {
 	debug.PrettyPrintAst(mod, a)
 	debug.PrettyPrintScope(scope)
@@ -18,7 +18,11 @@ var commands = []Command{
 	&cmd.Version{},
 	&cmd.Build{},
 	&cmd.Run{},
-	// &cmd.Debug{},
+	&cmd.Check{},
+	&cmd.Ast{},
+	&cmd.Explain{},
+	&cmd.Fix{},
+	&cmd.Debug{},
 }
 
 func main() {
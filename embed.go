@@ -1,8 +1,14 @@
 package golden
 
 import (
-	_ "embed"
+	"embed"
 )
 
 //go:embed VERSION
 var Version string
+
+// Modules of the standard library, imported as `@std/<name>`. The prelude is
+// imported implicitly by every module outside of the library.
+//
+//go:embed std/*.gold
+var Std embed.FS
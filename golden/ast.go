@@ -0,0 +1,30 @@
+package golden
+
+import "github.com/renatopp/golden/internal/compiler/ast"
+
+// Nodes of the typed AST. The types of the nodes are given by `GetType`, and
+// their locations by `GetSpan`, with the absolute paths of the modules in the
+// project root.
+type (
+	Node         = ast.Node
+	Type         = ast.Type
+	ModuleNode   = ast.Module
+	Import       = ast.Import
+	VarDecl      = ast.VarDecl
+	Int          = ast.Int
+	Float        = ast.Float
+	String       = ast.String
+	Bool         = ast.Bool
+	VarIdent     = ast.VarIdent
+	TypeIdent    = ast.TypeIdent
+	BinOp        = ast.BinOp
+	UnaryOp      = ast.UnaryOp
+	Block        = ast.Block
+	FnDecl       = ast.FnDecl
+	ExternFnDecl = ast.ExternFnDecl
+	FnDeclParam  = ast.FnDeclParam
+	TypeFn       = ast.TypeFn
+	Application  = ast.Application
+	Access       = ast.Access
+	Return       = ast.Return
+)
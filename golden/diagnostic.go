@@ -0,0 +1,109 @@
+package golden
+
+import (
+	"fmt"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Span of the source, with lines and columns starting at 1. The end is
+// exclusive.
+type Span struct {
+	File       string // Path of the module, as in Module.Path
+	FromLine   int
+	FromColumn int
+	ToLine     int
+	ToColumn   int
+}
+
+// Label points to a secondary location of the diagnostic, such as the
+// previous definition of a name.
+type Label struct {
+	Span    Span
+	Message string
+}
+
+// Diagnostic is an error or warning of the compiler, or an error raised when
+// running the program.
+type Diagnostic struct {
+	Code     string // ex: `G0302`, explained by `golden explain G0302`
+	Name     string // Short description of the code, ex: `type mismatch`
+	Severity Severity
+	Message  string
+	Span     *Span // Nil when the diagnostic has no location
+	Labels   []Label
+	Notes    []string
+	Help     string
+
+	rendered string
+}
+
+// Returns the diagnostic in the `file:line:column: error[code]: message`
+// format.
+func (d *Diagnostic) Error() string {
+	msg := fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+	if d.Span == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", d.Span.File, d.Span.FromLine, d.Span.FromColumn, msg)
+}
+
+// Returns the diagnostic as printed by the command line, with the excerpts of
+// the source.
+func (d *Diagnostic) Render() string {
+	return d.rendered
+}
+
+func (p *Program) diagnostic(err error) *Diagnostic {
+	e := errors.ToGoldenError(err)
+	d := &Diagnostic{
+		Code:     e.Code.String(),
+		Name:     e.Code.Name(),
+		Severity: SeverityError,
+		Message:  e.Msg,
+		Notes:    e.Notes,
+		Help:     e.Help,
+	}
+	if e.IsWarning() {
+		d.Severity = SeverityWarning
+	}
+	if loc := e.Loc.Or(nil); loc != nil && loc.Filename != "" {
+		span := p.span(loc)
+		d.Span = &span
+	}
+	for _, l := range e.Labels {
+		d.Labels = append(d.Labels, Label{Span: p.span(l.Loc), Message: l.Msg})
+	}
+
+	// stacks of internal errors are only useful to debug the compiler
+	e.Stack = ""
+	sources := &builder.BuildOptions{Overlay: p.files, InMemory: true}
+	d.rendered = errors.RenderWith(e, errors.RenderOptions{ReadFile: sources.ReadSource, FileName: p.relative})
+	return d
+}
+
+func (p *Program) span(loc *token.Span) Span {
+	return Span{
+		File:       p.relative(loc.Filename),
+		FromLine:   loc.FromLine,
+		FromColumn: loc.FromColumn,
+		ToLine:     loc.ToLine,
+		ToColumn:   loc.ToColumn,
+	}
+}
@@ -0,0 +1,157 @@
+// Package golden embeds the Golden compiler in Go programs. Sources are
+// compiled from memory, without reading the disk or depending on the working
+// directory, and programs run on the interpreter backend.
+//
+//	program, err := golden.Compile(golden.Files{
+//		"main.gold": `fn main() { println("hello") }`,
+//	}, golden.Options{})
+//	if err != nil {
+//		return err // *golden.Diagnostic
+//	}
+//	err = program.Run(golden.RunOptions{Stdout: os.Stdout})
+//
+// Errors are returned as values, the compiler never panics through the API.
+package golden
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/renatopp/golden/internal/backend/interpreter"
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/astjson"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
+
+// Sources of a program, by their slash-separated paths relative to the root
+// of the project, ex: `main.gold` and `lib/math.gold`, which is imported as
+// `@/lib/math`.
+type Files map[string]string
+
+type Options struct {
+	Entry             string   // Path of the entry module, `main.gold` by default
+	OptimizationLevel int      // Optimization level, from 0 (none) to 2
+	Suppress          []string // Codes of the warnings that are not reported, ex: `G0601`
+	Host              *Host    // Functions and values visible to every module, may be nil
+}
+
+// Host holds the Go functions and values exposed to the program, see
+// Host.Register for the conversion of their types.
+//
+//	host := golden.NewHost()
+//	host.Register("greet", func(name string) string { return "hello " + name })
+type Host = interpreter.Host
+
+func NewHost() *Host {
+	return interpreter.NewHost()
+}
+
+// Program is a checked program. Its modules hold the typed AST, which is not
+// modified when the program runs.
+type Program struct {
+	Entry    *Module
+	Modules  []*Module // In dependency order, the entry is the last one
+	Warnings []*Diagnostic
+
+	files map[string][]byte
+	opts  Options
+}
+
+type Module struct {
+	Path string // Path in the files, or `@std/<name>.gold` for the standard library
+	Name string
+	AST  *ModuleNode
+}
+
+// Encodes the typed AST of the module in the JSON format of `golden ast`.
+func (m *Module) MarshalAST() ([]byte, error) {
+	return astjson.Encode(m.AST)
+}
+
+// Root of the project in memory. Module paths are absolute for the compiler,
+// but nothing is read from this directory.
+var root, _ = filepath.Abs(string(filepath.Separator))
+
+// Compiles the files, returning the checked program. Compilation errors are
+// returned as *Diagnostic, invalid files and options as plain errors.
+func Compile(files Files, opts Options) (*Program, error) {
+	if opts.Entry == "" {
+		opts.Entry = "main.gold"
+	}
+
+	p := &Program{files: map[string][]byte{}, opts: opts}
+	for name, source := range files {
+		file, err := modulePath(name)
+		if err != nil {
+			return nil, err
+		}
+		p.files[file] = []byte(source)
+	}
+	entry, err := modulePath(opts.Entry)
+	if err != nil {
+		return nil, err
+	}
+
+	bopts, err := p.buildOptions(entry)
+	if err != nil {
+		return nil, err
+	}
+	bopts.OnWarning.Subscribe(func(w errors.GoldenError) { p.Warnings = append(p.Warnings, p.diagnostic(w)) })
+	bopts.OnTypeCheckReady.Subscribe(func(f *builder.File, r *ast.Module, _ *env.Scope) {
+		p.Modules = append(p.Modules, &Module{Path: p.relative(f.Path), Name: f.Name, AST: r})
+	})
+
+	if _, err := builder.NewBuilder(bopts).Check(); err != nil {
+		return nil, p.diagnostic(err)
+	}
+	p.Entry = p.Modules[len(p.Modules)-1]
+	return p, nil
+}
+
+// Converts the path of a file to the absolute path of the module.
+func modulePath(name string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || path.Ext(clean) != ".gold" {
+		return "", fmt.Errorf("file '%s' must be a relative path with the '.gold' extension", name)
+	}
+	return filepath.Join(root, filepath.FromSlash(clean)), nil
+}
+
+func (p *Program) buildOptions(entry string) (*builder.BuildOptions, error) {
+	opts := builder.NewBuildOptions(entry)
+	opts.WorkingDir = root
+	opts.Overlay = p.files
+	opts.InMemory = true
+	opts.NoCache = true
+	backend := interpreter.NewBackend()
+	backend.Host = p.opts.Host
+	opts.OutputTarget = backend
+	opts.OptimizationLevel = min(max(p.opts.OptimizationLevel, optimizations.LevelNone), optimizations.LevelAggressive)
+	for _, s := range p.opts.Suppress {
+		code, ok := errors.ParseCode(s)
+		if !ok {
+			return nil, fmt.Errorf("unknown error code '%s'", s)
+		}
+		opts.Suppress = append(opts.Suppress, code)
+	}
+	return opts, nil
+}
+
+// Returns the path of the module as given in the files.
+func (p *Program) relative(file string) string {
+	if fs.IsStdPath(file) {
+		rel, _ := filepath.Rel(fs.StdDir, file)
+		return "@std/" + filepath.ToSlash(rel)
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
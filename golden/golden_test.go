@@ -0,0 +1,192 @@
+package golden_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/renatopp/golden/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := golden.Run(golden.Files{
+		"main.gold":     "import \"@/lib/math\"\nimport \"@std/strings\"\nfn main() { println(strings.from_int(math.twice(21))) }\n",
+		"lib/math.gold": "fn twice(a Int) Int { a * 2 }\n",
+	}, golden.Options{OptimizationLevel: 2}, golden.RunOptions{Stdout: out})
+	require.NoError(t, err)
+	assert.Equal(t, "42\n", out.String())
+}
+
+func TestCompileTypedAst(t *testing.T) {
+	program, err := golden.Compile(golden.Files{
+		"app.gold": "fn twice(a Int) Int { a * 2 }\n",
+	}, golden.Options{Entry: "app.gold"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "app.gold", program.Entry.Path)
+	assert.Equal(t, "app", program.Entry.Name)
+	assert.Contains(t, program.Modules[0].Path, "@std/")
+
+	fn := program.Entry.AST.Exprs[0].(*golden.FnDecl)
+	assert.Equal(t, "Fn(Int) Int", fn.GetType().Unwrap().GetSignature())
+
+	data, err := program.Entry.MarshalAST()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type": "Fn(Int) Int"`)
+}
+
+func TestCompileDiagnostics(t *testing.T) {
+	_, err := golden.Compile(golden.Files{
+		"main.gold": "fn twice(a Int) Int { a * 2 }\nfn main() {\n  twice(true)\n}\n",
+	}, golden.Options{})
+	require.Error(t, err)
+
+	var d *golden.Diagnostic
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0302", d.Code)
+	assert.Equal(t, golden.SeverityError, d.Severity)
+	require.NotNil(t, d.Span)
+	assert.Equal(t, "main.gold", d.Span.File)
+	assert.Equal(t, 3, d.Span.FromLine)
+	assert.Contains(t, d.Render(), "--> main.gold:3:")
+	assert.Contains(t, d.Render(), "twice(true)")
+
+	_, err = golden.Compile(golden.Files{
+		"main.gold": "import \"missing\"\nfn main() {}\n",
+	}, golden.Options{})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0101", d.Code)
+
+	_, err = golden.Compile(golden.Files{"../main.gold": ""}, golden.Options{})
+	require.Error(t, err)
+}
+
+func TestRunDiagnostics(t *testing.T) {
+	program, err := golden.Compile(golden.Files{
+		"main.gold": "fn divide(a Int, b Int) Int { a / b }\nfn main() { divide(1, 0) }\n",
+	}, golden.Options{})
+	require.NoError(t, err)
+
+	err = program.Run(golden.RunOptions{})
+	var d *golden.Diagnostic
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0502", d.Code)
+	assert.Equal(t, "main.gold", d.Span.File)
+}
+
+func TestRunHost(t *testing.T) {
+	var logged []string
+	host := golden.NewHost()
+	require.NoError(t, host.Register("version", "1.2"))
+	require.NoError(t, host.Register("log", func(msg string) { logged = append(logged, msg) }))
+	require.NoError(t, host.Register("apply", func(f func(int) int, v int) int { return f(v) * 10 }))
+	require.NoError(t, host.Register("parse", func(s string) (int, error) {
+		if s == "" {
+			return 0, fmt.Errorf("empty string")
+		}
+		return len(s), nil
+	}))
+
+	out := &bytes.Buffer{}
+	err := golden.Run(golden.Files{
+		"main.gold": "import \"@std/strings\"\nfn main() {\n  log(version)\n  println(strings.from_int(apply(fn (x Int) Int { x + 1 }, parse(\"abc\"))))\n}\n",
+	}, golden.Options{Host: host}, golden.RunOptions{Stdout: out})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2"}, logged)
+	assert.Equal(t, "40\n", out.String())
+
+	err = golden.Run(golden.Files{
+		"main.gold": "fn main() { parse(\"\") }\n",
+	}, golden.Options{Host: host}, golden.RunOptions{})
+	var d *golden.Diagnostic
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "empty string", d.Message)
+	assert.Equal(t, "main.gold", d.Span.File)
+
+	_, err = golden.Compile(golden.Files{
+		"main.gold": "fn main() { log(1) }\n",
+	}, golden.Options{Host: host})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0302", d.Code)
+}
+
+func TestHostRegister(t *testing.T) {
+	host := golden.NewHost()
+	assert.NoError(t, host.Register("twice", func(a int) int { return a * 2 }))
+	assert.Error(t, host.Register("twice", func(a int) int { return a * 2 }))
+	assert.Error(t, host.Register("Twice", 1))
+	assert.Error(t, host.Register("__twice", 1))
+	assert.Error(t, host.Register("point", struct{ X int }{}))
+	assert.Error(t, host.Register("sum", func(a ...int) int { return 0 }))
+}
+
+func TestRunSandbox(t *testing.T) {
+	loop := golden.Files{"main.gold": "fn loop(n Int) Int {\n  return loop(n + 1)\n}\nfn main() { loop(0) }\n"}
+	program, err := golden.Compile(loop, golden.Options{})
+	require.NoError(t, err)
+
+	var d *golden.Diagnostic
+	err = program.Run(golden.RunOptions{Limits: golden.Limits{Steps: 1000}})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0702", d.Code)
+	assert.Equal(t, "step limit of 1000 exceeded", d.Message)
+	require.NotNil(t, d.Span)
+	assert.Equal(t, "main.gold", d.Span.File)
+	assert.Equal(t, 2, d.Span.FromLine)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = program.Run(golden.RunOptions{Context: ctx})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0702", d.Code)
+	assert.Contains(t, d.Message, "deadline exceeded")
+
+	err = program.Run(golden.RunOptions{Limits: golden.Limits{Values: 100}})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "value limit of 100 exceeded", d.Message)
+
+	err = golden.Run(golden.Files{
+		"main.gold": "fn depth(n Int) Int {\n  1 + depth(n + 1)\n}\nfn main() { depth(0) }\n",
+	}, golden.Options{}, golden.RunOptions{Limits: golden.Limits{Depth: 50}})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "call depth limit of 50 exceeded", d.Message)
+	assert.Equal(t, 2, d.Span.FromLine)
+
+	err = golden.Run(golden.Files{
+		"main.gold": "fn depth(n Int) Int {\n  1 + depth(n + 1)\n}\nfn main() { depth(0) }\n",
+	}, golden.Options{}, golden.RunOptions{Sandbox: true})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0702", d.Code)
+	assert.Equal(t, fmt.Sprintf("call depth limit of %d exceeded", golden.DefaultSandboxDepth), d.Message)
+}
+
+func TestRunSandboxCapabilities(t *testing.T) {
+	var d *golden.Diagnostic
+	err := golden.Run(golden.Files{
+		"main.gold": "fn main() { println(\"hello\") }\n",
+	}, golden.Options{}, golden.RunOptions{Sandbox: true})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0703", d.Code)
+
+	err = golden.Run(golden.Files{
+		"main.gold": "extern fn upper(s String) String = \"go:strings.ToUpper\"\nfn main() {\n  upper(\"hi\")\n}\n",
+	}, golden.Options{}, golden.RunOptions{Sandbox: true})
+	require.ErrorAs(t, err, &d)
+	assert.Equal(t, "G0703", d.Code)
+	assert.Equal(t, "main.gold", d.Span.File)
+	assert.Equal(t, 3, d.Span.FromLine)
+
+	calls := 0
+	host := golden.NewHost()
+	require.NoError(t, host.Register("count", func() { calls++ }))
+	err = golden.Run(golden.Files{
+		"main.gold": "fn main() { count() }\n",
+	}, golden.Options{Host: host}, golden.RunOptions{Sandbox: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,69 @@
+package golden
+
+import (
+	"context"
+	"io"
+
+	"github.com/renatopp/golden/internal/backend/interpreter"
+	"github.com/renatopp/golden/internal/builder"
+)
+
+type RunOptions struct {
+	Stdout io.Writer // Output of `print` and `println`, discarded when nil
+
+	// Sandbox of untrusted programs. The context stops the program when done,
+	// which bounds its running time with a deadline, and the limits bound its
+	// steps, calls and allocations. Sandboxed programs without a depth limit
+	// are limited to DefaultSandboxDepth nested calls, so deep recursions do
+	// not overflow the stack of the host. Sandboxed programs cannot call extern
+	// functions nor write to the output, but can call the functions of the
+	// Host. Exceeding a limit returns a *Diagnostic with the code `G0702` and
+	// the span where the program stopped.
+	Context context.Context
+	Limits  Limits
+	Sandbox bool
+}
+
+// Limits of the resources used by a program, zero values are unlimited.
+type Limits = interpreter.Limits
+
+// Depth limit of sandboxed programs whose limits have none.
+const DefaultSandboxDepth = interpreter.DefaultSandboxDepth
+
+// Runs the program on the interpreter, calling the `main` function of the
+// entry module. The program is compiled again with the optimizations of the
+// options, so the typed AST of the modules is not modified. Runtime errors
+// are returned as *Diagnostic.
+func (p *Program) Run(opts RunOptions) error {
+	entry, err := modulePath(p.opts.Entry)
+	if err != nil {
+		return err
+	}
+	bopts, err := p.buildOptions(entry)
+	if err != nil {
+		return err
+	}
+
+	backend := bopts.OutputTarget.(*interpreter.Interpreter)
+	backend.Stdout = opts.Stdout
+	if backend.Stdout == nil {
+		backend.Stdout = io.Discard
+	}
+	backend.Context = opts.Context
+	backend.Limits = opts.Limits
+	backend.Sandbox = opts.Sandbox
+
+	if _, err := builder.NewBuilder(bopts).Run(); err != nil {
+		return p.diagnostic(err)
+	}
+	return nil
+}
+
+// Compiles and runs the files, see Compile and Program.Run.
+func Run(files Files, opts Options, run RunOptions) error {
+	p, err := Compile(files, opts)
+	if err != nil {
+		return err
+	}
+	return p.Run(run)
+}
@@ -1,9 +1,12 @@
 package backend
 
-import "github.com/renatopp/golden/internal/compiler/ast"
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/env"
+)
 
 type Backend interface {
-	Initialize(targetPath string)
+	Initialize(projectPath, targetPath string)
 	BeforeCodeGeneration()
 	GenerateCode(filePath string, root *ast.Module, entry bool)
 	AfterCodeGeneration()
@@ -11,3 +14,17 @@ type Backend interface {
 	Build(outputPath string)
 	Finalize()
 }
+
+// Incremental is implemented by backends that keep the code generated for each
+// module between builds. Modules unchanged since the previous build are given
+// to GenerateCode with a nil root, and their previous output is kept.
+type Incremental interface {
+	HasOutput(filePath string) bool
+}
+
+// Host is implemented by backends running the program inside the process of
+// a host program, which may expose its functions and values to the modules.
+// They are declared in the global scope before the modules are checked.
+type Host interface {
+	DeclareHost(scope *env.Scope)
+}
@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"bytes"
 	_ "embed"
 	"log"
 	"os"
@@ -17,56 +18,89 @@ import (
 var raw_template_main string
 var template_main, _ = template.New("main").Parse(raw_template_main)
 
+//go:embed runtime/runtime.go
+var raw_runtime []byte
+
 //go:embed templates/go.mod.tmpl
 var raw_template_mod string
 var template_mod, _ = template.New("mod").Parse(raw_template_mod)
 
 type Golang struct {
+	projectDirectory        string // Root of the golden project
+	targetDirectory         string // Root of the generated go project
 	backendProjectDirectory string
 	backendMainPath         string
 	backendGoModPath        string
+	backendRuntimePath      string
+	entryRef                *Ref
+	names                   map[string]map[string]string // Golden names of the generated packages, by import path
 }
 
 func NewBackend() *Golang {
-	return &Golang{}
+	return &Golang{names: map[string]map[string]string{}}
 }
 
-func (b *Golang) Initialize(targetPath string) {
-	targetDirectory = path.Join(targetPath, "golang")
-	b.backendProjectDirectory = path.Join(targetDirectory, "root")
-	b.backendMainPath = path.Join(targetDirectory, "main.go")
-	b.backendGoModPath = path.Join(targetDirectory, "go.mod")
+func (b *Golang) Initialize(projectPath, targetPath string) {
+	b.projectDirectory = projectPath
+	b.targetDirectory = path.Join(targetPath, "golang")
+	b.backendProjectDirectory = path.Join(b.targetDirectory, "root")
+	b.backendMainPath = path.Join(b.targetDirectory, "main.go")
+	b.backendGoModPath = path.Join(b.targetDirectory, "go.mod")
+	b.backendRuntimePath = path.Join(b.targetDirectory, "runtime", "runtime.go")
 }
 
 func (b *Golang) BeforeCodeGeneration() {
-	fs.GuaranteeDirectoryExists(targetDirectory)
+	fs.GuaranteeDirectoryExists(b.targetDirectory)
 	fs.GuaranteeDirectoryExists(b.backendProjectDirectory)
 }
 
+// Each module is written to its own package, see `BackendPath`.
 func (b *Golang) GenerateCode(goldenFilePath string, root *ast.Module, entry bool) {
-	backendFilePath := BackendPath(goldenFilePath)
+	if entry {
+		b.entryRef = b.R(goldenFilePath, "main")
+	}
+	if root == nil {
+		return
+	}
+	backendFilePath := b.BackendPath(goldenFilePath)
+	fs.GuaranteeDirectoryExists(path.Dir(backendFilePath))
 	writer := NewWriter(b)
-	os.WriteFile(backendFilePath, []byte(writer.Generate("root", root)), 0644)
+	os.WriteFile(backendFilePath, []byte(writer.Generate(BackendPackageName(goldenFilePath), root)), 0644)
+	b.names[b.BackendImportPath(goldenFilePath)] = writer.Names()
+}
+
+func (b *Golang) HasOutput(goldenFilePath string) bool {
+	return fs.CheckFileExists(b.BackendPath(goldenFilePath)) == nil
 }
 
 func (b *Golang) AfterCodeGeneration() {
-	os.WriteFile(b.backendMainPath, tmpl.GenerateBytes(template_main, nil), 0644)
+	os.WriteFile(b.backendMainPath, tmpl.GenerateBytes(template_main, map[string]any{
+		"EntryImport": b.entryRef.BackendImportPath,
+	}), 0644)
 	os.WriteFile(b.backendGoModPath, tmpl.GenerateBytes(template_mod, nil), 0644)
+
+	// the runtime package is imported as `golden/runtime`
+	fs.GuaranteeDirectoryExists(path.Dir(b.backendRuntimePath))
+	os.WriteFile(b.backendRuntimePath, raw_runtime, 0644)
 }
 
 func (b *Golang) Run() {
+	// runtime errors are shown with the Golden names
+	stderr := &bytes.Buffer{}
 	cmd := exec.Command("go", "run", b.backendMainPath)
-	cmd.Dir = targetDirectory
+	cmd.Dir = b.targetDirectory
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	os.Stderr.WriteString(b.Demangle(stderr.String()))
+	if err != nil {
 		log.Fatalf("failed to run go: %v", err)
 	}
 }
 
 func (b *Golang) Build(outputPath string) {
 	cmd := exec.Command("go", "build", "-o", outputPath, b.backendMainPath)
-	cmd.Dir = targetDirectory
+	cmd.Dir = b.targetDirectory
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
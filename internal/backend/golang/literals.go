@@ -0,0 +1,31 @@
+package golang
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Formats the float with the shortest representation that reads back to the
+// same value. Integral values keep a decimal point, otherwise Go would treat
+// the constant as an integer, ex: `1.0 / 2.0` would be `1 / 2`, which is 0.
+// Go constants cannot represent NaN, infinities or negative zero, so these
+// are created by the math package.
+func formatFloat(v float64) (code string, usesMath bool) {
+	switch {
+	case math.IsNaN(v):
+		return "math.NaN()", true
+	case math.IsInf(v, 1):
+		return "math.Inf(1)", true
+	case math.IsInf(v, -1):
+		return "math.Inf(-1)", true
+	case v == 0 && math.Signbit(v):
+		return "math.Copysign(0, -1)", true
+	}
+
+	code = strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(code, ".e") {
+		code += ".0"
+	}
+	return code, false
+}
@@ -0,0 +1,75 @@
+package golang
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/renatopp/golden/internal/helpers/codegen"
+	"github.com/renatopp/golden/internal/helpers/naming"
+)
+
+// Keywords and predeclared identifiers of Go, which cannot be used as names
+// or would shadow the builtins used by the generated code.
+var reserved = []string{
+	// keywords
+	"break", "case", "chan", "const", "continue", "default", "defer", "else",
+	"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+	"map", "package", "range", "return", "select", "struct", "switch", "type",
+	"var",
+	// types
+	"any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+	"float32", "float64", "int", "int8", "int16", "int32", "int64", "rune",
+	"string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	// constants and zero value
+	"true", "false", "iota", "nil",
+	// functions
+	"append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+	"len", "make", "max", "min", "new", "panic", "print", "println", "real",
+	"recover",
+	// special functions
+	"init", "main",
+	// packages imported by the generated code
+	"math", "runtime",
+}
+
+// Public names are exported by the package, so their first letter is upper
+// case. Private names start with `_`, which Go does not export.
+func goName(name string) string {
+	if naming.IsPrivateName(name) {
+		return strings.ToLower(name[:1]) + name[1:]
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// Returns the name of the runtime function implementing the builtin, ex:
+// `__string_has_prefix` is `runtime.StringHasPrefix`.
+func builtinName(name string) string {
+	parts := strings.Split(strings.TrimPrefix(name, "__"), "_")
+	for i, p := range parts {
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func newMangler() *codegen.Mangler {
+	return codegen.NewMangler(reserved, goName, func(s string) string { return s + "_" })
+}
+
+// Qualified Go names of the generated packages, as shown in stack traces.
+var qualifiedName = regexp.MustCompile(`golden/(?:root|core)/([\w/]*/)?(\w+)\.(\w+)`)
+
+// Replaces the qualified Go names of the generated code with the Golden names,
+// ex: `golden/root/lib/math.Twice` is shown as `math.twice` and
+// `golden/core/strings.Upper` as `strings.upper`. Names of modules
+// that were not generated in this build are converted back by their case.
+func (b *Golang) Demangle(text string) string {
+	return qualifiedName.ReplaceAllStringFunc(text, func(s string) string {
+		m := qualifiedName.FindStringSubmatch(s)
+		importPath := strings.TrimSuffix(s, "."+m[3])
+		name, ok := b.names[importPath][m[3]]
+		if !ok {
+			name = strings.ToLower(m[3][:1]) + m[3][1:]
+		}
+		return m[2] + "." + name
+	})
+}
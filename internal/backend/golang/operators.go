@@ -0,0 +1,79 @@
+package golang
+
+import (
+	"math"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/compiler/types"
+)
+
+type operator struct {
+	Symbol     string
+	Precedence int
+}
+
+// Binary operators of Golden, by their Go operators and precedences. Golden
+// and Go do not agree on the precedences, ex: `%` binds tighter than `*` in
+// Golden, so parentheses are added following the Go precedences. `xor` is
+// only defined for booleans, where it is the same as `!=`.
+var operators = map[string]operator{
+	token.KindToLiteral(token.TOr):           {"||", 1},
+	token.KindToLiteral(token.TAnd):          {"&&", 2},
+	token.KindToLiteral(token.TXor):          {"!=", 3},
+	token.KindToLiteral(token.TEqual):        {"==", 3},
+	token.KindToLiteral(token.TNotEqual):     {"!=", 3},
+	token.KindToLiteral(token.TLess):         {"<", 3},
+	token.KindToLiteral(token.TLessEqual):    {"<=", 3},
+	token.KindToLiteral(token.TGreater):      {">", 3},
+	token.KindToLiteral(token.TGreaterEqual): {">=", 3},
+	token.KindToLiteral(token.TPlus):         {"+", 4},
+	token.KindToLiteral(token.TMinus):        {"-", 4},
+	token.KindToLiteral(token.TStar):         {"*", 5},
+	token.KindToLiteral(token.TSlash):        {"/", 5},
+	token.KindToLiteral(token.TPercent):      {"%", 5},
+}
+
+// Runtime functions of the integer division and remainder, which check the
+// divisor.
+var intDivisions = map[string]string{
+	token.KindToLiteral(token.TSlash):   "DivInt",
+	token.KindToLiteral(token.TPercent): "ModInt",
+}
+
+// Returns the runtime function replacing the operator, if any.
+func intDivision(node *ast.BinOp) (string, bool) {
+	fn, ok := intDivisions[node.Op]
+	if !ok || node.LeftExpr.GetType().Unwrap() != types.Int {
+		return "", false
+	}
+	return fn, true
+}
+
+const (
+	unaryPrecedence   = 6
+	primaryPrecedence = 7
+)
+
+// Returns the Go precedence of the code generated for the node. Negative
+// numbers are written with a unary minus.
+func precedence(node ast.Node) int {
+	switch node := node.(type) {
+	case *ast.BinOp:
+		if _, ok := intDivision(node); ok {
+			return primaryPrecedence
+		}
+		return operators[node.Op].Precedence
+	case *ast.UnaryOp:
+		return unaryPrecedence
+	case *ast.Int:
+		if node.Value < 0 {
+			return unaryPrecedence
+		}
+	case *ast.Float:
+		if node.Value < 0 && !math.IsInf(node.Value, -1) {
+			return unaryPrecedence
+		}
+	}
+	return primaryPrecedence
+}
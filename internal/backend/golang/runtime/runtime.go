@@ -0,0 +1,219 @@
+// Package runtime is the support library of the generated Go code. It is
+// embedded in the compiler and written to the target next to `main.go`, so it
+// must only depend on the standard library.
+//
+// The JavaScript backend has the same primitives in `runtime.mjs`, which must
+// behave exactly like these ones.
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a panic raised by the Golden program, at the location of the
+// Golden expression that failed.
+type Error struct {
+	Message  string
+	Location string // `path:line:column`, or empty if unknown
+}
+
+func (e *Error) Error() string {
+	if e.Location == "" {
+		return e.Message
+	}
+	return e.Message + " at " + e.Location
+}
+
+// Runs the entry function. Panics raised by the Golden program are printed
+// with their Golden location and exit with status 2, like the Go panics.
+func Main(main func()) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, ok := r.(*Error)
+		if !ok {
+			panic(r)
+		}
+		fmt.Fprintf(os.Stderr, "panic: %s\n", err.Message)
+		if err.Location != "" {
+			fmt.Fprintf(os.Stderr, "    at %s\n", err.Location)
+		}
+		os.Exit(2)
+	}()
+	main()
+}
+
+func Panic(message, location string) {
+	panic(&Error{Message: message, Location: location})
+}
+
+func Assert(cond bool, message, location string) {
+	if !cond {
+		Panic("assertion failed: "+message, location)
+	}
+}
+
+//
+//
+//
+
+func Print(s string) {
+	os.Stdout.WriteString(s)
+}
+
+func Println(s string) {
+	os.Stdout.WriteString(s + "\n")
+}
+
+func FormatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+// Floats are formatted with the shortest representation that reads back to
+// the same value, using exponents below 1e-4 and from 1e6, ex: `0.5`, `1e-05`
+// and `1.5e+06`. Non-finite values are `NaN`, `+Inf` and `-Inf`.
+func FormatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func FormatBool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+//
+//
+//
+
+// Integer division, truncated towards zero.
+func DivInt(a, b int64, location string) int64 {
+	if b == 0 {
+		Panic("division by zero", location)
+	}
+	return a / b
+}
+
+// Remainder of the integer division, with the sign of the dividend.
+func ModInt(a, b int64, location string) int64 {
+	if b == 0 {
+		Panic("division by zero", location)
+	}
+	return a % b
+}
+
+//
+//
+//
+
+// Number of characters, not bytes, of the string.
+func StringLength(s string) int64 {
+	return int64(utf8.RuneCountInString(s))
+}
+
+// Counts below one result in an empty string.
+func StringRepeat(s string, count int64) string {
+	if count <= 0 {
+		return ""
+	}
+	return strings.Repeat(s, int(count))
+}
+
+func StringContains(s, sub string) bool {
+	return strings.Contains(s, sub)
+}
+
+func StringHasPrefix(s, prefix string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+func StringHasSuffix(s, suffix string) bool {
+	return strings.HasSuffix(s, suffix)
+}
+
+// Characters are converted one by one, so the string keeps its length.
+func StringUpper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// Characters are converted one by one, so the string keeps its length.
+func StringLower(s string) string {
+	return strings.ToLower(s)
+}
+
+// White space are the characters of the Unicode `White_Space` property.
+func StringTrim(s string) string {
+	return strings.TrimSpace(s)
+}
+
+//
+//
+//
+
+func FloatAbs(x float64) float64 {
+	return math.Abs(x)
+}
+
+func FloatSqrt(x float64) float64 {
+	return math.Sqrt(x)
+}
+
+func FloatPow(x, y float64) float64 {
+	return math.Pow(x, y)
+}
+
+func FloatFloor(x float64) float64 {
+	return math.Floor(x)
+}
+
+func FloatCeil(x float64) float64 {
+	return math.Ceil(x)
+}
+
+func FloatMin(x, y float64) float64 {
+	return math.Min(x, y)
+}
+
+func FloatMax(x, y float64) float64 {
+	return math.Max(x, y)
+}
+
+// The absolute value of the smallest Int does not fit an Int, so it is
+// returned unchanged.
+func IntAbs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func IntMin(x, y int64) int64 {
+	return min(x, y)
+}
+
+func IntMax(x, y int64) int64 {
+	return max(x, y)
+}
+
+func IntToFloat(x int64) float64 {
+	return float64(x)
+}
+
+// Truncates the float towards zero. Values out of the Int range are clamped
+// and `NaN` is converted to zero.
+func FloatToInt(x float64) int64 {
+	switch {
+	case math.IsNaN(x):
+		return 0
+	case x >= math.MaxInt64:
+		return math.MaxInt64
+	case x <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(x)
+}
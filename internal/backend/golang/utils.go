@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"go/token"
 	"path"
 	"strings"
 
@@ -15,46 +16,59 @@ type Ref struct {
 	BackendIdentifier string
 }
 
-var targetDirectory = ""
-
-func R(filepath, identifier string) *Ref {
+func (b *Golang) R(filepath, identifier string) *Ref {
 	return &Ref{
 		GoldenFilePath:    filepath,
 		GoldenIdentifier:  identifier,
-		BackendFilePath:   BackendPath(filepath),
-		BackendImportPath: BackendImportPath(filepath),
+		BackendFilePath:   b.BackendPath(filepath),
+		BackendImportPath: b.BackendImportPath(filepath),
 		BackendIdentifier: BackendIdentifier(identifier),
 	}
 }
 
-// Returns the absolute path of the backend file
-func BackendPath(goldenFilepath string) string {
-	file := _relativeBackendPath(goldenFilepath)
-	return path.Join(targetDirectory, file)
+// Returns the absolute path of the backend file. Each module is a package,
+// placed at the directory of the module followed by the module name, ex:
+// `@/foo/bar/hello.gold` is written to `root/foo/bar/hello/hello.go`. Modules
+// of the standard library are placed in `core`, ex: `@std/math` is written
+// to `core/math/math.go`.
+func (b *Golang) BackendPath(goldenFilepath string) string {
+	dir := b.relativeBackendPackagePath(goldenFilepath)
+	return path.Join(b.targetDirectory, dir, fs.ModulePath2ModuleName(goldenFilepath)+".go")
 }
 
-func BackendImportPath(goldenFilepath string) string {
-	file := _relativeBackendPath(goldenFilepath)
-	return "golden/" + strings.TrimRight(file, ".go")
+func (b *Golang) BackendImportPath(goldenFilepath string) string {
+	return "golden/" + b.relativeBackendPackagePath(goldenFilepath)
 }
 
 func BackendIdentifier(goldenIdentifier string) string {
 	return goldenIdentifier
 }
 
-func _relativeBackendPath(goldenFilepath string) string {
+// Returns the name of the package of the module. Go does not allow importing
+// `main` packages, nor packages named after keywords.
+func BackendPackageName(goldenFilepath string) string {
+	name := fs.ModulePath2ModuleName(goldenFilepath)
+	if name == "main" || token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+func (b *Golang) relativeBackendPackagePath(goldenFilepath string) string {
 	// if filepath is from project, root/**
 	// if filepath is from core, core/**
 	// if filepath is from packages, package<name>/**
 
-	file := ""
-	if fs.IsProjectPath(goldenFilepath) {
-		relative := fs.ToLinuxSlash(fs.GetProjectRelativePath(goldenFilepath))
-		relative = strings.TrimPrefix(relative, "/")
-		file = path.Join("root", strings.ReplaceAll(relative, "/", "_"))
-	} else {
+	base, dir := "root", b.projectDirectory
+	switch {
+	case fs.IsStdPath(goldenFilepath):
+		base, dir = "core", fs.StdDir
+	case !fs.IsPathInside(b.projectDirectory, goldenFilepath):
 		panic("BackendPath not implemented: " + goldenFilepath)
 	}
 
-	return strings.ReplaceAll(file, ".gold", ".go")
+	pkg := fs.ModulePath2PackagePath(goldenFilepath)
+	relative := fs.ToLinuxSlash(fs.GetRelativePath(dir, pkg))
+	relative = strings.TrimPrefix(relative, "/")
+	return path.Join(base, relative, fs.ModulePath2ModuleName(goldenFilepath))
 }
@@ -3,15 +3,18 @@ package golang
 import (
 	_ "embed"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
 	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/compiler/types"
 	"github.com/renatopp/golden/internal/helpers/codegen"
 	"github.com/renatopp/golden/internal/helpers/errors"
-	"github.com/renatopp/golden/internal/helpers/naming"
 	"github.com/renatopp/golden/internal/helpers/tmpl"
 )
 
@@ -27,12 +30,17 @@ type Writer struct {
 	stack     []string
 	identer   *codegen.Identer
 	funcLevel int
+	functions []*ast.FnDecl
+	imports   map[string]string // Import paths of the used modules, by alias
+	names     *codegen.Mangler
 }
 
 func NewWriter(backend *Golang) *Writer {
 	w := &Writer{
 		backend: backend,
 		identer: codegen.NewIdenter(),
+		imports: map[string]string{},
+		names:   newMangler(),
 	}
 	w.Visiter = ast.NewVisiter(w)
 	return w
@@ -50,8 +58,13 @@ func (w *Writer) Pop() string {
 
 func (w *Writer) Generate(packageName string, root *ast.Module) string {
 	root.Visit(w)
+	imports := []map[string]string{}
+	for _, alias := range slices.Sorted(maps.Keys(w.imports)) {
+		imports = append(imports, map[string]string{"Alias": alias, "Path": w.imports[alias]})
+	}
 	return tmpl.GenerateString(template_module, map[string]any{
 		"PackageName": packageName,
+		"Imports":     imports,
 		"Exprs":       w.Pop(),
 	})
 }
@@ -81,11 +94,27 @@ func (w *Writer) VisitVarDecl(node *ast.VarDecl) ast.Node {
 	return node
 }
 
+// Builtins are functions of the runtime package.
 func (w *Writer) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	if _, ok := builtins.Lookup(node.Value); ok {
+		w.imports["runtime"] = "golden/runtime"
+		w.Push("runtime." + builtinName(node.Value))
+		return node
+	}
 	w.Push(w.name(node.Value))
 	return node
 }
 
+// Members of other modules are accessed through their packages, which are
+// imported only when used, since Go rejects unused imports.
+func (w *Writer) VisitAccess(node *ast.Access) ast.Node {
+	alias := w.names.MangleRaw(node.Target.(*ast.VarIdent).Value)
+	mod := node.Target.GetType().Unwrap().(*types.Module)
+	w.imports[alias] = w.backend.BackendImportPath(mod.Path)
+	w.Push(alias + "." + w.name(node.Name.Value))
+	return node
+}
+
 func (w *Writer) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
 	errors.ThrowAtNode(node, errors.InternalError, "TypeIdent should not be visited, use resolveType instead")
 	return node
@@ -97,12 +126,17 @@ func (w *Writer) VisitInt(node *ast.Int) ast.Node {
 }
 
 func (w *Writer) VisitFloat(node *ast.Float) ast.Node {
-	w.Push(fmt.Sprintf("%f", node.Value))
+	code, usesMath := formatFloat(node.Value)
+	if usesMath {
+		w.imports["math"] = "math"
+	}
+	w.Push(code)
 	return node
 }
 
+// Invalid UTF-8 bytes are escaped, so the string keeps the same bytes.
 func (w *Writer) VisitString(node *ast.String) ast.Node {
-	w.Push(fmt.Sprintf("%q", node.Value))
+	w.Push(strconv.Quote(node.Value))
 	return node
 }
 
@@ -112,56 +146,37 @@ func (w *Writer) VisitBool(node *ast.Bool) ast.Node {
 }
 
 func (w *Writer) VisitBinOp(node *ast.BinOp) ast.Node {
-	node.LeftExpr.Visit(w)
-	left := w.Pop()
-
-	node.RightExpr.Visit(w)
-	right := w.Pop()
-
-	op := ""
-	switch node.Op {
-	case token.KindToLiteral(token.TAnd):
-		op = "&&"
-	case token.KindToLiteral(token.TOr):
-		op = "||"
-	case token.KindToLiteral(token.TEqual):
-		op = "=="
-	case token.KindToLiteral(token.TNotEqual):
-		op = "!="
-	case token.KindToLiteral(token.TLess):
-		op = "<"
-	case token.KindToLiteral(token.TLessEqual):
-		op = "<="
-	case token.KindToLiteral(token.TGreater):
-		op = ">"
-	case token.KindToLiteral(token.TGreaterEqual):
-		op = ">="
-	case token.KindToLiteral(token.TPlus):
-		op = "+"
-	case token.KindToLiteral(token.TMinus):
-		op = "-"
-	case token.KindToLiteral(token.TStar):
-		op = "*"
-	case token.KindToLiteral(token.TSlash):
-		op = "/"
-	case token.KindToLiteral(token.TPercent):
-		op = "%"
-	case token.KindToLiteral(token.TSpaceShip):
+	if node.Op == token.KindToLiteral(token.TSpaceShip) {
 		errors.ThrowAtNode(node, errors.NotImplemented, "Spaceship operator not implemented yet")
-		// term := fmt.Sprintf("((%s < %s) ? -1 : (%s > %s) ? 1 : 0)", left, right, left, right)
-		// w.Push(term)
 		return node
 	}
 
-	w.Push(fmt.Sprintf("(%s %s %s)", left, op, right))
+	if fn, ok := intDivision(node); ok {
+		node.LeftExpr.Visit(w)
+		left := w.Pop()
+		node.RightExpr.Visit(w)
+		right := w.Pop()
+		w.imports["runtime"] = "golden/runtime"
+		w.Push(fmt.Sprintf("runtime.%s(%s, %s, %s)", fn, left, right, strconv.Quote(w.location(node))))
+		return node
+	}
+
+	op := operators[node.Op]
+	left := w.operand(node.LeftExpr, op.Precedence)
+	right := w.operand(node.RightExpr, op.Precedence+1)
+	w.Push(fmt.Sprintf("%s %s %s", left, op.Symbol, right))
 	return node
 }
 
+// Operands starting with the same sign are wrapped, since `--x` would be a
+// decrement in Go.
 func (w *Writer) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
-	node.RightExpr.Visit(w)
-	right := w.Pop()
+	right := w.operand(node.RightExpr, unaryPrecedence)
+	if strings.HasPrefix(right, node.Op) && node.Op != "!" {
+		right = "(" + right + ")"
+	}
 
-	w.Push(fmt.Sprintf("%s%s", node.Op, right))
+	w.Push(node.Op + right)
 	return node
 }
 
@@ -169,7 +184,12 @@ func (w *Writer) VisitBlock(node *ast.Block) ast.Node {
 	exprs := []string{}
 	for _, expr := range node.Exprs {
 		expr.Visit(w)
-		exprs = append(exprs, w.Pop())
+		exprs = append(exprs, w.statement(expr, w.Pop()))
+		// the code after a return is unreachable, and Go requires functions
+		// to end with a terminating statement
+		if _, ok := expr.(*ast.Return); ok {
+			break
+		}
 	}
 
 	w.Push(strings.Join(exprs, "\n"))
@@ -192,15 +212,90 @@ func (w *Writer) VisitFnDecl(node *ast.FnDecl) ast.Node {
 
 	w.identer.Inc()
 	w.funcLevel++
+	w.functions = append(w.functions, node)
 	node.ValueExpr.Visit(w)
-	body := w.identer.Indent(w.Pop())
+	body := w.Pop()
+	w.functions = w.functions[:len(w.functions)-1]
+
+	// self tail calls reassign the parameters and restart the loop
+	if node.TailCalls {
+		if node.TypeExpr.GetType().Unwrap() == types.Void {
+			body += "\nreturn"
+		}
+		body = fmt.Sprintf("for {\n%s\n}", w.identer.Indent(body))
+	}
+	body = w.identer.Indent(body)
 	w.funcLevel--
 	w.identer.Dec()
 
+	// Go has no named nested functions, so they are variables declared before
+	// their value, which may call itself
+	if name != "" && w.funcLevel > 0 {
+		w.resolveType(node.GetType().Unwrap())
+		fnType := w.Pop()
+		w.Push(fmt.Sprintf("var %s %s\n%s = func(%s) %s {\n%s\n}", name, fnType, name, params, type_, body))
+		return node
+	}
+
 	w.Push(fmt.Sprintf("func %s(%s) %s {\n%s\n}", name, params, type_, body))
 	return node
 }
 
+// Extern functions wrap the bound Go function, so they can be used as values
+// with the declared signature. Arguments are passed as they are, so the Go
+// function must accept the Go types of the Golden types, such as `int64` for
+// `Int`.
+func (w *Writer) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	binding, ok := node.Binding("go")
+	if !ok {
+		errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "extern function '%s' has no binding for the 'go' target", node.Name.Value)
+	}
+	path, fn, ok := ast.SplitGoBinding(binding)
+	if !ok {
+		errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "invalid Go binding '%s'", binding)
+	}
+
+	w.resolveType(node.TypeExpr.GetType().Unwrap())
+	type_ := w.Pop()
+
+	params := codegen.JoinList(", ", node.Params, func(p *ast.FnDeclParam) string {
+		p.Visit(w)
+		return w.Pop()
+	})
+	args := codegen.JoinList(", ", node.Params, func(p *ast.FnDeclParam) string {
+		return w.name(p.Name.Value)
+	})
+
+	call := fmt.Sprintf("%s.%s(%s)", w.importHost(path), fn, args)
+	if node.TypeExpr.GetType().Unwrap() != types.Void {
+		call = "return " + call
+	}
+	w.identer.Inc()
+	body := w.identer.Indent(call)
+	w.identer.Dec()
+	w.Push(fmt.Sprintf("func %s(%s) %s {\n%s\n}", w.name(node.Name.Value), params, type_, body))
+	return node
+}
+
+// Imports a Go package used by extern functions. Aliases start with `__go_`,
+// which Golden names cannot, so they do not collide with the module names.
+func (w *Writer) importHost(path string) string {
+	base := path[strings.LastIndex(path, "/")+1:]
+	base = "__go_" + strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, base)
+
+	alias := base
+	for i := 2; w.imports[alias] != "" && w.imports[alias] != path; i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	w.imports[alias] = path
+	return alias
+}
+
 func (w *Writer) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
 	node.Name.Visit(w)
 	name := w.Pop()
@@ -226,6 +321,11 @@ func (w *Writer) VisitApplication(node *ast.Application) ast.Node {
 }
 
 func (w *Writer) VisitReturn(node *ast.Return) ast.Node {
+	if node.TailCall {
+		w.tailCall(node.ValueExpr.Unwrap().(*ast.Application))
+		return node
+	}
+
 	if node.ValueExpr.Has() {
 		node.ValueExpr.Unwrap().Visit(w)
 		value := w.Pop()
@@ -236,12 +336,63 @@ func (w *Writer) VisitReturn(node *ast.Return) ast.Node {
 	return node
 }
 
-func (w *Writer) name(n string) string {
-	if naming.IsPrivateName(n) {
-		return strings.ToLower(n[:1]) + n[1:]
-	} else {
-		return strings.ToUpper(n[:1]) + n[1:]
+// Assigns all arguments at once, so each argument is evaluated with the
+// parameter values of the current iteration.
+func (w *Writer) tailCall(call *ast.Application) {
+	fn := w.functions[len(w.functions)-1]
+	if len(fn.Params) == 0 {
+		w.Push("continue")
+		return
+	}
+
+	params := codegen.JoinList(", ", fn.Params, func(p *ast.FnDeclParam) string {
+		return w.name(p.Name.Value)
+	})
+	args := codegen.JoinList(", ", call.Args, func(a ast.Node) string {
+		a.Visit(w)
+		return w.Pop()
+	})
+	w.Push(fmt.Sprintf("%s = %s\ncontinue", params, args))
+}
+
+// Go does not accept unused values as statements, so expressions that are
+// not calls or declarations have their values explicitly discarded.
+func (w *Writer) statement(node ast.Node, code string) string {
+	switch node.(type) {
+	case *ast.VarDecl, *ast.Return, *ast.Application, *ast.FnDecl:
+		return code
+	}
+	if tp := node.GetType(); tp.Has() && tp.Unwrap() != types.Void {
+		return "_ = " + code
+	}
+	return code
+}
+
+// Visits the operand, wrapping it in parentheses when it binds looser than the
+// given precedence. Operators of the same precedence are left associative, so
+// right operands require a higher precedence.
+func (w *Writer) operand(node ast.Node, prec int) string {
+	node.Visit(w)
+	code := w.Pop()
+	if precedence(node) < prec {
+		return "(" + code + ")"
 	}
+	return code
+}
+
+// Returns the location of the node shown by runtime errors.
+func (w *Writer) location(node ast.Node) string {
+	return codegen.Location(w.backend.projectDirectory, node)
+}
+
+func (w *Writer) name(n string) string {
+	return w.names.Mangle(n)
+}
+
+// Returns the Golden names of the identifiers of the module, by their Go
+// names.
+func (w *Writer) Names() map[string]string {
+	return w.names.Origins()
 }
 
 func (w *Writer) resolveType(tp ast.Type) {
@@ -0,0 +1,80 @@
+package interpreter
+
+import (
+	"io"
+
+	"github.com/renatopp/golden/internal/backend/golang/runtime"
+	"github.com/renatopp/golden/internal/compiler/builtins"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// Builtin is a function implemented in Go: a primitive used by the standard
+// library or the host function of an extern function. Primitives are
+// implemented by the runtime of the Go backend, so the interpreter behaves like
+// the generated code.
+type Builtin struct {
+	Name string
+	Fn   func(args []Value) Value
+}
+
+func (b *Builtin) String() string {
+	return "<builtin " + b.Name + ">"
+}
+
+var natives = map[string]func(args []Value) Value{
+	"__format_int":   func(a []Value) Value { return runtime.FormatInt(a[0].(int64)) },
+	"__format_float": func(a []Value) Value { return runtime.FormatFloat(a[0].(float64)) },
+	"__format_bool":  func(a []Value) Value { return runtime.FormatBool(a[0].(bool)) },
+
+	"__string_length":     func(a []Value) Value { return runtime.StringLength(a[0].(string)) },
+	"__string_repeat":     func(a []Value) Value { return runtime.StringRepeat(a[0].(string), a[1].(int64)) },
+	"__string_contains":   func(a []Value) Value { return runtime.StringContains(a[0].(string), a[1].(string)) },
+	"__string_has_prefix": func(a []Value) Value { return runtime.StringHasPrefix(a[0].(string), a[1].(string)) },
+	"__string_has_suffix": func(a []Value) Value { return runtime.StringHasSuffix(a[0].(string), a[1].(string)) },
+	"__string_upper":      func(a []Value) Value { return runtime.StringUpper(a[0].(string)) },
+	"__string_lower":      func(a []Value) Value { return runtime.StringLower(a[0].(string)) },
+	"__string_trim":       func(a []Value) Value { return runtime.StringTrim(a[0].(string)) },
+
+	"__float_abs":   func(a []Value) Value { return runtime.FloatAbs(a[0].(float64)) },
+	"__float_sqrt":  func(a []Value) Value { return runtime.FloatSqrt(a[0].(float64)) },
+	"__float_pow":   func(a []Value) Value { return runtime.FloatPow(a[0].(float64), a[1].(float64)) },
+	"__float_floor": func(a []Value) Value { return runtime.FloatFloor(a[0].(float64)) },
+	"__float_ceil":  func(a []Value) Value { return runtime.FloatCeil(a[0].(float64)) },
+	"__float_min":   func(a []Value) Value { return runtime.FloatMin(a[0].(float64), a[1].(float64)) },
+	"__float_max":   func(a []Value) Value { return runtime.FloatMax(a[0].(float64), a[1].(float64)) },
+
+	"__int_abs":      func(a []Value) Value { return runtime.IntAbs(a[0].(int64)) },
+	"__int_min":      func(a []Value) Value { return runtime.IntMin(a[0].(int64), a[1].(int64)) },
+	"__int_max":      func(a []Value) Value { return runtime.IntMax(a[0].(int64), a[1].(int64)) },
+	"__int_to_float": func(a []Value) Value { return runtime.IntToFloat(a[0].(int64)) },
+	"__float_to_int": func(a []Value) Value { return runtime.FloatToInt(a[0].(float64)) },
+}
+
+// Builtins writing to the standard output, which the host may redirect.
+func outputNatives(out io.Writer) map[string]func(args []Value) Value {
+	return map[string]func(args []Value) Value{
+		"__print":   func(a []Value) Value { io.WriteString(out, a[0].(string)); return Void },
+		"__println": func(a []Value) Value { io.WriteString(out, a[0].(string)+"\n"); return Void },
+	}
+}
+
+// Declares the builtins in the environment shared by all modules. Only the
+// standard library can refer to them, which is ensured by the checker. The
+// sandbox disables the builtins writing to the output.
+func declareBuiltins(env *Env, out io.Writer, sandbox bool) {
+	output := outputNatives(out)
+	for _, b := range builtins.Builtins {
+		if _, ok := output[b.Name]; ok && sandbox {
+			env.Declare(b.Name, disabled(b.Name, "output builtin"))
+			continue
+		}
+		fn, ok := output[b.Name]
+		if !ok {
+			fn, ok = natives[b.Name]
+		}
+		if !ok {
+			errors.Throw(errors.InternalError, "builtin '%s' is not implemented by the interpreter", b.Name)
+		}
+		env.Declare(b.Name, &Builtin{Name: b.Name, Fn: fn})
+	}
+}
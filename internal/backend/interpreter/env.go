@@ -1,35 +1,25 @@
 package interpreter
 
-import "fmt"
-
-type ObjectKind string
-
-const ObjectType = ObjectKind("type")
-const ObjectValue = ObjectKind("value")
-
-type Object interface {
-	Kind() ObjectKind // Kind of the object (type or value)
-	Id() uint64       // Object identifier
-	TypeId() uint64   // Id of the type object
-	Type() *Object    // Type instance of the object
-}
-
-type Assignment struct {
-	// Capability ...
-	Object *Object
+import "github.com/renatopp/golden/internal/compiler/ast"
+
+// Binding of a value in an environment. Module variables are initialized
+// lazily, on their first access, so they don't depend on declaration order.
+type Binding struct {
+	Value Value
+	Init  ast.Node // pending initializer of module variables
+	Env   *Env     // environment of the initializer
+	busy  bool
 }
 
 type Env struct {
 	parent *Env
-	types  map[string]Assignment
-	values map[string]Assignment
+	values map[string]*Binding
 }
 
 func NewEnv() *Env {
 	return &Env{
 		parent: nil,
-		types:  make(map[string]Assignment),
-		values: make(map[string]Assignment),
+		values: make(map[string]*Binding),
 	}
 }
 
@@ -39,18 +29,19 @@ func (e *Env) Create() *Env {
 	return c
 }
 
-func (e *Env) DeclareType(name string, obj *Object) error {
-	if _, ok := e.types[name]; ok {
-		return fmt.Errorf("type %s already declared", name)
-	}
-	e.types[name] = Assignment{Object: obj}
-	return nil
+func (e *Env) Declare(name string, value Value) {
+	e.values[name] = &Binding{Value: value}
+}
+
+func (e *Env) DeclareLazy(name string, init ast.Node) {
+	e.values[name] = &Binding{Init: init, Env: e}
 }
 
-func (e *Env) DeclareValue(name string, obj *Object) error {
-	if _, ok := e.values[name]; ok {
-		return fmt.Errorf("value %s already declared", name)
+func (e *Env) Lookup(name string) *Binding {
+	for env := e; env != nil; env = env.parent {
+		if b, ok := env.values[name]; ok {
+			return b
+		}
 	}
-	e.values[name] = Assignment{Object: obj}
 	return nil
 }
@@ -0,0 +1,347 @@
+package interpreter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+type flow int
+
+const (
+	flowNext     flow = iota // continue with the next expression
+	flowReturn               // leave the function with the value
+	flowTailCall             // restart the function with new arguments
+)
+
+// Result of executing an expression of a block.
+type result struct {
+	value Value
+	flow  flow
+	args  []Value // arguments of the tail call
+}
+
+// Evaluator walks the typed AST, evaluating each expression.
+type Evaluator struct {
+	Context context.Context // Stops the program when done, may be nil
+	Limits  Limits
+	Sandbox bool // Disables extern functions and the output builtins
+
+	modules map[string]*Env // Environments of the loaded modules, by path
+	steps   int64
+	values  int64
+	depth   int
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{modules: map[string]*Env{}}
+}
+
+// Creates the environment of a module, declaring its functions and variables.
+func (e *Evaluator) Load(root *ast.Module, parent *Env) *Env {
+	env := parent.Create()
+	if mod, ok := root.GetType().Or(nil).(*types.Module); ok {
+		e.modules[mod.Path] = env
+	}
+	for _, expr := range root.Exprs {
+		switch n := expr.(type) {
+		case *ast.FnDecl:
+			env.Declare(n.Name.Unwrap().Value, &Function{Decl: n, Env: env})
+		case *ast.VarDecl:
+			env.DeclareLazy(n.Name.Value, n.ValueExpr)
+		case *ast.ExternFnDecl:
+			env.Declare(n.Name.Value, e.newExtern(n))
+		}
+	}
+	return env
+}
+
+// Forces the initialization of all module variables, in declaration order
+// unless one depends on another.
+func (e *Evaluator) Initialize(root *ast.Module, env *Env) {
+	for _, expr := range root.Exprs {
+		if n, ok := expr.(*ast.VarDecl); ok {
+			e.lookup(n.Name, env)
+		}
+	}
+}
+
+func (e *Evaluator) Call(fn *Function, args []Value) Value {
+	for {
+		e.alloc(fn.Decl, 1+int64(len(args)))
+		env := fn.Env.Create()
+		for i, p := range fn.Decl.Params {
+			env.Declare(p.Name.Value, args[i])
+		}
+
+		res := e.execBlock(fn.Decl.ValueExpr, env)
+		if res.flow == flowTailCall {
+			args = res.args
+			continue
+		}
+		return res.value
+	}
+}
+
+//
+//
+//
+
+func (e *Evaluator) execBlock(node *ast.Block, env *Env) result {
+	e.alloc(node, 1)
+	env = env.Create()
+	res := result{value: Void}
+	for _, expr := range node.Exprs {
+		res = e.exec(expr, env)
+		if res.flow != flowNext {
+			return res
+		}
+	}
+	return res
+}
+
+func (e *Evaluator) exec(node ast.Node, env *Env) result {
+	switch n := node.(type) {
+	case *ast.VarDecl:
+		e.alloc(n, 1)
+		env.Declare(n.Name.Value, e.eval(n.ValueExpr, env))
+		return result{value: Void}
+
+	case *ast.FnDecl:
+		e.alloc(n, 1)
+		fn := &Function{Decl: n, Env: env}
+		if n.Name.Has() {
+			env.Declare(n.Name.Unwrap().Value, fn)
+		}
+		return result{value: fn}
+
+	case *ast.Return:
+		if n.TailCall {
+			call := n.ValueExpr.Unwrap().(*ast.Application)
+			return result{flow: flowTailCall, args: e.evalArgs(call.Args, env)}
+		}
+		if !n.ValueExpr.Has() {
+			return result{value: Void, flow: flowReturn}
+		}
+		return result{value: e.eval(n.ValueExpr.Unwrap(), env), flow: flowReturn}
+
+	case *ast.Block:
+		return e.execBlock(n, env)
+	}
+
+	return result{value: e.eval(node, env)}
+}
+
+func (e *Evaluator) eval(node ast.Node, env *Env) Value {
+	e.step(node)
+	switch n := node.(type) {
+	case *ast.Int:
+		return n.Value
+	case *ast.Float:
+		return n.Value
+	case *ast.String:
+		return n.Value
+	case *ast.Bool:
+		return n.Value
+	case *ast.VarIdent:
+		return e.lookup(n, env)
+	case *ast.Access:
+		return e.evalAccess(n)
+	case *ast.UnaryOp:
+		return e.evalUnaryOp(n, env)
+	case *ast.BinOp:
+		return e.evalBinOp(n, env)
+	case *ast.Application:
+		return e.evalApplication(n, env)
+	case *ast.FnDecl, *ast.Block:
+		return e.exec(n, env).value
+	}
+
+	errors.ThrowAtNode(node, errors.InternalError, "cannot evaluate node %T", node)
+	return nil
+}
+
+func (e *Evaluator) lookup(node *ast.VarIdent, env *Env) Value {
+	b := env.Lookup(node.Value)
+	if b == nil {
+		errors.ThrowAtNode(node, errors.NameNotFound, "variable '%s' not defined", node.Value)
+	}
+	if b.Init != nil {
+		if b.busy {
+			errors.ThrowAtNode(node, errors.CircularInitialization, "circular initialization of '%s'", node.Value)
+		}
+		b.busy = true
+		b.Value = e.eval(b.Init, b.Env)
+		b.Init = nil
+		b.busy = false
+	}
+	return b.Value
+}
+
+// Looks up the member in the environment of the imported module, which is
+// loaded before the modules importing it.
+func (e *Evaluator) evalAccess(node *ast.Access) Value {
+	mod, ok := node.Target.GetType().Or(nil).(*types.Module)
+	if !ok || e.modules[mod.Path] == nil {
+		errors.ThrowAtNode(node, errors.InternalError, "module of '%s' not loaded", node.Name.Value)
+	}
+	return e.lookup(node.Name, e.modules[mod.Path])
+}
+
+func (e *Evaluator) evalArgs(args []ast.Node, env *Env) []Value {
+	values := make([]Value, len(args))
+	for i, a := range args {
+		values[i] = e.eval(a, env)
+	}
+	return values
+}
+
+func (e *Evaluator) evalApplication(node *ast.Application, env *Env) Value {
+	switch fn := e.eval(node.Target, env).(type) {
+	case *Function:
+		args := e.evalArgs(node.Args, env)
+		defer e.enter(node)()
+		return e.Call(fn, args)
+	case *Builtin:
+		return e.callBuiltin(node, fn, e.evalArgs(node.Args, env))
+	}
+	errors.ThrowAtNode(node, errors.NotCallable, "value is not a function")
+	return nil
+}
+
+func (e *Evaluator) evalUnaryOp(node *ast.UnaryOp, env *Env) Value {
+	right := e.eval(node.RightExpr, env)
+	switch v := right.(type) {
+	case int64:
+		switch node.Op {
+		case "-":
+			return -v
+		case "+":
+			return v
+		}
+	case float64:
+		switch node.Op {
+		case "-":
+			return -v
+		case "+":
+			return v
+		}
+	case bool:
+		if node.Op == "!" {
+			return !v
+		}
+	}
+
+	errors.ThrowAtNode(node, errors.RuntimeError, "invalid operation '%s%s'", node.Op, Format(right))
+	return nil
+}
+
+func (e *Evaluator) evalBinOp(node *ast.BinOp, env *Env) Value {
+	// short-circuit operators
+	switch node.Op {
+	case "and":
+		if !e.eval(node.LeftExpr, env).(bool) {
+			return false
+		}
+		return e.eval(node.RightExpr, env).(bool)
+	case "or":
+		if e.eval(node.LeftExpr, env).(bool) {
+			return true
+		}
+		return e.eval(node.RightExpr, env).(bool)
+	}
+
+	left := e.eval(node.LeftExpr, env)
+	right := e.eval(node.RightExpr, env)
+
+	switch node.Op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+
+	switch a := left.(type) {
+	case int64:
+		b := right.(int64)
+		switch node.Op {
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/", "%":
+			if b == 0 {
+				errors.ThrowAtNode(node, errors.DivisionByZero, "division by zero")
+			}
+			if node.Op == "/" {
+				return a / b
+			}
+			return a % b
+		}
+		if v := compareOp(node.Op, a, b); v != nil {
+			return v
+		}
+
+	case float64:
+		b := right.(float64)
+		switch node.Op {
+		case "+":
+			return a + b
+		case "-":
+			return a - b
+		case "*":
+			return a * b
+		case "/":
+			return a / b
+		}
+		if v := compareOp(node.Op, a, b); v != nil {
+			return v
+		}
+
+	case string:
+		b := right.(string)
+		if node.Op == "+" {
+			res := a + b
+			e.allocString(node, res)
+			return res
+		}
+		if v := compareOp(node.Op, strings.Compare(a, b), 0); v != nil {
+			return v
+		}
+
+	case bool:
+		if node.Op == "xor" {
+			return a != right.(bool)
+		}
+	}
+
+	errors.ThrowAtNode(node, errors.RuntimeError, "invalid operation '%s %s %s'", Format(left), node.Op, Format(right))
+	return nil
+}
+
+func compareOp[T int | int64 | float64](op string, a, b T) Value {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<=>":
+		switch {
+		case a < b:
+			return int64(-1)
+		case a > b:
+			return int64(1)
+		}
+		return int64(0)
+	}
+	return nil
+}
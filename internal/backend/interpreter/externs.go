@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// Go cannot look up functions by their import path at runtime, so the host
+// functions bound by extern functions must be registered to be called by the
+// interpreter. They are keyed by their Go binding, ex: `strings.ToUpper`.
+var hosts = map[string]reflect.Value{}
+var hostsMu sync.RWMutex
+
+func init() {
+	for binding, fn := range map[string]any{
+		"strings.ToUpper":    strings.ToUpper,
+		"strings.ToLower":    strings.ToLower,
+		"strings.TrimSpace":  strings.TrimSpace,
+		"strings.Repeat":     strings.Repeat,
+		"strings.Contains":   strings.Contains,
+		"strings.HasPrefix":  strings.HasPrefix,
+		"strings.HasSuffix":  strings.HasSuffix,
+		"strings.Index":      strings.Index,
+		"strings.ReplaceAll": strings.ReplaceAll,
+		"strconv.Quote":      strconv.Quote,
+		"math.Sqrt":          math.Sqrt,
+		"math.Pow":           math.Pow,
+		"math.Floor":         math.Floor,
+		"math.Ceil":          math.Ceil,
+		"math.Abs":           math.Abs,
+		"math.Sin":           math.Sin,
+		"math.Cos":           math.Cos,
+		"math.Log":           math.Log,
+	} {
+		RegisterHost(binding, fn)
+	}
+}
+
+// Registers the Go function called by the extern functions bound to the
+// given Go binding. Values are converted as for the functions of a Host, so
+// `int` parameters accept Golden `Int`s.
+func RegisterHost(binding string, fn any) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		errors.Throw(errors.InvalidExternBinding, "host '%s' must be a function", binding)
+	}
+	if _, err := goldenType(v.Type()); err != nil {
+		errors.Throw(errors.InvalidExternBinding, "host '%s': %v", binding, err)
+	}
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	hosts[binding] = v
+}
+
+func lookupHost(binding string) (reflect.Value, bool) {
+	hostsMu.RLock()
+	defer hostsMu.RUnlock()
+	fn, ok := hosts[binding]
+	return fn, ok
+}
+
+// Creates the value of the extern function. Missing hosts are only reported
+// when the function is called, since other targets may be the only ones used
+// by the program, and so are the extern functions disabled by the sandbox.
+func (e *Evaluator) newExtern(node *ast.ExternFnDecl) *Builtin {
+	name := node.Name.Value
+	if e.Sandbox {
+		return disabled(name, "extern function")
+	}
+	binding, ok := node.Binding("go")
+	if !ok {
+		return &Builtin{Name: name, Fn: func([]Value) Value {
+			errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "extern function '%s' has no binding for the 'go' target", name)
+			return nil
+		}}
+	}
+	fn, ok := lookupHost(binding)
+	if !ok {
+		return &Builtin{Name: name, Fn: func([]Value) Value {
+			errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "host function '%s' is not registered in the interpreter", binding)
+			return nil
+		}}
+	}
+	return &Builtin{Name: name, Fn: func(args []Value) Value { return e.callGo(fn, args) }}
+}
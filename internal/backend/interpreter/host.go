@@ -0,0 +1,85 @@
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/helpers/naming"
+)
+
+// Host holds the functions and values a Go program exposes to the modules it
+// runs. They are declared in the global scope, so every module can refer to
+// them by name without importing them, like the prelude.
+type Host struct {
+	values map[string]reflect.Value
+}
+
+func NewHost() *Host {
+	return &Host{values: map[string]reflect.Value{}}
+}
+
+// Registers the Go value under the name. Its Golden type is derived from its
+// Go type: integers are `Int`, floats are `Float`, strings are `String`,
+// booleans are `Bool` and functions of these types are functions, which may
+// also return an error raised as a runtime error. Functions received by host
+// functions are Golden functions, which the host may call back.
+//
+// Structs, maps, slices and pointers are rejected with an error, since the
+// language has no struct or collection type yet.
+func (h *Host) Register(name string, v any) error {
+	switch {
+	case naming.IsReservedName(name):
+		return fmt.Errorf("host name '%s' is reserved", name)
+	case !naming.IsValueName(name):
+		return fmt.Errorf("host name '%s' must start with a lowercase letter", name)
+	}
+	if _, ok := h.values[name]; ok {
+		return fmt.Errorf("host name '%s' already registered", name)
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return fmt.Errorf("host value '%s' is nil", name)
+	}
+	if _, err := goldenType(rv.Type()); err != nil {
+		return fmt.Errorf("host value '%s': %w", name, err)
+	}
+	if rv.Kind() == reflect.Func && rv.IsNil() {
+		return fmt.Errorf("host value '%s' is nil", name)
+	}
+	h.values[name] = rv
+	return nil
+}
+
+func (h *Host) names() []string {
+	names := []string{}
+	for name := range h.values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Declares the values of the host in the global scope of the checker.
+func (b *Interpreter) DeclareHost(scope *env.Scope) {
+	if b.Host == nil {
+		return
+	}
+	for _, name := range b.Host.names() {
+		// types are created for each build, since ids are assigned by the session
+		tp, _ := goldenType(b.Host.values[name].Type())
+		scope.Values.Set(name, env.VB(nil, tp))
+	}
+}
+
+// Declares the values of the host in the environment shared by all modules.
+func (e *Evaluator) declareHost(env *Env, host *Host) {
+	if host == nil {
+		return
+	}
+	for _, name := range host.names() {
+		env.Declare(name, e.must(e.fromGo(host.values[name])))
+	}
+}
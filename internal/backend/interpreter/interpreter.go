@@ -1,26 +1,75 @@
 package interpreter
 
-import "github.com/renatopp/golden/internal/compiler/ast"
+import (
+	"context"
+	"io"
+	"os"
 
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// Interpreter runs the typed AST directly, without generating code.
 type Interpreter struct {
+	Stdout io.Writer // Output of `print` and `println`
+	Host   *Host     // Functions and values of the host program, may be nil
+
+	// Sandbox of untrusted programs. The context stops the program when done,
+	// such as on its deadline, and the sandbox disables extern functions and
+	// the output builtins. Functions of the host are still available. The
+	// depth of sandboxed programs is limited to DefaultSandboxDepth when the
+	// limits have none.
+	Context context.Context
+	Limits  Limits
+	Sandbox bool
+
+	modules []*ast.Module
+	entry   *ast.Module
 }
 
 func NewBackend() *Interpreter {
-	return &Interpreter{}
+	return &Interpreter{Stdout: os.Stdout}
 }
 
-func (b *Interpreter) Initialize(targetPath string) {}
+func (b *Interpreter) Initialize(projectPath, targetPath string) {}
 
-func (b *Interpreter) BeforeCodeGeneration() {}
+func (b *Interpreter) BeforeCodeGeneration() {
+	b.modules = []*ast.Module{}
+	b.entry = nil
+}
 
 func (b *Interpreter) GenerateCode(filePath string, root *ast.Module, entry bool) {
-
+	b.modules = append(b.modules, root)
+	if entry {
+		b.entry = root
+	}
 }
 
 func (b *Interpreter) AfterCodeGeneration() {}
 
+// Runs the modules in dependency order, then calls the entry `main`.
 func (b *Interpreter) Run() {
+	eval := NewEvaluator()
+	eval.Context = b.Context
+	eval.Limits = b.Limits
+	eval.Sandbox = b.Sandbox
+	if b.Sandbox && eval.Limits.Depth == 0 {
+		eval.Limits.Depth = DefaultSandboxDepth
+	}
+	global := NewEnv()
+	declareBuiltins(global, b.Stdout, b.Sandbox)
+	eval.declareHost(global, b.Host)
+
+	var entryEnv *Env
+	for _, mod := range b.modules {
+		env := eval.Load(mod, global)
+		eval.Initialize(mod, env)
+		if mod == b.entry {
+			entryEnv = env
+		}
+	}
 
+	main := entryEnv.Lookup("main").Value.(*Function)
+	eval.Call(main, []Value{})
 }
 
 func (b *Interpreter) Build(outputPath string) {}
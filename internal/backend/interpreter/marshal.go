@@ -0,0 +1,237 @@
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+var errorType = reflect.TypeFor[error]()
+
+// Returns the Golden type of the Go type: integers are `Int`, floats are
+// `Float`, strings are `String`, booleans are `Bool` and functions of these
+// types are `Fn`. Functions may also return an error as their last result.
+// Function types are created on each call, since their ids are assigned by the
+// compilation session.
+func goldenType(t reflect.Type) (ast.Type, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return types.Int, nil
+	case reflect.Float32, reflect.Float64:
+		return types.Float, nil
+	case reflect.String:
+		return types.String, nil
+	case reflect.Bool:
+		return types.Bool, nil
+	case reflect.Func:
+		if t.IsVariadic() {
+			return nil, fmt.Errorf("variadic function %s has no Golden type", t)
+		}
+		params := []ast.Type{}
+		for i := range t.NumIn() {
+			p, err := goldenType(t.In(i))
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, p)
+		}
+
+		var ret ast.Type = types.Void
+		outs := resultTypes(t)
+		switch len(outs) {
+		case 0:
+		case 1:
+			r, err := goldenType(outs[0])
+			if err != nil {
+				return nil, err
+			}
+			ret = r
+		default:
+			return nil, fmt.Errorf("function %s returns more than one value", t)
+		}
+		return types.NewFunction(nil, params, ret), nil
+	}
+	return nil, fmt.Errorf("type %s has no Golden type", t)
+}
+
+// Returns the results of the function type, without the trailing error.
+func resultTypes(t reflect.Type) []reflect.Type {
+	outs := []reflect.Type{}
+	for i := range t.NumOut() {
+		outs = append(outs, t.Out(i))
+	}
+	if len(outs) > 0 && outs[len(outs)-1] == errorType {
+		outs = outs[:len(outs)-1]
+	}
+	return outs
+}
+
+// Converts the Go value to a Golden value. Go functions are wrapped in
+// builtins converting their arguments and results.
+func (e *Evaluator) fromGo(v reflect.Value) (Value, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > 1<<63-1 {
+			return nil, fmt.Errorf("value %d overflows Int", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Func:
+		if _, err := goldenType(v.Type()); err != nil {
+			return nil, err
+		}
+		if v.IsNil() {
+			return nil, fmt.Errorf("nil function")
+		}
+		return &Builtin{Name: v.Type().String(), Fn: func(args []Value) Value { return e.callGo(v, args) }}, nil
+	}
+	return nil, fmt.Errorf("type %s has no Golden type", v.Type())
+}
+
+// Converts the Golden value to the Go type. Golden functions are converted to
+// Go functions calling them, so the host can call them back.
+func (e *Evaluator) toGo(v Value, t reflect.Type) (reflect.Value, error) {
+	res := reflect.New(t).Elem()
+	switch v := v.(type) {
+	case int64:
+		switch {
+		case res.CanInt() && !res.OverflowInt(v):
+			res.SetInt(v)
+			return res, nil
+		case res.CanUint() && v >= 0 && !res.OverflowUint(uint64(v)):
+			res.SetUint(uint64(v))
+			return res, nil
+		}
+	case float64:
+		if res.CanFloat() {
+			res.SetFloat(v)
+			return res, nil
+		}
+	case string:
+		if t.Kind() == reflect.String {
+			res.SetString(v)
+			return res, nil
+		}
+	case bool:
+		if t.Kind() == reflect.Bool {
+			res.SetBool(v)
+			return res, nil
+		}
+	case *Function, *Builtin:
+		if t.Kind() == reflect.Func {
+			return e.goFunction(v, t)
+		}
+	}
+	return res, fmt.Errorf("cannot convert %s to %s", Format(v), t)
+}
+
+// Creates a Go function calling the Golden function. Errors are returned
+// when the Go function returns an error, and raised otherwise.
+func (e *Evaluator) goFunction(fn Value, t reflect.Type) (reflect.Value, error) {
+	if _, err := goldenType(t); err != nil {
+		return reflect.Value{}, err
+	}
+	outs := resultTypes(t)
+	returnsError := t.NumOut() > len(outs)
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.New(t.Out(i)).Elem()
+		}
+
+		call := func() {
+			args := make([]Value, len(in))
+			for i, a := range in {
+				args[i] = e.must(e.fromGo(a))
+			}
+			res := e.callValue(fn, args)
+			if len(outs) > 0 {
+				out[0] = e.mustGo(e.toGo(res, outs[0]))
+			}
+		}
+		if !returnsError {
+			call()
+		} else if err := errors.WithRecovery(call); err != nil {
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+		}
+		return out
+	}), nil
+}
+
+// Calls the Go function with the Golden arguments. Errors returned by the
+// function and panics are raised as runtime errors.
+func (e *Evaluator) callGo(fn reflect.Value, args []Value) Value {
+	t := fn.Type()
+	if t.NumIn() != len(args) {
+		errors.Throw(errors.RuntimeError, "function %s expects %d argument(s), but got %d", t, t.NumIn(), len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		in[i] = e.mustGo(e.toGo(a, t.In(i)))
+	}
+
+	out := callRecovering(fn, in)
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			errors.Throw(errors.RuntimeError, "%v", err)
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return Void
+	}
+	return e.must(e.fromGo(out[0]))
+}
+
+// Calls the Go function, raising its panics as runtime errors. Errors of the
+// compiler are raised unchanged, since they come from Golden callbacks.
+func callRecovering(fn reflect.Value, in []reflect.Value) []reflect.Value {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case errors.GoldenError, *errors.GoldenError:
+				panic(r)
+			}
+			errors.Throw(errors.RuntimeError, "host function panicked: %v", r)
+		}
+	}()
+	return fn.Call(in)
+}
+
+func (e *Evaluator) callValue(fn Value, args []Value) Value {
+	switch fn := fn.(type) {
+	case *Function:
+		return e.Call(fn, args)
+	case *Builtin:
+		return fn.Fn(args)
+	}
+	errors.Throw(errors.NotCallable, "value %s is not a function", Format(fn))
+	return nil
+}
+
+// Raises the error of a conversion as a runtime error.
+func (e *Evaluator) must(v Value, err error) Value {
+	if err != nil {
+		errors.Throw(errors.RuntimeError, "%v", err)
+	}
+	return v
+}
+
+func (e *Evaluator) mustGo(v reflect.Value, err error) reflect.Value {
+	if err != nil {
+		errors.Throw(errors.RuntimeError, "%v", err)
+	}
+	return v
+}
@@ -0,0 +1,94 @@
+package interpreter
+
+import (
+	"context"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// Limits of the resources a program may use, to run untrusted code. Zero
+// values are unlimited, except the depth of sandboxed programs, which is
+// DefaultSandboxDepth. Exceeding a limit stops the program with a
+// LimitExceeded error at the expression being evaluated.
+type Limits struct {
+	Steps int64 // Maximum number of evaluated expressions
+	Depth int   // Maximum depth of nested function calls, tail calls excluded
+
+	// Maximum number of allocated values: environments, variables, closures
+	// and strings built at runtime, counting a value per 8 bytes of string.
+	// Values are counted when allocated, the ones no longer used are not
+	// released.
+	Values int64
+}
+
+// Depth limit of sandboxed programs without one. Deep recursions overflow the
+// stack of the host, which cannot be recovered, so the depth of untrusted
+// programs is always bounded.
+const DefaultSandboxDepth = 10_000
+
+// How many steps are evaluated between checks of the context, whose error
+// is expensive compared to the evaluation of an expression.
+const contextInterval = 1024
+
+// Counts an evaluation step, stopping the program when the limit is exceeded
+// or the context is done.
+func (e *Evaluator) step(node ast.Node) {
+	e.steps++
+	if e.Limits.Steps > 0 && e.steps > e.Limits.Steps {
+		errors.ThrowAtNode(node, errors.LimitExceeded, "step limit of %d exceeded", e.Limits.Steps)
+	}
+	if e.Context != nil && e.steps%contextInterval == 0 {
+		if err := e.Context.Err(); err != nil {
+			errors.ThrowAtNode(node, errors.LimitExceeded, "execution stopped: %v", context.Cause(e.Context))
+		}
+	}
+}
+
+// Counts the values allocated by the node.
+func (e *Evaluator) alloc(node ast.Node, n int64) {
+	e.values += n
+	if e.Limits.Values > 0 && e.values > e.Limits.Values {
+		errors.ThrowAtNode(node, errors.LimitExceeded, "value limit of %d exceeded", e.Limits.Values)
+	}
+}
+
+func (e *Evaluator) allocString(node ast.Node, s string) {
+	e.alloc(node, 1+int64(len(s))/8)
+}
+
+// Enters the function called by the node, returning the function leaving it.
+func (e *Evaluator) enter(node ast.Node) func() {
+	e.depth++
+	if e.Limits.Depth > 0 && e.depth > e.Limits.Depth {
+		errors.ThrowAtNode(node, errors.LimitExceeded, "call depth limit of %d exceeded", e.Limits.Depth)
+	}
+	return func() { e.depth-- }
+}
+
+// Calls the builtin, reporting its errors at the application when they have
+// no location, such as the errors of host functions.
+func (e *Evaluator) callBuiltin(node *ast.Application, fn *Builtin, args []Value) Value {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(errors.GoldenError); ok && !err.Loc.Has() {
+				r = err.WithNode(node)
+			}
+			panic(r)
+		}
+	}()
+	res := fn.Fn(args)
+	if s, ok := res.(string); ok {
+		e.allocString(node, s)
+	}
+	return res
+}
+
+// Creates a builtin refusing to run, for the capabilities disabled by the
+// sandbox.
+func disabled(name, capability string) *Builtin {
+	return &Builtin{Name: name, Fn: func([]Value) Value {
+		errors.Throw(errors.CapabilityDisabled, "%s '%s' is disabled by the sandbox", capability, name)
+		return nil
+	}}
+}
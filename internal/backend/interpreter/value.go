@@ -0,0 +1,51 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/renatopp/golden/internal/backend/golang/runtime"
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// Value is any runtime value: int64, float64, string, bool, *Function,
+// *Builtin or Void. Values are converted from and to Go values of the host by
+// fromGo and toGo.
+type Value any
+
+type void struct{}
+
+// Void is the value of expressions without a result.
+var Void = void{}
+
+func (void) String() string { return "void" }
+
+// Function is a closure over the environment where it was declared.
+type Function struct {
+	Decl *ast.FnDecl
+	Env  *Env
+}
+
+func (f *Function) String() string {
+	if f.Decl.Name.Has() {
+		return fmt.Sprintf("<fn %s>", f.Decl.Name.Unwrap().Value)
+	}
+	return "<fn>"
+}
+
+// Formats the value as the other backends would print it, using the
+// formatting of the Go runtime.
+func Format(v Value) string {
+	switch v := v.(type) {
+	case int64:
+		return runtime.FormatInt(v)
+	case float64:
+		return runtime.FormatFloat(v)
+	case string:
+		return v
+	case bool:
+		return runtime.FormatBool(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -1,8 +1,10 @@
 package javascript
 
 import (
+	"bytes"
 	_ "embed"
 	"log"
+	"maps"
 	"os"
 	"os/exec"
 	"path"
@@ -17,44 +19,66 @@ import (
 var raw_template_main string
 var template_main, _ = template.New("main").Parse(raw_template_main)
 
+//go:embed runtime/runtime.mjs
+var raw_runtime []byte
+
 type Javascript struct {
-	entryRef        *Ref
-	backendMainPath string
+	projectDirectory   string // Root of the golden project
+	targetDirectory    string // Root of the generated javascript project
+	entryRef           *Ref
+	backendMainPath    string
+	backendRuntimePath string
+	names              map[string]string // Golden names of the generated code, by JavaScript name
 }
 
 func NewBackend() *Javascript {
-	return &Javascript{}
+	return &Javascript{names: map[string]string{}}
 }
 
-func (b *Javascript) Initialize(targetPath string) {
-	targetDirectory = path.Join(targetPath, "javascript")
-	b.backendMainPath = path.Join(targetDirectory, "main.mjs")
+func (b *Javascript) Initialize(projectPath, targetPath string) {
+	b.projectDirectory = projectPath
+	b.targetDirectory = path.Join(targetPath, "javascript")
+	b.backendMainPath = path.Join(b.targetDirectory, "main.mjs")
+	b.backendRuntimePath = path.Join(b.targetDirectory, "runtime.mjs")
 }
 
 func (b *Javascript) BeforeCodeGeneration() {
-	fs.GuaranteeDirectoryExists(targetDirectory)
+	fs.GuaranteeDirectoryExists(b.targetDirectory)
 }
 
 func (b *Javascript) GenerateCode(goldenFilePath string, root *ast.Module, entry bool) {
 	if entry {
-		b.entryRef = R(goldenFilePath, "main")
+		b.entryRef = b.R(goldenFilePath, "main")
+	}
+	if root == nil {
+		return
 	}
-	backendFilePath := BackendPath(goldenFilePath)
+	backendFilePath := b.BackendPath(goldenFilePath)
 	writer := NewWriter(b)
 	os.WriteFile(backendFilePath, []byte(writer.Generate(root)), 0644)
+	maps.Copy(b.names, writer.Names())
+}
+
+func (b *Javascript) HasOutput(goldenFilePath string) bool {
+	return fs.CheckFileExists(b.BackendPath(goldenFilePath)) == nil
 }
 
 func (b *Javascript) AfterCodeGeneration() {
 	os.WriteFile(b.backendMainPath, tmpl.GenerateBytes(template_main, map[string]any{
 		"EntryImport": b.entryRef.BackendImportPath,
 	}), 0644)
+	os.WriteFile(b.backendRuntimePath, raw_runtime, 0644)
 }
 
 func (b *Javascript) Run() {
+	// runtime errors are shown with the Golden names
+	stderr := &bytes.Buffer{}
 	cmd := exec.Command("node", b.backendMainPath)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	os.Stderr.WriteString(b.Demangle(stderr.String()))
+	if err != nil {
 		log.Fatalf("failed to run node: %v", err)
 	}
 }
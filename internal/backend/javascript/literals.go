@@ -0,0 +1,62 @@
+package javascript
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Formats the float with the shortest representation that reads back to the
+// same value. NaN and the infinities are globals, which are reserved names,
+// so they cannot be shadowed by the generated code.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "Infinity"
+	case math.IsInf(v, -1):
+		return "-Infinity"
+	case v == 0 && math.Signbit(v):
+		return "-0"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Quotes the string as a JavaScript string literal. Go escapes such as `\x00`
+// and `\U0001F600` are not valid JavaScript, so control characters and the
+// line separators are written as `\uXXXX`. Bytes that are not valid UTF-8 are
+// replaced by U+FFFD, like the JavaScript decoders do.
+func quote(s string) string {
+	b := strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\u2028', '\u2029':
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package javascript
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/renatopp/golden/internal/helpers/codegen"
+)
+
+// Reserved words of JavaScript modules, which run in strict mode, and the
+// global values that cannot be redeclared or would be shadowed.
+var reserved = []string{
+	// keywords
+	"await", "break", "case", "catch", "class", "const", "continue",
+	"debugger", "default", "delete", "do", "else", "enum", "export", "extends",
+	"false", "finally", "for", "function", "if", "import", "in", "instanceof",
+	"let", "new", "null", "return", "static", "super", "switch", "this",
+	"throw", "true", "try", "typeof", "var", "void", "while", "with", "yield",
+	// strict mode
+	"arguments", "eval", "implements", "interface", "package", "private",
+	"protected", "public",
+	// globals
+	"undefined", "NaN", "Infinity", "globalThis", "console",
+	// modules imported by the generated code
+	"runtime",
+}
+
+// Returns the name of the runtime function implementing the builtin, ex:
+// `__string_has_prefix` is `runtime.stringHasPrefix`.
+func builtinName(name string) string {
+	parts := strings.Split(strings.TrimPrefix(name, "__"), "_")
+	for i, p := range parts[1:] {
+		parts[i+1] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// Golden identifiers cannot contain `$`, so escaped names never collide with
+// other names.
+func newMangler() *codegen.Mangler {
+	identity := func(s string) string { return s }
+	return codegen.NewMangler(reserved, identity, func(s string) string { return s + "$" })
+}
+
+var escapedName = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*\$)`)
+
+// Replaces the escaped names of the generated code with the Golden names.
+// Names of modules that were not generated in this build are converted back
+// by removing the escape.
+func (b *Javascript) Demangle(text string) string {
+	return escapedName.ReplaceAllStringFunc(text, func(s string) string {
+		if name, ok := b.names[s]; ok {
+			return name
+		}
+		return s[:len(s)-1]
+	})
+}
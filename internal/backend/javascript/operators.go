@@ -0,0 +1,81 @@
+package javascript
+
+import (
+	"math"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/compiler/types"
+)
+
+type operator struct {
+	Symbol     string
+	Precedence int
+}
+
+// Binary operators of Golden, by their JavaScript operators and precedences.
+// Golden and JavaScript do not agree on the precedences, ex: `%` binds tighter
+// than `*` in Golden, so parentheses are added following the JavaScript
+// precedences. `xor` is only defined for booleans, where it is the same as
+// `!==`.
+var operators = map[string]operator{
+	token.KindToLiteral(token.TOr):           {"||", 1},
+	token.KindToLiteral(token.TAnd):          {"&&", 2},
+	token.KindToLiteral(token.TXor):          {"!==", 3},
+	token.KindToLiteral(token.TEqual):        {"===", 3},
+	token.KindToLiteral(token.TNotEqual):     {"!==", 3},
+	token.KindToLiteral(token.TLess):         {"<", 4},
+	token.KindToLiteral(token.TLessEqual):    {"<=", 4},
+	token.KindToLiteral(token.TGreater):      {">", 4},
+	token.KindToLiteral(token.TGreaterEqual): {">=", 4},
+	token.KindToLiteral(token.TPlus):         {"+", 5},
+	token.KindToLiteral(token.TMinus):        {"-", 5},
+	token.KindToLiteral(token.TStar):         {"*", 6},
+	token.KindToLiteral(token.TSlash):        {"/", 6},
+	token.KindToLiteral(token.TPercent):      {"%", 6},
+}
+
+// Runtime functions of the integer division and remainder, which check the
+// divisor and truncate the quotient, since JavaScript numbers are floats.
+var intDivisions = map[string]string{
+	token.KindToLiteral(token.TSlash):   "divInt",
+	token.KindToLiteral(token.TPercent): "modInt",
+}
+
+// Returns the runtime function replacing the operator, if any.
+func intDivision(node *ast.BinOp) (string, bool) {
+	fn, ok := intDivisions[node.Op]
+	if !ok || node.LeftExpr.GetType().Unwrap() != types.Int {
+		return "", false
+	}
+	return fn, true
+}
+
+const (
+	unaryPrecedence   = 7
+	primaryPrecedence = 8
+)
+
+// Returns the JavaScript precedence of the code generated for the node.
+// Negative numbers, including `-Infinity` and `-0`, are written with a unary
+// minus.
+func precedence(node ast.Node) int {
+	switch node := node.(type) {
+	case *ast.BinOp:
+		if _, ok := intDivision(node); ok {
+			return primaryPrecedence
+		}
+		return operators[node.Op].Precedence
+	case *ast.UnaryOp:
+		return unaryPrecedence
+	case *ast.Int:
+		if node.Value < 0 {
+			return unaryPrecedence
+		}
+	case *ast.Float:
+		if math.Signbit(node.Value) && !math.IsNaN(node.Value) {
+			return unaryPrecedence
+		}
+	}
+	return primaryPrecedence
+}
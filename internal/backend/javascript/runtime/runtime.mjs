@@ -0,0 +1,231 @@
+'use strict'
+
+// Support library of the generated JavaScript code. It is embedded in the
+// compiler and written to the target next to `main.mjs`.
+//
+// The Go backend has the same primitives in `runtime.go`, which must behave
+// exactly like these ones.
+
+// Panic raised by the Golden program, at the location of the Golden
+// expression that failed.
+export class GoldenError extends Error {
+  constructor(message, location) {
+    super(location ? `${message} at ${location}` : message)
+    this.name = 'GoldenError'
+    this.golden = message
+    this.location = location
+  }
+}
+
+// Runs the entry function. Panics raised by the Golden program are printed
+// with their Golden location and exit with status 2, like the Go panics.
+export function main(main) {
+  try {
+    main()
+  } catch (err) {
+    if (!(err instanceof GoldenError)) {
+      throw err
+    }
+    process.stderr.write(`panic: ${err.golden}\n`)
+    if (err.location) {
+      process.stderr.write(`    at ${err.location}\n`)
+    }
+    process.exit(2)
+  }
+}
+
+export function panic(message, location) {
+  throw new GoldenError(message, location)
+}
+
+export function assert(cond, message, location) {
+  if (!cond) {
+    panic(`assertion failed: ${message}`, location)
+  }
+}
+
+//
+//
+//
+
+export function print(s) {
+  process.stdout.write(s)
+}
+
+export function println(s) {
+  process.stdout.write(s + '\n')
+}
+
+export function formatInt(v) {
+  return String(v)
+}
+
+// Floats are formatted like `strconv.FormatFloat(v, 'g', -1, 64)` in Go: the
+// shortest representation that reads back to the same value, using exponents
+// below 1e-4 and from 1e6 with at least two digits, ex: `0.5`, `1e-05` and
+// `1.5e+06`. Non-finite values are `NaN`, `+Inf` and `-Inf`.
+export function formatFloat(v) {
+  if (Number.isNaN(v)) {
+    return 'NaN'
+  }
+  if (!Number.isFinite(v)) {
+    return v > 0 ? '+Inf' : '-Inf'
+  }
+  if (v === 0) {
+    return Object.is(v, -0) ? '-0' : '0'
+  }
+
+  const [mantissa, e] = v.toExponential().split('e')
+  const exp = Number(e)
+  if (exp < -4 || exp >= 6) {
+    return `${mantissa}e${exp < 0 ? '-' : '+'}${String(Math.abs(exp)).padStart(2, '0')}`
+  }
+  return String(v)
+}
+
+export function formatBool(v) {
+  return String(v)
+}
+
+//
+//
+//
+
+// Integer division, truncated towards zero.
+export function divInt(a, b, location) {
+  if (b === 0) {
+    panic('division by zero', location)
+  }
+  return Math.trunc(a / b)
+}
+
+// Remainder of the integer division, with the sign of the dividend.
+export function modInt(a, b, location) {
+  if (b === 0) {
+    panic('division by zero', location)
+  }
+  return a % b
+}
+
+//
+//
+//
+
+// Number of characters, not UTF-16 code units, of the string.
+export function stringLength(s) {
+  let count = 0
+  for (const _ of s) {
+    count++
+  }
+  return count
+}
+
+// Counts below one result in an empty string.
+export function stringRepeat(s, count) {
+  return count <= 0 ? '' : s.repeat(count)
+}
+
+export function stringContains(s, sub) {
+  return s.includes(sub)
+}
+
+export function stringHasPrefix(s, prefix) {
+  return s.startsWith(prefix)
+}
+
+export function stringHasSuffix(s, suffix) {
+  return s.endsWith(suffix)
+}
+
+// Characters are converted one by one, like in Go, so the characters mapped
+// to many characters by JavaScript, ex: `ß` to `SS`, are kept.
+function mapChars(s, fn) {
+  let res = ''
+  for (const c of s) {
+    const mapped = fn(c)
+    res += [...mapped].length === 1 ? mapped : c
+  }
+  return res
+}
+
+export function stringUpper(s) {
+  return mapChars(s, (c) => c.toUpperCase())
+}
+
+export function stringLower(s) {
+  return mapChars(s, (c) => c.toLowerCase())
+}
+
+// White space are the characters of the Unicode `White_Space` property, like
+// in Go, which differs from `String.prototype.trim` by `\u0085` and `\ufeff`.
+const spaces = '[\\t\\n\\v\\f\\r \\u0085\\u00a0\\u1680\\u2000-\\u200a\\u2028\\u2029\\u202f\\u205f\\u3000]'
+const trimmed = new RegExp(`^${spaces}+|${spaces}+$`, 'g')
+
+export function stringTrim(s) {
+  return s.replace(trimmed, '')
+}
+
+//
+//
+//
+
+export function floatAbs(x) {
+  return Math.abs(x)
+}
+
+export function floatSqrt(x) {
+  return Math.sqrt(x)
+}
+
+// Follows the special cases of Go, where `1 ** NaN` and `(-1) ** ±Infinity`
+// are 1.
+export function floatPow(x, y) {
+  if (x === 1 || (x === -1 && !Number.isFinite(y) && !Number.isNaN(y))) {
+    return 1
+  }
+  return x ** y
+}
+
+export function floatFloor(x) {
+  return Math.floor(x)
+}
+
+export function floatCeil(x) {
+  return Math.ceil(x)
+}
+
+export function floatMin(x, y) {
+  return Math.min(x, y)
+}
+
+export function floatMax(x, y) {
+  return Math.max(x, y)
+}
+
+export function intAbs(x) {
+  return Math.abs(x)
+}
+
+export function intMin(x, y) {
+  return Math.min(x, y)
+}
+
+export function intMax(x, y) {
+  return Math.max(x, y)
+}
+
+export function intToFloat(x) {
+  return x
+}
+
+const maxInt = 9223372036854775807
+const minInt = -9223372036854775808
+
+// Truncates the float towards zero. Values out of the Int range are clamped
+// and `NaN` is converted to zero.
+export function floatToInt(x) {
+  if (Number.isNaN(x)) {
+    return 0
+  }
+  return Math.trunc(Math.min(Math.max(x, minInt), maxInt))
+}
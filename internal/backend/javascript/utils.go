@@ -15,26 +15,24 @@ type Ref struct {
 	BackendIdentifier string
 }
 
-var targetDirectory = ""
-
-func R(filepath, identifier string) *Ref {
+func (b *Javascript) R(filepath, identifier string) *Ref {
 	return &Ref{
 		GoldenFilePath:    filepath,
 		GoldenIdentifier:  identifier,
-		BackendFilePath:   BackendPath(filepath),
-		BackendImportPath: BackendImportPath(filepath),
+		BackendFilePath:   b.BackendPath(filepath),
+		BackendImportPath: b.BackendImportPath(filepath),
 		BackendIdentifier: BackendIdentifier(identifier),
 	}
 }
 
 // Returns the absolute path of the backend file
-func BackendPath(goldenFilepath string) string {
-	file := _relativeBackendPath(goldenFilepath)
-	return path.Join(targetDirectory, file)
+func (b *Javascript) BackendPath(goldenFilepath string) string {
+	file := b.relativeBackendPath(goldenFilepath)
+	return path.Join(b.targetDirectory, file)
 }
 
-func BackendImportPath(goldenFilepath string) string {
-	file := _relativeBackendPath(goldenFilepath)
+func (b *Javascript) BackendImportPath(goldenFilepath string) string {
+	file := b.relativeBackendPath(goldenFilepath)
 	return "./" + file
 }
 
@@ -42,14 +40,17 @@ func BackendIdentifier(goldenIdentifier string) string {
 	return goldenIdentifier
 }
 
-func _relativeBackendPath(goldenFilepath string) string {
+func (b *Javascript) relativeBackendPath(goldenFilepath string) string {
 	// if filepath is from project, root/**
 	// if filepath is from core, core/**
 	// if filepath is from packages, package<name>/**
 
 	file := ""
-	if fs.IsProjectPath(goldenFilepath) {
-		relative := fs.ToLinuxSlash(fs.GetProjectRelativePath(goldenFilepath))
+	if fs.IsStdPath(goldenFilepath) {
+		relative := fs.ToLinuxSlash(fs.GetRelativePath(fs.StdDir, goldenFilepath))
+		file = "core" + strings.ReplaceAll(relative, "/", "_")
+	} else if fs.IsPathInside(b.projectDirectory, goldenFilepath) {
+		relative := fs.ToLinuxSlash(fs.GetRelativePath(b.projectDirectory, goldenFilepath))
 		file = "root" + strings.ReplaceAll(relative, "/", "_")
 	} else {
 		panic("BackendPath not implemented: " + goldenFilepath)
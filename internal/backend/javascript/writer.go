@@ -3,11 +3,16 @@ package javascript
 import (
 	_ "embed"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
 	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/compiler/types"
 	"github.com/renatopp/golden/internal/helpers/codegen"
 	"github.com/renatopp/golden/internal/helpers/errors"
 	"github.com/renatopp/golden/internal/helpers/naming"
@@ -26,11 +31,16 @@ type Writer struct {
 	stack      []string
 	identLevel int
 	funcLevel  int
+	functions  []*ast.FnDecl
+	imports    map[string]string // Import paths of the used modules, by alias
+	names      *codegen.Mangler
 }
 
 func NewWriter(backend *Javascript) *Writer {
 	w := &Writer{
 		backend: backend,
+		imports: map[string]string{},
+		names:   newMangler(),
 	}
 	w.Visiter = ast.NewVisiter(w)
 	return w
@@ -48,8 +58,13 @@ func (w *Writer) Pop() string {
 
 func (w *Writer) Generate(root *ast.Module) string {
 	root.Visit(w)
+	imports := []map[string]string{}
+	for _, alias := range slices.Sorted(maps.Keys(w.imports)) {
+		imports = append(imports, map[string]string{"Alias": alias, "Path": w.imports[alias]})
+	}
 	return tmpl.GenerateString(template_module, map[string]any{
-		"Exprs": w.Pop(),
+		"Imports": imports,
+		"Exprs":   w.Pop(),
 	})
 }
 
@@ -75,8 +90,14 @@ func (w *Writer) VisitVarDecl(node *ast.VarDecl) ast.Node {
 	return node
 }
 
+// Builtins are functions of the runtime module.
 func (w *Writer) VisitVarIdent(node *ast.VarIdent) ast.Node {
-	w.Push(node.Value)
+	if _, ok := builtins.Lookup(node.Value); ok {
+		w.imports["runtime"] = "./runtime.mjs"
+		w.Push("runtime." + builtinName(node.Value))
+		return node
+	}
+	w.Push(w.names.Mangle(node.Value))
 	return node
 }
 
@@ -86,12 +107,12 @@ func (w *Writer) VisitInt(node *ast.Int) ast.Node {
 }
 
 func (w *Writer) VisitFloat(node *ast.Float) ast.Node {
-	w.Push(fmt.Sprintf("%f", node.Value))
+	w.Push(formatFloat(node.Value))
 	return node
 }
 
 func (w *Writer) VisitString(node *ast.String) ast.Node {
-	w.Push(fmt.Sprintf("%q", node.Value))
+	w.Push(quote(node.Value))
 	return node
 }
 
@@ -101,56 +122,37 @@ func (w *Writer) VisitBool(node *ast.Bool) ast.Node {
 }
 
 func (w *Writer) VisitBinOp(node *ast.BinOp) ast.Node {
-	node.LeftExpr.Visit(w)
-	left := w.Pop()
-
-	node.RightExpr.Visit(w)
-	right := w.Pop()
-
-	op := ""
-	switch node.Op {
-	case token.KindToLiteral(token.TAnd):
-		op = "&&"
-	case token.KindToLiteral(token.TOr):
-		op = "||"
-	case token.KindToLiteral(token.TEqual):
-		op = "==="
-	case token.KindToLiteral(token.TNotEqual):
-		op = "!=="
-	case token.KindToLiteral(token.TLess):
-		op = "<"
-	case token.KindToLiteral(token.TLessEqual):
-		op = "<="
-	case token.KindToLiteral(token.TGreater):
-		op = ">"
-	case token.KindToLiteral(token.TGreaterEqual):
-		op = ">="
-	case token.KindToLiteral(token.TPlus):
-		op = "+"
-	case token.KindToLiteral(token.TMinus):
-		op = "-"
-	case token.KindToLiteral(token.TStar):
-		op = "*"
-	case token.KindToLiteral(token.TSlash):
-		op = "/"
-	case token.KindToLiteral(token.TPercent):
-		op = "%"
-	case token.KindToLiteral(token.TSpaceShip):
+	if node.Op == token.KindToLiteral(token.TSpaceShip) {
 		errors.ThrowAtNode(node, errors.NotImplemented, "Spaceship operator not implemented yet")
-		// term := fmt.Sprintf("((%s < %s) ? -1 : (%s > %s) ? 1 : 0)", left, right, left, right)
-		// w.Push(term)
 		return node
 	}
 
-	w.Push(fmt.Sprintf("(%s %s %s)", left, op, right))
+	if fn, ok := intDivision(node); ok {
+		node.LeftExpr.Visit(w)
+		left := w.Pop()
+		node.RightExpr.Visit(w)
+		right := w.Pop()
+		w.imports["runtime"] = "./runtime.mjs"
+		w.Push(fmt.Sprintf("runtime.%s(%s, %s, %s)", fn, left, right, quote(w.location(node))))
+		return node
+	}
+
+	op := operators[node.Op]
+	left := w.operand(node.LeftExpr, op.Precedence)
+	right := w.operand(node.RightExpr, op.Precedence+1)
+	w.Push(fmt.Sprintf("%s %s %s", left, op.Symbol, right))
 	return node
 }
 
+// Operands starting with the same sign are wrapped, since `--x` would be a
+// decrement in JavaScript.
 func (w *Writer) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
-	node.RightExpr.Visit(w)
-	right := w.Pop()
+	right := w.operand(node.RightExpr, unaryPrecedence)
+	if strings.HasPrefix(right, node.Op) && node.Op != "!" {
+		right = "(" + right + ")"
+	}
 
-	w.Push(fmt.Sprintf("%s%s", node.Op, right))
+	w.Push(node.Op + right)
 	return node
 }
 
@@ -168,7 +170,7 @@ func (w *Writer) VisitBlock(node *ast.Block) ast.Node {
 func (w *Writer) VisitFnDecl(node *ast.FnDecl) ast.Node {
 	name := ""
 	if node.Name.Has() {
-		name = node.Name.Unwrap().Value
+		name = w.names.Mangle(node.Name.Unwrap().Value)
 	}
 	export := w.visibility(name)
 
@@ -179,8 +181,19 @@ func (w *Writer) VisitFnDecl(node *ast.FnDecl) ast.Node {
 
 	w.identLevel++
 	w.funcLevel++
+	w.functions = append(w.functions, node)
 	node.ValueExpr.Visit(w)
-	body := w.ident(w.Pop())
+	body := w.Pop()
+	w.functions = w.functions[:len(w.functions)-1]
+
+	// self tail calls reassign the parameters and restart the loop
+	if node.TailCalls {
+		if node.TypeExpr.GetType().Unwrap() == types.Void {
+			body += "\nreturn"
+		}
+		body = fmt.Sprintf("while (true) {\n%s\n}", w.ident(body))
+	}
+	body = w.ident(body)
 	w.funcLevel--
 	w.identLevel--
 
@@ -188,12 +201,74 @@ func (w *Writer) VisitFnDecl(node *ast.FnDecl) ast.Node {
 	return node
 }
 
+// Extern functions wrap the bound JavaScript function. Bindings in the
+// `<specifier>#<export>` format import the export of a module, other bindings
+// are paths of global values, such as `Math.sqrt`.
+func (w *Writer) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	binding, ok := node.Binding("js")
+	if !ok {
+		errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "extern function '%s' has no binding for the 'js' target", node.Name.Value)
+	}
+
+	target := "globalThis." + binding
+	if spec, export, ok := strings.Cut(binding, "#"); ok {
+		if spec == "" || export == "" {
+			errors.ThrowAtNode(node.Name, errors.InvalidExternBinding, "invalid JavaScript binding '%s'", binding)
+		}
+		target = w.importHost(spec) + "." + export
+	}
+
+	name := w.names.Mangle(node.Name.Value)
+	params := codegen.JoinList(", ", node.Params, func(p *ast.FnDeclParam) string {
+		p.Visit(w)
+		return w.Pop()
+	})
+
+	call := fmt.Sprintf("%s(%s)", target, params)
+	if node.TypeExpr.GetType().Unwrap() != types.Void {
+		call = "return " + call
+	}
+	w.identLevel++
+	body := w.ident(call)
+	w.identLevel--
+	w.Push(fmt.Sprintf("%sfunction %s(%s) {\n%s\n}", w.visibility(name), name, params, body))
+	return node
+}
+
+// Imports a JavaScript module used by extern functions. Aliases start with
+// `__js_`, which Golden names cannot, so they do not collide with the module
+// names.
+func (w *Writer) importHost(spec string) string {
+	base := spec[strings.LastIndexAny(spec, "/:")+1:]
+	if i := strings.Index(base, "."); i > 0 {
+		base = base[:i]
+	}
+	base = "__js_" + strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, base)
+
+	alias := base
+	for i := 2; w.imports[alias] != "" && w.imports[alias] != spec; i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	w.imports[alias] = spec
+	return alias
+}
+
 func (w *Writer) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
-	w.Push(node.Name.Value)
+	w.Push(w.names.Mangle(node.Name.Value))
 	return node
 }
 
 func (w *Writer) VisitReturn(node *ast.Return) ast.Node {
+	if node.TailCall {
+		w.tailCall(node.ValueExpr.Unwrap().(*ast.Application))
+		return node
+	}
+
 	if node.ValueExpr.Has() {
 		node.ValueExpr.Unwrap().Visit(w)
 		value := w.Pop()
@@ -204,6 +279,16 @@ func (w *Writer) VisitReturn(node *ast.Return) ast.Node {
 	return node
 }
 
+// Members of other modules are accessed through the namespace of the imported
+// module, which is imported only when used.
+func (w *Writer) VisitAccess(node *ast.Access) ast.Node {
+	alias := w.names.MangleRaw(node.Target.(*ast.VarIdent).Value)
+	mod := node.Target.GetType().Unwrap().(*types.Module)
+	w.imports[alias] = w.backend.BackendImportPath(mod.Path)
+	w.Push(alias + "." + w.names.Mangle(node.Name.Value))
+	return node
+}
+
 func (w *Writer) VisitApplication(node *ast.Application) ast.Node {
 	node.Target.Visit(w)
 	target := w.Pop()
@@ -217,6 +302,49 @@ func (w *Writer) VisitApplication(node *ast.Application) ast.Node {
 	return node
 }
 
+// Assigns all arguments at once, so each argument is evaluated with the
+// parameter values of the current iteration.
+func (w *Writer) tailCall(call *ast.Application) {
+	fn := w.functions[len(w.functions)-1]
+	args := codegen.JoinList(", ", call.Args, func(a ast.Node) string {
+		a.Visit(w)
+		return w.Pop()
+	})
+
+	switch len(fn.Params) {
+	case 0:
+		w.Push("continue")
+	case 1:
+		w.Push(fmt.Sprintf("%s = %s\ncontinue", w.names.Mangle(fn.Params[0].Name.Value), args))
+	default:
+		params := codegen.JoinList(", ", fn.Params, func(p *ast.FnDeclParam) string { return w.names.Mangle(p.Name.Value) })
+		w.Push(fmt.Sprintf(";[%s] = [%s]\ncontinue", params, args))
+	}
+}
+
+// Visits the operand, wrapping it in parentheses when it binds looser than the
+// given precedence. Operators of the same precedence are left associative, so
+// right operands require a higher precedence.
+func (w *Writer) operand(node ast.Node, prec int) string {
+	node.Visit(w)
+	code := w.Pop()
+	if precedence(node) < prec {
+		return "(" + code + ")"
+	}
+	return code
+}
+
+// Returns the location of the node shown by runtime errors.
+func (w *Writer) location(node ast.Node) string {
+	return codegen.Location(w.backend.projectDirectory, node)
+}
+
+// Returns the Golden names of the identifiers of the module, by their
+// JavaScript names.
+func (w *Writer) Names() map[string]string {
+	return w.names.Origins()
+}
+
 func (w *Writer) visibility(name string) string {
 	if naming.IsPrivateName(name) || w.funcLevel > 0 {
 		return ""
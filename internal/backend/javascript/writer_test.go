@@ -0,0 +1,56 @@
+package javascript_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/backend/javascript"
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected outputs in testdata")
+
+// Checks and folds the module, then compares the generated code with the
+// expected output in testdata.
+func generate(t *testing.T, name string) {
+	source, err := filepath.Abs(filepath.Join("testdata", name+".gold"))
+	require.NoError(t, err)
+	expected := filepath.Join("testdata", name+".mjs.golden")
+
+	opts := buildertest.Options(t, source)
+	opts.Passes = []string{"const-fold"}
+
+	var root *ast.Module
+	opts.OnOptimizationReady.Subscribe(func(_ *builder.File, mod *ast.Module) { root = mod })
+	_, err = builder.NewBuilder(opts).Lower()
+	require.NoError(t, err)
+
+	backend := javascript.NewBackend()
+	backend.Initialize(opts.WorkingDir, opts.LocalTargetPath)
+	code := javascript.NewWriter(backend).Generate(root)
+	if *update {
+		require.NoError(t, os.WriteFile(expected, []byte(code), 0644))
+	}
+	bytes, err := os.ReadFile(expected)
+	require.NoError(t, err)
+	assert.Equal(t, string(bytes), code)
+	assert.NotContains(t, code, "\r", "generated code must use '\\n' line endings")
+}
+
+func TestGenerateLiterals(t *testing.T) {
+	generate(t, "literals")
+}
+
+func TestGenerateDivisions(t *testing.T) {
+	generate(t, "divisions")
+}
+
+func TestGenerateExterns(t *testing.T) {
+	generate(t, "externs")
+}
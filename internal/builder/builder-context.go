@@ -2,13 +2,18 @@ package builder
 
 import (
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
 	"github.com/renatopp/golden/internal/helpers/ds"
 )
 
 type BuildContext struct {
+	Session         *Session
 	Options         *BuildOptions
 	ModuleRegistry  *ds.SyncMap[string, *File]
 	EntryModule     *File
 	DependencyOrder []*File
 	GlobalScope     *env.Scope
+	StdScope        *env.Scope // Global scope with the builtins, parent of the standard library modules
+	PassManager     *optimizations.PassManager
+	Cache           *ModuleCache
 }
@@ -1,11 +1,19 @@
 package builder
 
 import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/renatopp/golden"
 	"github.com/renatopp/golden/internal/backend"
 	"github.com/renatopp/golden/internal/backend/golang"
 	"github.com/renatopp/golden/internal/compiler/ast"
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
 	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/errors"
 	"github.com/renatopp/golden/internal/helpers/events"
 	"github.com/renatopp/golden/internal/helpers/fs"
 )
@@ -19,8 +27,25 @@ type BuildOptions struct {
 	LocalTargetPath  string // Absolute path of the local target directory for storing transpiled files
 	GlobalTargetPath string // Absolute path of the global target directory for storing transpiled files
 
+	// Sources replacing the content of the files on disk, by absolute path,
+	// such as the buffers of an editor or the result of fixes not written yet
+	Overlay map[string][]byte
+
+	// Sources are only read from the overlay and the standard library, and
+	// nothing is written to the cache and target directories, such as when
+	// compiling sources given by a host program
+	InMemory bool
+
 	// Backend
 	OutputTarget backend.Backend // Output targets for the backend
+	NoCache      bool            // Compiles every module from the source, ignoring the module cache
+
+	// Optimizations
+	OptimizationLevel int      // Optimization level, from 0 (only lowering) to 2
+	Passes            []string // Explicit selection of passes, replacing the ones enabled by the level
+
+	// Diagnostics
+	Suppress []errors.ErrorCode // Warnings that are not reported
 
 	// Events
 	OnTokensReady          *events.Signal2[*File, []*token.Token]
@@ -28,6 +53,50 @@ type BuildOptions struct {
 	OnDependencyGraphReady *events.Signal1[[]*File]
 	OnTypeCheckReady       *events.Signal3[*File, *ast.Module, *env.Scope]
 	OnOptimizationReady    *events.Signal2[*File, *ast.Module]
+	OnPassReady            *events.Signal2[*File, *optimizations.PassReport]
+	OnIRReady              *events.Signal2[*File, *ir.Module]
+	OnWarning              *events.Signal1[errors.GoldenError]
+}
+
+// Returns the source of the module, from the overlay, the standard library
+// embedded in the compiler or the disk.
+func (o *BuildOptions) ReadSource(modulePath string) ([]byte, error) {
+	if bytes, ok := o.Overlay[modulePath]; ok {
+		return bytes, nil
+	}
+	if fs.IsStdPath(modulePath) {
+		return golden.Std.ReadFile(stdFileName(modulePath))
+	}
+	if o.InMemory {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(modulePath)
+}
+
+// Checks if the source of the module exists in the overlay, the standard
+// library or the disk.
+func (o *BuildOptions) HasSource(modulePath string) bool {
+	if _, ok := o.Overlay[modulePath]; ok {
+		return true
+	}
+	if fs.IsStdPath(modulePath) {
+		_, err := golden.Std.Open(stdFileName(modulePath))
+		return err == nil
+	}
+	return !o.InMemory && fs.CheckFileExists(modulePath) == nil
+}
+
+// Checks if the source of the module is read from the disk.
+func (o *BuildOptions) IsOnDisk(modulePath string) bool {
+	_, ok := o.Overlay[modulePath]
+	return !ok && !o.InMemory && !fs.IsStdPath(modulePath)
+}
+
+// Returns the name of the module in the embedded file system, ex:
+// `<std>/math.gold` is `std/math.gold`.
+func stdFileName(modulePath string) string {
+	relative, _ := filepath.Rel(fs.StdDir, modulePath)
+	return path.Join("std", filepath.ToSlash(relative))
 }
 
 func NewBuildOptions(fileName string) *BuildOptions {
@@ -42,10 +111,16 @@ func NewBuildOptions(fileName string) *BuildOptions {
 
 		OutputTarget: golang.NewBackend(),
 
+		OptimizationLevel: optimizations.LevelDefault,
+		Passes:            []string{},
+
 		OnTokensReady:          events.NewSignal2[*File, []*token.Token](),
 		OnAstReady:             events.NewSignal2[*File, *ast.Module](),
 		OnDependencyGraphReady: events.NewSignal1[[]*File](),
 		OnTypeCheckReady:       events.NewSignal3[*File, *ast.Module, *env.Scope](),
 		OnOptimizationReady:    events.NewSignal2[*File, *ast.Module](),
+		OnPassReady:            events.NewSignal2[*File, *optimizations.PassReport](),
+		OnIRReady:              events.NewSignal2[*File, *ir.Module](),
+		OnWarning:              events.NewSignal1[errors.GoldenError](),
 	}
 }
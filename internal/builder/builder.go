@@ -2,12 +2,16 @@ package builder
 
 import (
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/renatopp/golden/internal/backend"
 	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
 	"github.com/renatopp/golden/internal/compiler/optimizations"
 	"github.com/renatopp/golden/internal/compiler/semantic"
 	"github.com/renatopp/golden/internal/compiler/types"
@@ -18,7 +22,10 @@ import (
 )
 
 type BuildResult struct {
-	Elapsed time.Duration
+	Elapsed     time.Duration
+	PassTimings []optimizations.PassTiming
+	CacheHits   int // Modules reused from the previous build
+	CacheMisses int // Modules compiled from the source
 }
 
 //
@@ -61,26 +68,79 @@ func (b *Builder) Run() (res *BuildResult, err error) {
 	return res, err
 }
 
+// Check parses and checks the modules, without generating code. The backend
+// is not used and nothing is written to the target and cache directories.
+// Entry modules without a `main` function are checked as libraries.
+func (b *Builder) Check() (res *BuildResult, err error) {
+	err = errors.WithRecovery(func() {
+		start := time.Now()
+		res = b.checkOnly()
+		res.Elapsed = time.Since(start)
+	})
+	return res, err
+}
+
+// Lower checks the modules like Check, then optimizes them and lowers them to
+// the IR for the subscribers of OnIRReady, without generating code.
+func (b *Builder) Lower() (res *BuildResult, err error) {
+	err = errors.WithRecovery(func() {
+		start := time.Now()
+		res = b.checkOnly()
+		b.ctx.PassManager = optimizations.NewPassManager(b.opts.OptimizationLevel, b.opts.Passes)
+		b.applyOptimizations()
+		b.lowerToIR()
+		res.PassTimings = b.ctx.PassManager.Timings()
+		res.Elapsed = time.Since(start)
+	})
+	return res, err
+}
+
 func (b *Builder) build() *BuildResult {
-	res := &BuildResult{}
-	b.ctx = &BuildContext{
-		Options:        b.opts,
-		ModuleRegistry: ds.NewSyncMap[string, *File](),
-		EntryModule:    nil,
-	}
+	res := b.check()
+	b.checkMain()
+	b.applyOptimizations()
+	b.lowerToIR()
+	b.generateCode()
+	b.storeCache()
+
+	res.PassTimings = b.ctx.PassManager.Timings()
+	return res
+}
 
-	fs.WorkingDir = b.ctx.Options.WorkingDir
+func (b *Builder) check() *BuildResult {
+	b.newContext()
 	b.validateEntry()
-	b.checkCacheFolders()
+	if !b.opts.InMemory {
+		b.checkCacheFolders()
+	}
+	b.prepareBackend()
+	return b.analyze()
+}
+
+// Loads the modules and checks their types.
+func (b *Builder) analyze() *BuildResult {
+	res := &BuildResult{}
 	b.loadModules()
 	b.checkEntries()
 	b.buildDependencyGraph()
 	b.buildGlobalScope()
+	b.restoreCachedModules()
 	b.semanticAnalysis()
-	b.checkMain()
-	b.applyOptimizations()
-	b.generateCode()
 
+	res.CacheHits = b.ctx.Cache.Hits
+	res.CacheMisses = b.ctx.Cache.Misses
+	return res
+}
+
+// Checks the modules without the backend and the module cache.
+func (b *Builder) checkOnly() *BuildResult {
+	b.newContext()
+	b.validateEntry()
+	b.ctx.Cache = NewModuleCache(b.opts, false)
+	res := b.analyze()
+	if b.hasMain() {
+		b.checkMain()
+	}
 	return res
 }
 
@@ -88,6 +148,15 @@ func (b *Builder) run() {
 	b.runCode()
 }
 
+func (b *Builder) newContext() {
+	b.ctx = &BuildContext{
+		Session:        NewSession(),
+		Options:        b.opts,
+		ModuleRegistry: ds.NewSyncMap[string, *File](),
+		EntryModule:    nil,
+	}
+}
+
 func (b *Builder) validateEntry() {
 	inputPath := b.ctx.Options.EntryFilePath
 
@@ -96,25 +165,27 @@ func (b *Builder) validateEntry() {
 		inputPath += ".gold"
 	}
 
-	if err := fs.CheckFileExists(inputPath); err != nil {
-		errors.Throw(errors.InvalidFileError, "input file '%s' not found", inputPath)
+	if !b.opts.HasSource(inputPath) {
+		errors.Throw(errors.FileNotFound, "input file '%s' not found", inputPath)
 	}
 
 	if !fs.IsFileExtension(inputPath, ".gold", false) {
-		errors.Throw(errors.InvalidFileError, "input file '%s' must have a '.gold' extension", inputPath)
+		errors.Throw(errors.InvalidFileExtension, "input file '%s' must have a '.gold' extension", inputPath)
 	}
 
-	if err := fs.CheckFilePermissions(inputPath); err != nil {
-		errors.Throw(errors.InvalidFileError, "input file '%s' does not have read permissions", inputPath)
+	if b.opts.IsOnDisk(inputPath) {
+		if err := fs.CheckFilePermissions(inputPath); err != nil {
+			errors.Throw(errors.FileNotReadable, "input file '%s' does not have read permissions", inputPath)
+		}
 	}
 
 	absPath, err := fs.GetAbsolutePath(inputPath)
 	if err != nil {
-		errors.Throw(errors.InvalidFileError, "input file '%s' does not have a valid path", inputPath)
+		errors.Throw(errors.InvalidModulePath, "input file '%s' does not have a valid path", inputPath)
 	}
 
 	if name := fs.ModulePath2ModuleName(absPath); !fs.IsModuleNameValid(name) {
-		errors.Throw(errors.InvalidFileError, "input file '%s' does not have a valid name", inputPath)
+		errors.Throw(errors.InvalidModulePath, "input file '%s' does not have a valid name", inputPath)
 	}
 
 	b.ctx.Options.EntryFilePath = absPath
@@ -139,10 +210,26 @@ func (b *Builder) checkCacheFolders() {
 	}
 }
 
+// Initializes the backend and the pass manager, which decide if the modules of
+// the previous build can be reused. Whole-program analyses, like dead code
+// elimination, need the AST of every module, so the modules are only reused
+// when none is enabled, as in -O0 builds.
+func (b *Builder) prepareBackend() {
+	target := b.opts.OutputTarget
+	target.Initialize(b.opts.WorkingDir, b.opts.LocalTargetPath)
+	manager := optimizations.NewPassManager(b.opts.OptimizationLevel, b.opts.Passes)
+	b.ctx.PassManager = manager
+
+	_, incremental := target.(backend.Incremental)
+	incremental = incremental && !b.opts.NoCache && !b.opts.InMemory
+	b.ctx.Cache = NewModuleCache(b.opts, incremental && !manager.AnalyzesProgram())
+}
+
 func (b *Builder) loadModules() {
 	l := &loader{
 		ctx:     b.ctx,
 		errors:  ds.NewSyncList[error](),
+		seen:    ds.NewSyncMap[string, bool](),
 		pending: sync.WaitGroup{},
 	}
 	l.discover(b.ctx.Options.EntryFilePath)
@@ -174,18 +261,21 @@ func (b *Builder) buildDependencyGraph() {
 func (b *Builder) buildDependencyGraphLoop(registry map[string]*File, file *File, visited, stack map[string]bool, order []*File) []*File {
 	visited[file.Path] = true
 	stack[file.Path] = true
-	for _, dep := range []string{} { // TODO: file.Imports.Values() {
+	for _, dep := range file.Dependencies() {
 		if !visited[dep] {
 			p := registry[dep]
 			order = b.buildDependencyGraphLoop(registry, p, visited, stack, order)
 
 		} else if stack[dep] {
 			names := []string{}
-			for k := range stack {
-				names = append(names, k)
+			for k, v := range stack {
+				if v {
+					names = append(names, k)
+				}
 			}
+			slices.Sort(names)
 			deps := strings.Join(names, "\n- ")
-			errors.Throw(errors.CircularReferenceError, "cyclic dependency detected importing packages: \n- %s", deps)
+			errors.Throw(errors.CircularDependency, "cyclic dependency detected importing modules: \n- %s", deps)
 		}
 	}
 	stack[file.Path] = false
@@ -193,38 +283,106 @@ func (b *Builder) buildDependencyGraphLoop(registry map[string]*File, file *File
 }
 
 func (b *Builder) buildGlobalScope() {
-	b.ctx.GlobalScope = env.NewScope()
+	b.ctx.GlobalScope = env.NewScope(b.ctx.Session.Bindings)
 	b.ctx.GlobalScope.Types.Set(types.Int.GetSignature(), env.TB(types.Int, nil))
 	b.ctx.GlobalScope.Types.Set(types.Float.GetSignature(), env.TB(types.Float, nil))
 	b.ctx.GlobalScope.Types.Set(types.Bool.GetSignature(), env.TB(types.Bool, nil))
 	b.ctx.GlobalScope.Types.Set(types.String.GetSignature(), env.TB(types.String, nil))
 	b.ctx.GlobalScope.Types.Set(types.Void.GetSignature(), env.TB(types.Void, nil))
+	b.ctx.StdScope = builtins.NewScope(b.ctx.GlobalScope)
+	if host, ok := b.opts.OutputTarget.(backend.Host); ok {
+		host.DeclareHost(b.ctx.GlobalScope)
+	}
+}
+
+// Reuses the modules unchanged since the previous build, restoring their
+// scopes from the cache entries. Modules whose dependencies changed or whose
+// output is missing are compiled from the source.
+func (b *Builder) restoreCachedModules() {
+	cache := b.ctx.Cache
+	if !cache.Enabled {
+		cache.Misses = len(b.ctx.DependencyOrder)
+		return
+	}
+
+	target := b.opts.OutputTarget.(backend.Incremental)
+	registry := b.ctx.ModuleRegistry.Items()
+	for _, mod := range b.ctx.DependencyOrder {
+		if !mod.Cached.Has() {
+			continue
+		}
+
+		// dependencies compiled in this build may have changed the types seen
+		// by the module, even when their sources did not change
+		entry := mod.Cached.Unwrap()
+		valid := target.HasOutput(mod.Path)
+		for path, hash := range entry.Dependencies {
+			dep, ok := registry[path]
+			valid = valid && ok && dep.Hash == hash && dep.Cached.Has()
+		}
+		if valid {
+			scope, ok := restoreScope(entry, b.ctx.GlobalScope)
+			mod.cached = types.NewModule(nil, mod.Path, scope)
+			types.AssignIds(mod.cached, b.ctx.Session.Types)
+			valid = ok
+		}
+		if !valid {
+			b.compileCachedModule(mod)
+		}
+	}
+
+	for _, mod := range b.ctx.DependencyOrder {
+		if mod.Cached.Has() {
+			cache.Hits++
+		} else {
+			cache.Misses++
+		}
+	}
+}
+
+// Discards the cache entry of the module, parsing it from the source.
+func (b *Builder) compileCachedModule(mod *File) {
+	bytes, err := b.ctx.Options.ReadSource(mod.Path)
+	if err != nil {
+		errors.Throw(errors.FileNotReadable, "could not read module '%s', reason: %v", mod.Path, err)
+	}
+
+	mod.Cached = safe.None[*CacheEntry]()
+	mod.cached = nil
+	mod.Hash = HashSource(bytes)
+	if err := parseModule(b.ctx, mod, bytes); err != nil {
+		errors.Rethrow(err)
+	}
 }
 
 func (b *Builder) semanticAnalysis() {
 	checker := semantic.NewChecker()
-	mods := b.ctx.DependencyOrder
-	// create type instances for all modules
+	mods := []*File{}
+	for _, mod := range b.ctx.DependencyOrder {
+		if !mod.Cached.Has() {
+			mods = append(mods, mod)
+		}
+	}
+
+	// create type instances for the modules compiled in this build
 	for _, mod := range mods {
 		root := mod.Root.Unwrap()
-		scope := b.ctx.GlobalScope.New()
+		b.ctx.Session.Number(root)
+		parent := b.ctx.GlobalScope
+		if fs.IsStdPath(mod.Path) {
+			parent = b.ctx.StdScope
+		}
+		scope := parent.New()
 		scope.IsModule = true
 		mod.Root.Unwrap().SetType(types.NewModule(root, mod.Path, scope))
 	}
 
-	// attach type instances to the module scopes
+	// bind the imported modules in the module scopes, by their aliases
+	registry := b.ctx.ModuleRegistry.Items()
 	for _, mod := range mods {
-		root := mod.Root.Unwrap()
-		modType := root.GetType().Unwrap().(*types.Module)
-
-		for _, other := range mods {
-			if mod == other {
-				continue
-			}
-
-			alias := fs.ModulePath2ModuleName(other.Path)
-			otherRoot := other.Root.Unwrap()
-			modType.Scope.Values.Set(alias, env.VB(otherRoot, otherRoot.GetType().Unwrap()))
+		modType := mod.Type()
+		for _, imp := range mod.Imports {
+			modType.Scope.Values.Set(imp.Alias, env.VB(nil, registry[imp.Path].Type()))
 		}
 	}
 
@@ -235,49 +393,119 @@ func (b *Builder) semanticAnalysis() {
 	}
 
 	// resolve everything
-	for _, mod := range b.ctx.DependencyOrder {
+	for _, mod := range mods {
 		root := mod.Root.Unwrap()
 		_, err := checker.Check(root)
+		for _, w := range checker.Warnings {
+			if !slices.Contains(b.ctx.Options.Suppress, w.Code) {
+				b.ctx.Options.OnWarning.Emit(w)
+			}
+		}
+		checker.Warnings = checker.Warnings[:0]
 		if err != nil {
 			errors.Rethrow(err)
 		}
+		b.ctx.Session.Number(root)
+		if b.ctx.Cache.Enabled {
+			// summarized before the optimizations, which may remove declarations
+			mod.bindings = summarize(root)
+		}
 		b.ctx.Options.OnTypeCheckReady.Emit(mod, root, root.GetType().Unwrap().(*types.Module).Scope)
 	}
 }
 
+func (b *Builder) hasMain() bool {
+	return b.ctx.EntryModule.Scope().Values.Get("main", nil) != nil
+}
+
 func (b *Builder) checkMain() {
 	main := b.ctx.EntryModule.Scope().Values.Get("main", nil)
 	if main == nil {
-		errors.Throw(errors.InvalidEntryFile, "entry module '%s' does not contain a 'main' function", b.ctx.EntryModule.Path)
+		errors.Throw(errors.MissingMain, "entry module '%s' does not contain a 'main' function", b.ctx.EntryModule.Path)
 	}
 
 	if !types.NoopFn.IsCompatible(main.Type) {
-		errors.Throw(errors.InvalidEntryFile, "entry module '%s' 'main' function has an invalid signature", b.ctx.EntryModule.Path)
+		if fn, ok := main.DefinitionNode.(*ast.FnDecl); ok && fn.Name.Has() {
+			errors.ThrowAtNode(fn.Name.Unwrap(), errors.InvalidMainSignature, "entry module '%s' 'main' function has an invalid signature", b.ctx.EntryModule.Path)
+		}
+		errors.Throw(errors.InvalidMainSignature, "entry module '%s' 'main' function has an invalid signature", b.ctx.EntryModule.Path)
 	}
 }
 
 func (b *Builder) applyOptimizations() {
-	pipeline := optimizations.NewPipeline(
-	// optimizations.NewAddReturnToFunctions(),
-	)
+	manager := b.ctx.PassManager
+
+	mods := []*File{}
+	program := &optimizations.Program{Entry: b.ctx.EntryModule.Root.Or(nil)}
 	for _, mod := range b.ctx.DependencyOrder {
-		mod.Root = safe.Some(pipeline.Run(mod.Root.Unwrap()).(*ast.Module))
+		if !mod.Cached.Has() {
+			mods = append(mods, mod)
+			program.Modules = append(program.Modules, mod.Root.Unwrap())
+		}
+	}
+
+	manager.OnReport = func(r *optimizations.PassReport) {
+		i := slices.Index(program.Modules, r.Module)
+		b.ctx.Options.OnPassReady.Emit(mods[i], r)
+	}
+	manager.Run(program)
+
+	for i, mod := range mods {
+		mod.Root = safe.Some(program.Modules[i])
+		b.ctx.Session.Number(program.Modules[i])
 		b.ctx.Options.OnOptimizationReady.Emit(mod, mod.Root.Unwrap())
 	}
 }
 
+// Lowers the modules to the IR. The backends generate code from the AST, so
+// the IR is only built when something subscribes to it.
+func (b *Builder) lowerToIR() {
+	if !b.ctx.Options.OnIRReady.HasSubscribers() {
+		return
+	}
+	for _, mod := range b.ctx.DependencyOrder {
+		if mod.Cached.Has() {
+			continue
+		}
+		res, err := ir.Lower(mod.Name, mod.Path, mod.Root.Unwrap(), b.ctx.GlobalScope)
+		if err != nil {
+			errors.Rethrow(err)
+		}
+		mod.IR = safe.Some(res)
+		b.ctx.Options.OnIRReady.Emit(mod, res)
+	}
+}
+
 func (b *Builder) generateCode() {
 	backend := b.opts.OutputTarget
-	backend.Initialize(b.opts.LocalTargetPath)
 
 	backend.BeforeCodeGeneration()
 	for _, mod := range b.ctx.DependencyOrder {
-		backend.GenerateCode(mod.Path, mod.Root.Unwrap(), mod == b.ctx.EntryModule)
+		backend.GenerateCode(mod.Path, mod.Root.Or(nil), mod == b.ctx.EntryModule)
 	}
 	backend.AfterCodeGeneration()
 	backend.Finalize()
 }
 
+// Stores the modules compiled in this build, so the next build can reuse them.
+func (b *Builder) storeCache() {
+	if !b.ctx.Cache.Enabled {
+		return
+	}
+
+	registry := b.ctx.ModuleRegistry.Items()
+	for _, mod := range b.ctx.DependencyOrder {
+		if mod.Cached.Has() {
+			continue
+		}
+		deps := []*File{}
+		for _, dep := range mod.Dependencies() {
+			deps = append(deps, registry[dep])
+		}
+		b.ctx.Cache.Store(mod, deps)
+	}
+}
+
 func (b *Builder) generateOutput() {
 	backend := b.opts.OutputTarget
 	backend.Build(b.opts.OutputFilePath)
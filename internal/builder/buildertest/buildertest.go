@@ -0,0 +1,39 @@
+// Package buildertest creates the projects and build options of the tests,
+// so they never read or write the caches and targets of the user.
+package buildertest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/stretchr/testify/require"
+)
+
+// Writes the files to a new project, by their slash-separated paths relative
+// to it, returning the directory of the project.
+func Project(t testing.TB, files map[string]string) string {
+	dir := t.TempDir()
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(source), 0644))
+	}
+	return dir
+}
+
+// Returns the options building the entry in its directory. The output, the
+// caches and the targets are written to a temporary directory, outside of the
+// project.
+func Options(t testing.TB, entry string) *builder.BuildOptions {
+	dir := t.TempDir()
+	opts := builder.NewBuildOptions(entry)
+	opts.WorkingDir = filepath.Dir(entry)
+	opts.OutputFilePath = filepath.Join(dir, "main")
+	opts.LocalCachePath = filepath.Join(dir, "cache")
+	opts.LocalTargetPath = filepath.Join(dir, "target")
+	opts.GlobalCachePath = filepath.Join(dir, "global", "cache")
+	opts.GlobalTargetPath = filepath.Join(dir, "global", "target")
+	return opts
+}
@@ -0,0 +1,215 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/renatopp/golden"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
+
+// CacheEntry is the information stored for a module after a successful build.
+// A module is reused by the next build when its source, the compiler version,
+// the options affecting the generated code and the sources of its
+// dependencies are the same.
+type CacheEntry struct {
+	Version      string            `json:"version"`
+	Options      string            `json:"options"`
+	Path         string            `json:"path"`
+	SourceHash   string            `json:"source_hash"`
+	Dependencies map[string]string `json:"dependencies"` // Source hash of each dependency, by path
+	Bindings     []*CachedBinding  `json:"bindings"`     // Module declarations, as seen by the dependents
+}
+
+type CachedBinding struct {
+	Name string      `json:"name"`
+	Type *CachedType `json:"type"`
+}
+
+// CachedType is the structural representation of a type in the cache.
+type CachedType struct {
+	Kind   string        `json:"kind"` // primitive, void or function
+	Name   string        `json:"name,omitempty"`
+	Params []*CachedType `json:"params,omitempty"`
+	Return *CachedType   `json:"return,omitempty"`
+}
+
+// ModuleCache stores one entry per module in the local cache directory, or in
+// the global one for modules outside of the project.
+type ModuleCache struct {
+	Enabled bool
+	Options string // Fingerprint of the options affecting the generated code
+	Hits    int
+	Misses  int
+	project string
+	local   string
+	global  string
+}
+
+func NewModuleCache(opts *BuildOptions, enabled bool) *ModuleCache {
+	return &ModuleCache{
+		Enabled: enabled,
+		Options: fmt.Sprintf("%T;O%d;%s", opts.OutputTarget, opts.OptimizationLevel, strings.Join(opts.Passes, ",")),
+		project: opts.WorkingDir,
+		local:   opts.LocalCachePath,
+		global:  opts.GlobalCachePath,
+	}
+}
+
+// Returns the entry of the module if it was built from the same source, with
+// the same compiler and options. Dependencies are checked after loading.
+func (c *ModuleCache) Lookup(file *File) (*CacheEntry, bool) {
+	if !c.Enabled {
+		return nil, false
+	}
+
+	bytes, err := os.ReadFile(c.entryPath(file.Path))
+	if err != nil {
+		return nil, false
+	}
+	entry := &CacheEntry{}
+	if err := json.Unmarshal(bytes, entry); err != nil {
+		return nil, false
+	}
+
+	ok := entry.Version == golden.Version &&
+		entry.Options == c.Options &&
+		entry.Path == file.Path &&
+		entry.SourceHash == file.Hash
+	return entry, ok
+}
+
+// Writes the entry of a module that was compiled in this build.
+func (c *ModuleCache) Store(file *File, deps []*File) {
+	entry := &CacheEntry{
+		Version:      golden.Version,
+		Options:      c.Options,
+		Path:         file.Path,
+		SourceHash:   file.Hash,
+		Dependencies: map[string]string{},
+		Bindings:     file.bindings,
+	}
+	for _, dep := range deps {
+		entry.Dependencies[dep.Path] = dep.Hash
+	}
+
+	bytes, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		errors.Throw(errors.InternalError, "could not encode cache entry of '%s': %v", file.Path, err)
+	}
+	if err := os.WriteFile(c.entryPath(file.Path), bytes, 0644); err != nil {
+		errors.Throw(errors.InternalError, "could not write cache entry of '%s': %v", file.Path, err)
+	}
+}
+
+func (c *ModuleCache) entryPath(modulePath string) string {
+	dir := c.global
+	if fs.IsPathInside(c.project, modulePath) {
+		dir = c.local
+	}
+	return filepath.Join(dir, HashSource([]byte(modulePath))+".json")
+}
+
+//
+//
+//
+
+func HashSource(bytes []byte) string {
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Collects the module declarations with their resolved types. Modules are
+// summarized once checked, since dependents may use the declarations that the
+// optimizations remove.
+func summarize(root *ast.Module) []*CachedBinding {
+	scope := root.GetType().Unwrap().(*types.Module).Scope
+	bindings := []*CachedBinding{}
+	for _, expr := range root.Exprs {
+		name := ""
+		switch n := expr.(type) {
+		case *ast.VarDecl:
+			name = n.Name.Value
+		case *ast.FnDecl:
+			name = n.Name.Unwrap().Value
+		case *ast.ExternFnDecl:
+			name = n.Name.Value
+		default:
+			continue
+		}
+
+		bind := scope.Values.GetLocal(name, nil)
+		if bind == nil || bind.Type == nil {
+			continue
+		}
+		bindings = append(bindings, &CachedBinding{Name: name, Type: encodeType(bind.Type)})
+	}
+	return bindings
+}
+
+func encodeType(tp ast.Type) *CachedType {
+	switch tp := tp.(type) {
+	case *types.Primitive:
+		return &CachedType{Kind: "primitive", Name: tp.Name}
+	case *types.Unit:
+		return &CachedType{Kind: "void"}
+	case *types.Function:
+		params := []*CachedType{}
+		for _, p := range tp.Params {
+			params = append(params, encodeType(p))
+		}
+		return &CachedType{Kind: "function", Params: params, Return: encodeType(tp.Return)}
+	}
+	errors.Throw(errors.InternalError, "type %s cannot be cached", tp.GetSignature())
+	return nil
+}
+
+// Resolves a cached type against the global scope, returning nil if the type
+// is not known anymore.
+func decodeType(tp *CachedType, global *env.Scope) ast.Type {
+	switch tp.Kind {
+	case "primitive":
+		if bind := global.Types.Get(tp.Name, nil); bind != nil {
+			return bind.Type
+		}
+	case "void":
+		return types.Void
+	case "function":
+		params := []ast.Type{}
+		for _, p := range tp.Params {
+			param := decodeType(p, global)
+			if param == nil {
+				return nil
+			}
+			params = append(params, param)
+		}
+		if ret := decodeType(tp.Return, global); ret != nil {
+			return types.NewFunction(nil, params, ret)
+		}
+	}
+	return nil
+}
+
+// Rebuilds the scope of a cached module from its bindings, returning false if
+// some type cannot be resolved.
+func restoreScope(entry *CacheEntry, global *env.Scope) (*env.Scope, bool) {
+	scope := global.New()
+	scope.IsModule = true
+	for _, b := range entry.Bindings {
+		tp := decodeType(b.Type, global)
+		if tp == nil {
+			return nil, false
+		}
+		scope.Values.Set(b.Name, env.VB(nil, tp))
+	}
+	return scope, true
+}
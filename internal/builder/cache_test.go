@@ -0,0 +1,127 @@
+package builder_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/backend/javascript"
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cached = map[string]string{
+	"main.gold":     "import \"lib/math\"\nimport \"lib/text\"\nfn main() { math.half(text.size) }\n",
+	"lib/math.gold": "fn half(a Int) Int { a / 2 }\nfn third(a Int) Int { a / 3 }\n",
+	"lib/text.gold": "let size = 4\n",
+}
+
+// Creates the project, returning the options shared by its builds. Without
+// optimizations, no pass analyzes the whole program and the cache is used.
+func cachedProject(t *testing.T) (string, *builder.BuildOptions) {
+	dir := buildertest.Project(t, cached)
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	opts.OptimizationLevel = optimizations.LevelNone
+	return dir, opts
+}
+
+// Builds the project with the JavaScript backend, which reuses the modules of
+// the previous build.
+func rebuild(t *testing.T, opts *builder.BuildOptions) *builder.BuildResult {
+	opts.OutputTarget = javascript.NewBackend()
+	res, err := builder.NewBuilder(opts).Build()
+	require.NoError(t, err)
+	return res
+}
+
+func write(t *testing.T, dir, name, source string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(source), 0644))
+}
+
+func TestCacheHits(t *testing.T) {
+	_, opts := cachedProject(t)
+
+	first := rebuild(t, opts)
+	assert.Zero(t, first.CacheHits)
+	assert.Positive(t, first.CacheMisses)
+
+	second := rebuild(t, opts)
+	assert.Equal(t, first.CacheMisses, second.CacheHits)
+	assert.Zero(t, second.CacheMisses)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	dir, opts := cachedProject(t)
+	modules := rebuild(t, opts).CacheMisses
+
+	// the dependents of a changed module are compiled again
+	write(t, dir, "lib/text.gold", "let size = 6\n")
+	res := rebuild(t, opts)
+	assert.Equal(t, 2, res.CacheMisses)
+	assert.Equal(t, modules-2, res.CacheHits)
+
+	// the other modules are reused when the entry changes
+	write(t, dir, "main.gold", "import \"lib/math\"\nfn main() { math.third(3) }\n")
+	res = rebuild(t, opts)
+	assert.Equal(t, 1, res.CacheMisses)
+	assert.Equal(t, modules-2, res.CacheHits, "lib/text is not imported anymore")
+
+	// outputs removed since the previous build are generated again
+	require.NoError(t, os.RemoveAll(filepath.Join(opts.LocalTargetPath, "javascript")))
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+}
+
+func TestCacheFingerprint(t *testing.T) {
+	_, opts := cachedProject(t)
+	modules := rebuild(t, opts).CacheMisses
+
+	// options affecting the generated code do not reuse the modules
+	opts.Passes = []string{"const-fold"}
+	res := rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+	assert.Equal(t, modules, res.CacheMisses)
+
+	res = rebuild(t, opts)
+	assert.Equal(t, modules, res.CacheHits)
+
+	// whole-program passes, selected or enabled by the level, compile every
+	// module
+	opts.Passes = []string{"dce"}
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+
+	opts.Passes = nil
+	opts.OptimizationLevel = optimizations.LevelDefault
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+
+	opts.NoCache = true
+	opts.OptimizationLevel = optimizations.LevelNone
+	res = rebuild(t, opts)
+	assert.Zero(t, res.CacheHits)
+}
+
+func TestCacheDeadCode(t *testing.T) {
+	_, opts := cachedProject(t)
+	opts.OptimizationLevel = optimizations.LevelDefault
+	rebuild(t, opts)
+
+	// the unused functions are still removed and reported when the cache is
+	// enabled, as with --report-dead-code
+	remarks := []string{}
+	opts.OnPassReady.Subscribe(func(file *builder.File, r *optimizations.PassReport) {
+		if r.Pass.Name == "dce" {
+			remarks = append(remarks, r.Remarks...)
+		}
+	})
+	rebuild(t, opts)
+	assert.Contains(t, remarks, "2:1: removed unused function 'third'")
+}
@@ -0,0 +1,169 @@
+package builder_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Checks the source in its own project, which must not be changed.
+func check(t *testing.T, source string) error {
+	return checkModules(t, map[string]string{"main.gold": source})
+}
+
+// Checks the modules, given by their paths relative to the project, with
+// `main.gold` as entry.
+func checkModules(t *testing.T, modules map[string]string) error {
+	dir := buildertest.Project(t, modules)
+	before, _ := os.ReadDir(dir)
+
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	_, err := builder.NewBuilder(opts).Check()
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, len(before), "check must only read the project")
+	assert.NoDirExists(t, opts.LocalCachePath, "check must not write the cache")
+	assert.NoDirExists(t, opts.LocalTargetPath, "check must not write the target")
+	return err
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, checkModules(t, project))
+}
+
+func TestCheckLibrary(t *testing.T) {
+	assert.NoError(t, check(t, "fn twice(a Int) Int { a * 2 }\n"))
+}
+
+func TestCheckErrors(t *testing.T) {
+	err := check(t, "fn main() Int { 1 }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.InvalidMainSignature, errors.ToGoldenError(err).Code)
+
+	err = check(t, "fn twice(a Int) Int { a * true }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.TypeMismatch, errors.ToGoldenError(err).Code)
+}
+
+func TestCheckReturns(t *testing.T) {
+	// the value of the function is its last expression, unless it returns
+	// before, which makes the following code unreachable
+	assert.NoError(t, check(t, "fn f(a Int) Int {\n  a\n}\n"))
+	assert.NoError(t, check(t, "fn f(a Int) Int {\n  return a\n  \"oops\"\n}\n"))
+
+	err := check(t, "fn f(a Int) Int {\n  \"oops\"\n}\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.TypeMismatch, errors.ToGoldenError(err).Code)
+
+	err = check(t, "fn f(a Int) Int {\n  let b = a\n}\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.MissingReturn, errors.ToGoldenError(err).Code)
+}
+
+func TestCheckImports(t *testing.T) {
+	lib := "let value = 2\nfn add(a Int, b Int) Int { a + b }\nfn _hidden() Int { 1 }\n"
+
+	err := checkModules(t, map[string]string{
+		"main.gold":     "import \"lib/math\"\nimport \"@/lib/math\" as m\nfn add() Int { math.add(m.value, 1) }\nfn main() { add() }\n",
+		"lib/math.gold": lib,
+	})
+	assert.NoError(t, err)
+
+	err = checkModules(t, map[string]string{
+		"main.gold":     "import \"lib/math\"\nfn main() { math._hidden() }\n",
+		"lib/math.gold": lib,
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.NameNotFound, errors.ToGoldenError(err).Code)
+
+	err = checkModules(t, map[string]string{
+		"main.gold":     "import \"lib/math\"\nfn main() { let f = math }\n",
+		"lib/math.gold": lib,
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.TypeMismatch, errors.ToGoldenError(err).Code)
+
+	err = checkModules(t, map[string]string{"main.gold": "import \"missing\"\nfn main() {}\n"})
+	require.Error(t, err)
+	assert.Equal(t, errors.FileNotFound, errors.ToGoldenError(err).Code)
+
+	err = checkModules(t, map[string]string{
+		"main.gold": "import \"a\"\nfn main() {}\n",
+		"a.gold":    "import \"main\"\n",
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.CircularDependency, errors.ToGoldenError(err).Code)
+}
+
+func TestCheckStd(t *testing.T) {
+	err := check(t, "import \"@std/strings\"\nfn main() { println(strings.upper(\"hi\")) }\n")
+	assert.NoError(t, err)
+
+	// declarations of the module take precedence over the prelude
+	err = check(t, "fn print(a Int) Int { a }\nfn main() { print(1) }\n")
+	assert.NoError(t, err)
+
+	err = check(t, "fn main() { __print(\"hi\") }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.NameNotFound, errors.ToGoldenError(err).Code)
+
+	err = check(t, "import \"@std/missing\"\nfn main() {}\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.FileNotFound, errors.ToGoldenError(err).Code)
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	for _, source := range []string{
+		"fn value() Int { 1 }\nfn value() Int { 2 }\nfn main() {}\n",
+		"let value = 1\nlet value = 2\nfn main() {}\n",
+		"let value = 1\nfn value() Int { 2 }\nfn main() {}\n",
+		"fn main() {\n  let value = 1\n  let value = 2\n}\n",
+	} {
+		err := check(t, source)
+		require.Error(t, err, source)
+		e := errors.ToGoldenError(err)
+		assert.Equal(t, errors.NameAlreadyDefined, e.Code, source)
+		require.Len(t, e.Labels, 1, source)
+		assert.Equal(t, "'value' first defined here", e.Labels[0].Msg)
+		assert.Less(t, e.Labels[0].Loc.FromLine, e.Loc.Unwrap().FromLine, source)
+	}
+
+	// recursive functions are declared once
+	assert.NoError(t, check(t, "fn count(n Int) Int { count(n) }\nfn main() {}\n"))
+	assert.NoError(t, check(t, "fn f(n Int) Int {\n  fn g(x Int) Int { g(x + n) }\n  g(1)\n}\nfn main() {}\n"))
+}
+
+func TestCheckReservedNames(t *testing.T) {
+	err := check(t, "fn __twice(a Int) Int { a * 2 }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.ReservedName, errors.ToGoldenError(err).Code)
+
+	err = check(t, "fn twice(__a Int) Int { __a * 2 }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.ReservedName, errors.ToGoldenError(err).Code)
+
+	err = check(t, "import \"@std/math\" as __math\nfn main() {}\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.ReservedName, errors.ToGoldenError(err).Code)
+}
+
+func TestCheckExterns(t *testing.T) {
+	err := check(t, "extern fn upper(s String) String = \"go:strings.ToUpper\", \"js:node:module#upper\"\nfn main() { upper(\"a\") }\n")
+	assert.NoError(t, err)
+
+	for _, binding := range []string{`"strings.ToUpper"`, `"rust:upper"`, `"go:strings.toUpper"`, `"go:strings.ToUpper", "go:strings.ToLower"`} {
+		err = check(t, "extern fn upper(s String) String = "+binding+"\nfn main() {}\n")
+		require.Error(t, err, binding)
+		assert.Equal(t, errors.InvalidExternBinding, errors.ToGoldenError(err).Code, binding)
+	}
+
+	err = check(t, "extern fn upper(s String) String = \"go:strings.ToUpper\"\nfn main() { upper(1) }\n")
+	require.Error(t, err)
+	assert.Equal(t, errors.TypeMismatch, errors.ToGoldenError(err).Code)
+}
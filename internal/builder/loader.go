@@ -1,23 +1,33 @@
 package builder
 
 import (
-	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
 	"github.com/renatopp/golden/internal/compiler/syntax"
+	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/helpers/ds"
 	"github.com/renatopp/golden/internal/helpers/errors"
 	"github.com/renatopp/golden/internal/helpers/fs"
+	"github.com/renatopp/golden/internal/helpers/naming"
 	"github.com/renatopp/golden/internal/helpers/safe"
 )
 
 type loader struct {
 	ctx     *BuildContext
 	errors  *ds.SyncList[error]
+	seen    *ds.SyncMap[string, bool]
 	pending sync.WaitGroup
 }
 
+// Loads the module in background, unless it was already discovered.
 func (l *loader) discover(modulePath string) {
+	if ok := l.seen.SetFirst(modulePath, true); !ok {
+		return
+	}
 	l.pending.Add(1)
 	go l.loadModule(modulePath)
 }
@@ -33,158 +43,107 @@ func (l *loader) loadModule(modulePath string) {
 	)
 
 	// Read the bytes
-	bytes, err := os.ReadFile(modulePath)
+	bytes, err := l.ctx.Options.ReadSource(modulePath)
 	if err != nil {
 		l.errors.Add(
-			errors.NewError(errors.InvalidFileError, "could not read module '%s', reason: %v", modulePath, err),
+			errors.NewError(errors.FileNotReadable, "could not read module '%s', reason: %v", modulePath, err),
 		)
 		return
 	}
-
-	// Convert bytes to tokens
-	lexer := syntax.NewLexer(modulePath, bytes)
-	tokens, err := lexer.Lex()
-	if err != nil {
-		l.errors.Add(err)
+	file.Hash = HashSource(bytes)
+
+	// Unchanged modules skip the front end, their dependencies are discovered
+	// from the cache entry
+	if entry, ok := l.ctx.Cache.Lookup(file); ok {
+		file.Cached = safe.Some(entry)
+		l.ctx.ModuleRegistry.Set(modulePath, file)
+		for _, dep := range file.Dependencies() {
+			l.discover(dep)
+		}
 		return
 	}
-	l.ctx.Options.OnTokensReady.Emit(file, tokens)
 
-	// Convert tokens to AST
-	parser := syntax.NewParser(tokens)
-	root, err := parser.Parse()
-	if err != nil {
+	if err := parseModule(l.ctx, file, bytes); err != nil {
 		l.errors.Add(err)
 		return
 	}
-	file.Root = safe.Some(root)
-	l.ctx.Options.OnAstReady.Emit(file, root)
 
 	// Add the module to the package
 	l.ctx.ModuleRegistry.Set(modulePath, file)
 
-	// // Discover imports
-	// for _, a := range root.Imports {
-	// 	path := fs.ImportName2ModulePath(a.Path.Literal)
-	// 	alias := fs.ModulePath2ModuleName(path)
-	// 	if a.Alias.Has() {
-	// 		alias = a.Alias.Unwrap().Literal
-	// 	}
-
-	// 	module.Imports = append(module.Imports, &ModuleImport{
-	// 		Path:  path,
-	// 		Alias: alias,
-	// 	})
+	for _, dep := range file.Dependencies() {
+		l.discover(dep)
+	}
+}
 
-	// 	packagePath := fs.ModulePath2PackagePath(path)
-	// 	module.Package.Imports.AddUnique(packagePath)
+// Converts the source of the module to tokens and then to the AST.
+func parseModule(ctx *BuildContext, file *File, bytes []byte) error {
+	lexer := syntax.NewLexer(file.Path, bytes)
+	tokens, err := lexer.Lex()
+	if err != nil {
+		return err
+	}
+	ctx.Options.OnTokensReady.Emit(file, tokens)
 
-	// 	if fs.CheckFolderExists(packagePath) != nil {
-	// 		l.errors.Add(errors.NewError(errors.InvalidFolderError, "could not find package '%s'", packagePath).WithNode(a.Path))
-	// 	}
+	parser := syntax.NewParser(tokens)
+	root, err := parser.Parse()
+	if err != nil {
+		return err
+	}
+	file.Root = safe.Some(root)
+	ctx.Options.OnAstReady.Emit(file, root)
+	return resolveImports(ctx, file, root)
+}
 
-	// 	if fs.CheckFileExists(path) != nil {
-	// 		l.errors.Add(errors.NewError(errors.InvalidFileError, "could not find module '%s'", path).WithNode(a.Path))
-	// 	}
+// Converts the imports of the module to the absolute paths of the imported
+// modules. Paths starting with `@/` are relative to the project, the ones
+// starting with `@std/` are modules of the standard library and the others
+// are relative to the directory of the importing module. The `.gold`
+// extension is optional.
+func resolveImports(ctx *BuildContext, file *File, root *ast.Module) error {
+	file.Imports = []*ModuleImport{}
+	aliases := map[string]bool{}
+	for _, imp := range root.Imports {
+		name := imp.Path.Literal
+		path := ""
+		if rest, ok := strings.CutPrefix(name, "@/"); ok {
+			path = filepath.Join(ctx.Options.WorkingDir, filepath.FromSlash(rest))
+		} else if rest, ok := strings.CutPrefix(name, "@std/"); ok {
+			path = filepath.Join(fs.StdDir, filepath.FromSlash(rest))
+		} else {
+			path = filepath.Join(fs.ModulePath2PackagePath(file.Path), filepath.FromSlash(name))
+		}
+		if fs.GetFileExtension(path) == "" {
+			path += ".gold"
+		}
+
+		alias := fs.ModulePath2ModuleName(path)
+		imp.Alias.If(func(t *token.Token) { alias = t.Literal })
+
+		switch {
+		case !fs.IsFileExtension(path, ".gold", false):
+			return errors.NewError(errors.InvalidFileExtension, "imported module '%s' must have a '.gold' extension", name).WithToken(imp.Path)
+		case !ctx.Options.HasSource(path):
+			return errors.NewError(errors.FileNotFound, "could not find module '%s'", name).WithToken(imp.Path)
+		case path == file.Path:
+			return errors.NewError(errors.CircularDependency, "module cannot import itself").WithToken(imp.Path)
+		case naming.IsReservedName(alias):
+			return errors.NewError(errors.ReservedName, "module alias '%s' is reserved to the compiler", alias).WithToken(imp.Path).
+				WithHelp("import the module with another name with `import \"%s\" as <name>`", name)
+		case !fs.IsModuleNameValid(alias):
+			return errors.NewError(errors.InvalidModulePath, "'%s' is not a valid module alias", alias).WithToken(imp.Path).
+				WithHelp("give the module a valid name with `import \"%s\" as <name>`", name)
+		case aliases[alias]:
+			return errors.NewError(errors.NameAlreadyDefined, "module '%s' already imported", alias).WithToken(imp.Path).
+				WithHelp("import the module with another name with `import \"%s\" as <name>`", name)
+		}
+		aliases[alias] = true
+		file.Imports = append(file.Imports, &ModuleImport{Path: path, Alias: alias})
+	}
 
-	// 	l.discover(path)
-	// }
+	if !fs.IsStdPath(file.Path) {
+		prelude := filepath.Join(fs.StdDir, "prelude.gold")
+		file.Imports = append(file.Imports, &ModuleImport{Path: prelude, Alias: builtins.PreludeAlias})
+	}
+	return nil
 }
-
-// func (l *loader) discover(modulePath string) {
-// 	if l.errors.Len() > 0 {
-// 		return
-// 	}
-
-// 	packagePath := fs.ModulePath2PackagePath(modulePath)
-// 	if ok := l.ctx.PackageRegistry.SetFirst(packagePath, nil); !ok {
-// 		return
-// 	}
-
-// 	l.pending.Add(1)
-// 	go l.loadPackage(packagePath)
-// }
-
-// func (l *loader) loadPackage(packagePath string) {
-// 	defer l.pending.Done()
-
-// 	pkg := NewPackage(
-// 		fs.PackagePath2PackageName(packagePath),
-// 		packagePath,
-// 	)
-// 	l.ctx.PackageRegistry.Set(packagePath, pkg)
-// 	files := fs.DiscoverModules(packagePath)
-// 	for _, modulePath := range files {
-// 		l.pending.Add(1)
-// 		go l.loadModule(pkg, modulePath)
-// 	}
-// }
-
-// func (l *loader) loadModule(pkg *Package, modulePath string) {
-// 	defer l.pending.Done()
-
-// 	// Create the module
-// 	module := NewFile(
-// 		fs.ModulePath2ModuleName(modulePath),
-// 		modulePath,
-// 		fs.ModulePath2ModuleFileName(modulePath),
-// 		pkg,
-// 	)
-
-// 	// Read the bytes
-// 	bytes, err := os.ReadFile(modulePath)
-// 	if err != nil {
-// 		l.errors.Add(
-// 			errors.NewError(errors.InvalidFileError, "could not read module '%s', reason: %v", modulePath, err),
-// 		)
-// 		return
-// 	}
-
-// 	// Convert bytes to tokens
-// 	tokens, err := syntax.Lex(bytes, modulePath)
-// 	if err != nil {
-// 		l.errors.Add(err)
-// 		return
-// 	}
-// 	l.ctx.Options.OnTokensReady.Emit(module, tokens)
-
-// 	// Convert tokens to AST
-// 	root, err := syntax.Parse(tokens, modulePath)
-// 	if err != nil {
-// 		l.errors.Add(err)
-// 		return
-// 	}
-// 	module.Root = root
-// 	l.ctx.Options.OnAstReady.Emit(module, root)
-
-// 	// Add the module to the package
-// 	pkg.Modules.Add(module)
-// 	l.ctx.ModuleRegistry.Set(modulePath, module)
-
-// 	// Discover imports
-// 	for _, a := range root.Imports {
-// 		path := fs.ImportName2ModulePath(a.Path.Literal)
-// 		alias := fs.ModulePath2ModuleName(path)
-// 		if a.Alias.Has() {
-// 			alias = a.Alias.Unwrap().Literal
-// 		}
-
-// 		module.Imports = append(module.Imports, &ModuleImport{
-// 			Path:  path,
-// 			Alias: alias,
-// 		})
-
-// 		packagePath := fs.ModulePath2PackagePath(path)
-// 		module.Package.Imports.AddUnique(packagePath)
-
-// 		if fs.CheckFolderExists(packagePath) != nil {
-// 			l.errors.Add(errors.NewError(errors.InvalidFolderError, "could not find package '%s'", packagePath).WithNode(a.Path))
-// 		}
-
-// 		if fs.CheckFileExists(path) != nil {
-// 			l.errors.Add(errors.NewError(errors.InvalidFileError, "could not find module '%s'", path).WithNode(a.Path))
-// 		}
-
-// 		l.discover(path)
-// 	}
-// }
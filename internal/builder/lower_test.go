@@ -0,0 +1,51 @@
+package builder_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Lowers the project, returning the files and whether they have an IR.
+func lowered(t *testing.T, subscribe bool) map[string]bool {
+	dir := buildertest.Project(t, project)
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	opts.NoCache = true
+
+	files := []*builder.File{}
+	opts.OnOptimizationReady.Subscribe(func(f *builder.File, _ *ast.Module) {
+		files = append(files, f)
+	})
+	emitted := 0
+	if subscribe {
+		opts.OnIRReady.Subscribe(func(*builder.File, *ir.Module) { emitted++ })
+	}
+
+	_, err := builder.NewBuilder(opts).Lower()
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	res := map[string]bool{}
+	for _, f := range files {
+		res[f.Name] = f.IR.Has()
+	}
+	if subscribe {
+		assert.Equal(t, len(files), emitted)
+	}
+	return res
+}
+
+func TestLowerOnlyWithSubscribers(t *testing.T) {
+	for name, has := range lowered(t, false) {
+		assert.False(t, has, "module '%s' lowered without subscribers", name)
+	}
+	for name, has := range lowered(t, true) {
+		assert.True(t, has, "module '%s' not lowered", name)
+	}
+}
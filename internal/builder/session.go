@@ -0,0 +1,34 @@
+package builder
+
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/ids"
+)
+
+// Session owns the identifiers given to the nodes, types and bindings of a
+// build. Each build creates its own session, so several builds can run in the
+// same process, and the same input always produces the same identifiers.
+type Session struct {
+	Nodes    *ids.Generator
+	Types    *ids.Generator
+	Bindings *ids.Generator
+}
+
+func NewSession() *Session {
+	return &Session{
+		Nodes:    ids.NewGenerator(0),
+		Types:    ids.NewGenerator(types.ReservedIds),
+		Bindings: ids.NewGenerator(0),
+	}
+}
+
+// Numbers the nodes of the module that do not have an id yet, and the types
+// attached to them. Modules must be numbered in a deterministic order, such as
+// the dependency order.
+func (s *Session) Number(root *ast.Module) {
+	ast.AssignIds(root, s.Nodes)
+	ast.Walk(root, func(n ast.Node) {
+		n.GetType().If(func(tp ast.Type) { types.AssignIds(tp, s.Types) })
+	})
+}
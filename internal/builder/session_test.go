@@ -0,0 +1,82 @@
+package builder_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/backend/javascript"
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Project importing local modules besides the prelude, so the modules are
+// loaded concurrently, using values of every kind of type.
+var project = map[string]string{
+	"main.gold": `import "lib/math"
+import "lib/text"
+let limit = 10
+fn count(n Int) Int { count(n - 1) }
+fn main() {
+  let inc = fn (x Int) Int { x + 1 }
+  inc(math.half(limit + text.size))
+}
+`,
+	"lib/math.gold": "fn half(a Int) Int { a / 2 }\nfn ratio(a Float) Float { a / 2.0 }\n",
+	"lib/text.gold": "let size = 4\nfn empty(s String) Bool { s == \"\" }\n",
+}
+
+// Builds the project, returning the node and type ids of the checked
+// modules, in walk order. It may run in other goroutines, so failures are
+// returned instead of asserted.
+func build(t *testing.T) ([]string, error) {
+	dir := buildertest.Project(t, project)
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	opts.OutputTarget = javascript.NewBackend()
+
+	ids := []string{}
+	opts.OnTypeCheckReady.Subscribe(func(_ *builder.File, root *ast.Module, _ *env.Scope) {
+		ast.Walk(root, func(n ast.Node) {
+			tp := uint64(0)
+			n.GetType().If(func(t ast.Type) { tp = t.GetId() })
+			ids = append(ids, fmt.Sprintf("%T:%d:%d", n, n.GetId(), tp))
+		})
+	})
+
+	if _, err := builder.NewBuilder(opts).Build(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(opts.LocalTargetPath, "javascript/main.mjs")); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func TestConcurrentBuilds(t *testing.T) {
+	expected, err := build(t)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	type result struct {
+		ids []string
+		err error
+	}
+	results := make(chan result, 8)
+	for range 8 {
+		go func() {
+			ids, err := build(t)
+			results <- result{ids, err}
+		}()
+	}
+
+	for range 8 {
+		res := <-results
+		require.NoError(t, res.err)
+		assert.Equal(t, expected, res.ids)
+	}
+}
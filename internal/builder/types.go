@@ -1,8 +1,12 @@
 package builder
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/renatopp/golden/internal/compiler/ast"
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
 	"github.com/renatopp/golden/internal/compiler/types"
 	"github.com/renatopp/golden/internal/helpers/safe"
 )
@@ -13,7 +17,12 @@ type File struct {
 	Path     string                     // Absolute path of the module in the file system, ex: `/d/project/foo/bar/hello.gold`
 	FileName string                     // Name of the file, ex: `hello.gold`
 	Root     safe.Optional[*ast.Module] // Root node of the module, type is `ast.Module`
-	// Imports []*ModuleImport // Modules that this module imports
+	IR       safe.Optional[*ir.Module]  // Lowered representation, when OnIRReady has subscribers
+	Hash     string                     // Hash of the source, used by the module cache
+	Cached   safe.Optional[*CacheEntry] // Entry of the previous build, when the module is unchanged
+	cached   *types.Module              // Module type restored from the cache entry
+	bindings []*CachedBinding           // Declarations of the checked module, stored in the cache
+	Imports  []*ModuleImport            // Modules that this module imports
 }
 
 func NewFile(name, path, fileName string) *File {
@@ -22,12 +31,36 @@ func NewFile(name, path, fileName string) *File {
 		Path:     path,
 		FileName: fileName,
 		Root:     safe.None[*ast.Module](),
-		// Imports: make([]*ModuleImport, 0),
+		IR:       safe.None[*ir.Module](),
+		Cached:   safe.None[*CacheEntry](),
+		Imports:  make([]*ModuleImport, 0),
+	}
+}
+
+// Returns the paths of the modules imported by this module. Cached modules
+// are not parsed, their dependencies come from the cache entry.
+func (m *File) Dependencies() []string {
+	if m.Cached.Has() {
+		return slices.Sorted(maps.Keys(m.Cached.Unwrap().Dependencies))
+	}
+	paths := []string{}
+	for _, imp := range m.Imports {
+		if !slices.Contains(paths, imp.Path) {
+			paths = append(paths, imp.Path)
+		}
+	}
+	return paths
+}
+
+func (m *File) Type() *types.Module {
+	if m.Cached.Has() {
+		return m.cached
 	}
+	return m.Root.Unwrap().GetType().Unwrap().(*types.Module)
 }
 
 func (m *File) Scope() *env.Scope {
-	return m.Root.Unwrap().GetType().Unwrap().(*types.Module).Scope
+	return m.Type().Scope
 }
 
 // Represents the import from one module to another.
@@ -1,6 +1,9 @@
 package ast
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/helpers/safe"
 )
@@ -10,6 +13,8 @@ type Node interface {
 	GetId() uint64
 	SetToken(tok *token.Token)
 	GetToken() *token.Token
+	SetSpan(span *token.Span)
+	GetSpan() *token.Span
 	SetType(Type)
 	GetType() safe.Optional[Type]
 	Visit(Visitor) Node
@@ -27,29 +32,48 @@ type Type interface {
 //
 //
 
-var _nodeId = uint64(0)
-
+// Nodes are created without an id, the ids are given by the compilation
+// session (see `AssignIds`), so they do not depend on the order in which the
+// modules were parsed.
+//
+// The token is the one that identifies the node, such as the operator of a
+// binary operation, while the span covers the whole expression. Nodes created
+// outside of the parser may not have a span, in which case the location of
+// the token is used.
 type BaseNode struct {
 	Id    uint64
 	Token *token.Token
+	Span  *token.Span
 	Type  safe.Optional[Type]
 }
 
 func NewBaseNode(tok *token.Token) BaseNode {
-	_nodeId++
 	return BaseNode{
-		Id:    _nodeId,
 		Token: tok,
 		Type:  safe.None[Type](),
 	}
 }
 
-func (n *BaseNode) IsEqual(other Node) bool      { return n.Id == other.GetId() }
+func (n *BaseNode) base() *BaseNode { return n }
+func (n *BaseNode) IsEqual(other Node) bool {
+	b, ok := other.(interface{ base() *BaseNode })
+	return ok && b.base() == n
+}
 func (n *BaseNode) GetId() uint64                { return n.Id }
 func (n *BaseNode) SetToken(tok *token.Token)    { n.Token = tok }
 func (n *BaseNode) GetToken() *token.Token       { return n.Token }
+func (n *BaseNode) SetSpan(span *token.Span)     { n.Span = span }
 func (n *BaseNode) SetType(tp Type)              { n.Type = safe.Some(tp) }
 func (n *BaseNode) GetType() safe.Optional[Type] { return n.Type }
+func (n *BaseNode) GetSpan() *token.Span {
+	if n.Span != nil {
+		return n.Span
+	}
+	if n.Token != nil {
+		return n.Token.Loc
+	}
+	return nil
+}
 func (n *BaseNode) Visit(v Visitor) Node {
 	panic("base node does not have visitor")
 }
@@ -58,11 +82,26 @@ func (n *BaseNode) Visit(v Visitor) Node {
 
 type Module struct {
 	BaseNode
-	Exprs []Node
+	Imports []*Import
+	Exprs   []Node
+}
+
+func NewModule(tok *token.Token, exprs []Node) *Module {
+	return &Module{BaseNode: NewBaseNode(tok), Imports: []*Import{}, Exprs: exprs}
 }
+func (n *Module) Visit(v Visitor) Node { return v.VisitModule(n) }
 
-func NewModule(tok *token.Token, exprs []Node) *Module { return &Module{NewBaseNode(tok), exprs} }
-func (n *Module) Visit(v Visitor) Node                 { return v.VisitModule(n) }
+// Imports are not expressions, they are declared by the module and bind the
+// imported module to the alias, which defaults to the module name.
+type Import struct {
+	Token *token.Token
+	Path  *token.Token
+	Alias safe.Optional[*token.Token]
+}
+
+func NewImport(tok *token.Token, path *token.Token, alias safe.Optional[*token.Token]) *Import {
+	return &Import{Token: tok, Path: path, Alias: alias}
+}
 
 type VarDecl struct {
 	BaseNode
@@ -159,10 +198,13 @@ func (n *Block) Visit(v Visitor) Node                { return v.VisitBlock(n) }
 
 type FnDecl struct {
 	BaseNode
-	Name      safe.Optional[*VarIdent]
-	Params    []*FnDeclParam
-	TypeExpr  Node
-	ValueExpr *Block
+	Name        safe.Optional[*VarIdent]
+	Params      []*FnDeclParam
+	TypeExpr    Node
+	ValueExpr   *Block
+	Annotations []*token.Token // @inline, @noinline
+	Recursive   bool           // Set by the checker when the function reaches itself
+	TailCalls   bool           // Set when any return of the function is a tail call to itself
 }
 
 func NewFnDecl(tok *token.Token, name safe.Optional[*VarIdent], params []*FnDeclParam, ret Node, val *Block) *FnDecl {
@@ -177,6 +219,60 @@ func NewFnDecl(tok *token.Token, name safe.Optional[*VarIdent], params []*FnDecl
 
 func (n *FnDecl) Visit(v Visitor) Node { return v.VisitFnDecl(n) }
 
+func (n *FnDecl) HasAnnotation(name string) bool {
+	for _, a := range n.Annotations {
+		if a.Literal == "@"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// Function implemented by the host of the backend, such as a function of the
+// Go standard library. Each binding names the implementation for a target,
+// ex: `go:strings.ToUpper`. The declared signature is trusted by the checker.
+type ExternFnDecl struct {
+	BaseNode
+	Name     *VarIdent
+	Params   []*FnDeclParam
+	TypeExpr Node
+	Bindings []*token.Token // Strings in the `<target>:<name>` format
+}
+
+func NewExternFnDecl(tok *token.Token, name *VarIdent, params []*FnDeclParam, ret Node, bindings []*token.Token) *ExternFnDecl {
+	return &ExternFnDecl{
+		BaseNode: NewBaseNode(tok),
+		Name:     name,
+		Params:   params,
+		TypeExpr: ret,
+		Bindings: bindings,
+	}
+}
+
+func (n *ExternFnDecl) Visit(v Visitor) Node { return v.VisitExternFnDecl(n) }
+
+// Returns the binding of the target, without the target prefix.
+func (n *ExternFnDecl) Binding(target string) (string, bool) {
+	for _, b := range n.Bindings {
+		if name, ok := strings.CutPrefix(b.Literal, target+":"); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+var goBindingRegex = regexp.MustCompile(`^((?:[^/]+/)*[^./]+)\.([A-Z][a-zA-Z0-9_]*)$`)
+
+// Splits a Go binding, ex: `math/rand.Intn`, in the import path of the package
+// and the exported name of the function.
+func SplitGoBinding(binding string) (pkg, name string, ok bool) {
+	m := goBindingRegex.FindStringSubmatch(binding)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
 type FnDeclParam struct {
 	BaseNode
 	Name     *VarIdent
@@ -222,9 +318,27 @@ func NewApplication(tok *token.Token, target Node, args []Node) *Application {
 }
 func (n *Application) Visit(v Visitor) Node { return v.VisitApplication(n) }
 
+// Access to a member of a module, such as `math.max`. The name is not a
+// reference by itself, it is resolved in the scope of the target.
+type Access struct {
+	BaseNode
+	Target Node
+	Name   *VarIdent
+}
+
+func NewAccess(tok *token.Token, target Node, name *VarIdent) *Access {
+	return &Access{
+		BaseNode: NewBaseNode(tok),
+		Target:   target,
+		Name:     name,
+	}
+}
+func (n *Access) Visit(v Visitor) Node { return v.VisitAccess(n) }
+
 type Return struct {
 	BaseNode
 	ValueExpr safe.Optional[Node]
+	TailCall  bool // Returns a call to the enclosing function, which backends turn into a loop
 }
 
 func NewReturn(tok *token.Token, val safe.Optional[Node]) *Return {
@@ -19,9 +19,11 @@ type Visitor interface {
 	VisitBlock(*Block) Node
 
 	VisitFnDecl(*FnDecl) Node
+	VisitExternFnDecl(*ExternFnDecl) Node
 	VisitFnDeclParam(*FnDeclParam) Node
 	VisitTypeFn(*TypeFn) Node
 	VisitApplication(*Application) Node
+	VisitAccess(*Access) Node
 	VisitReturn(*Return) Node
 }
 
@@ -71,6 +73,12 @@ func (v *Visiter) VisitFnDecl(node *FnDecl) Node {
 	node.ValueExpr = node.ValueExpr.Visit(v.self).(*Block)
 	return node
 }
+func (v *Visiter) VisitExternFnDecl(node *ExternFnDecl) Node {
+	node.Name = node.Name.Visit(v.self).(*VarIdent)
+	node.Params = iter.Map(node.Params, func(n *FnDeclParam) *FnDeclParam { return n.Visit(v.self).(*FnDeclParam) })
+	node.TypeExpr = node.TypeExpr.Visit(v.self)
+	return node
+}
 func (v *Visiter) VisitFnDeclParam(node *FnDeclParam) Node {
 	node.Name = node.Name.Visit(v.self).(*VarIdent)
 	node.TypeExpr = node.TypeExpr.Visit(v.self)
@@ -86,6 +94,10 @@ func (v *Visiter) VisitApplication(node *Application) Node {
 	node.Args = iter.Map(node.Args, func(n Node) Node { return n.Visit(v.self) })
 	return node
 }
+func (v *Visiter) VisitAccess(node *Access) Node {
+	node.Target = node.Target.Visit(v.self)
+	return node
+}
 func (v *Visiter) VisitReturn(node *Return) Node {
 	node.ValueExpr = safe.Map(node.ValueExpr, func(n Node) Node { return n.Visit(v.self) })
 	return node
@@ -0,0 +1,64 @@
+package ast
+
+import "github.com/renatopp/golden/internal/helpers/ids"
+
+// Walk calls `fn` for the node and all its descendants, parents first and
+// children in source order.
+func Walk(node Node, fn func(Node)) {
+	fn(node)
+	switch n := node.(type) {
+	case *Module:
+		for _, e := range n.Exprs {
+			Walk(e, fn)
+		}
+	case *VarDecl:
+		Walk(n.Name, fn)
+		n.TypeExpr.If(func(t Node) { Walk(t, fn) })
+		Walk(n.ValueExpr, fn)
+	case *BinOp:
+		Walk(n.LeftExpr, fn)
+		Walk(n.RightExpr, fn)
+	case *UnaryOp:
+		Walk(n.RightExpr, fn)
+	case *Block:
+		for _, e := range n.Exprs {
+			Walk(e, fn)
+		}
+	case *FnDecl:
+		n.Name.If(func(name *VarIdent) { Walk(name, fn) })
+		for _, p := range n.Params {
+			Walk(p, fn)
+		}
+		Walk(n.TypeExpr, fn)
+		Walk(n.ValueExpr, fn)
+	case *FnDeclParam:
+		Walk(n.Name, fn)
+		Walk(n.TypeExpr, fn)
+	case *TypeFn:
+		for _, p := range n.Parameters {
+			Walk(p, fn)
+		}
+		Walk(n.ReturnExpr, fn)
+	case *Application:
+		Walk(n.Target, fn)
+		for _, a := range n.Args {
+			Walk(a, fn)
+		}
+	case *Access:
+		Walk(n.Target, fn)
+		Walk(n.Name, fn)
+	case *Return:
+		n.ValueExpr.If(func(v Node) { Walk(v, fn) })
+	}
+}
+
+// AssignIds gives an id to every node of the tree that does not have one yet,
+// in walk order. Nodes keep their ids when the tree is numbered again after
+// being transformed.
+func AssignIds(root Node, gen *ids.Generator) {
+	Walk(root, func(n Node) {
+		if b := n.(interface{ base() *BaseNode }).base(); b.Id == 0 {
+			b.Id = gen.Next()
+		}
+	})
+}
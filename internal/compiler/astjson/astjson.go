@@ -0,0 +1,121 @@
+// Package astjson converts modules to a versioned JSON document and back, so
+// external tools can consume the AST without depending on the compiler.
+package astjson
+
+import (
+	"encoding/json"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// Version of the document format. It changes whenever a node kind or field is
+// changed in an incompatible way.
+const Version = 1
+
+// Node kinds.
+const (
+	KindModule      = "module"
+	KindVarDecl     = "var_decl"
+	KindInt         = "int"
+	KindFloat       = "float"
+	KindString      = "string"
+	KindBool        = "bool"
+	KindVarIdent    = "var_ident"
+	KindTypeIdent   = "type_ident"
+	KindBinOp       = "bin_op"
+	KindUnaryOp     = "unary_op"
+	KindBlock       = "block"
+	KindFnDecl      = "fn_decl"
+	KindFnDeclParam = "fn_decl_param"
+	KindExternFn    = "extern_fn_decl"
+	KindTypeFn      = "type_fn"
+	KindApplication = "application"
+	KindAccess      = "access"
+	KindReturn      = "return"
+)
+
+type Document struct {
+	Version int   `json:"version"`
+	Root    *Node `json:"root"`
+}
+
+// Span of the whole node. Offsets are in bytes, the end is exclusive.
+type Span struct {
+	File       string `json:"file"`
+	FromLine   int    `json:"from_line"`
+	FromColumn int    `json:"from_column"`
+	FromOffset int    `json:"from_offset"`
+	ToLine     int    `json:"to_line"`
+	ToColumn   int    `json:"to_column"`
+	ToOffset   int    `json:"to_offset"`
+}
+
+// Node is the encoding of any AST node. Only the fields used by the kind are
+// present in the document.
+type Node struct {
+	Kind        string          `json:"kind"`
+	Span        *Span           `json:"span,omitempty"`
+	Type        string          `json:"type,omitempty"`  // Signature of the resolved type
+	Value       json.RawMessage `json:"value,omitempty"` // Literals and identifiers
+	Op          string          `json:"op,omitempty"`
+	Name        *Node           `json:"name,omitempty"` // Identifier of declarations
+	Left        *Node           `json:"left,omitempty"`
+	Right       *Node           `json:"right,omitempty"`
+	Target      *Node           `json:"target,omitempty"`
+	TypeExpr    *Node           `json:"type_expr,omitempty"`
+	ValueExpr   *Node           `json:"value_expr,omitempty"`
+	Params      []*Node         `json:"params,omitempty"`
+	Args        []*Node         `json:"args,omitempty"`
+	Exprs       []*Node         `json:"exprs,omitempty"`
+	Imports     []*Import       `json:"imports,omitempty"` // Imports of modules
+	Annotations []string        `json:"annotations,omitempty"`
+	Bindings    []string        `json:"bindings,omitempty"` // Bindings of extern functions
+	Recursive   bool            `json:"recursive,omitempty"`
+	TailCalls   bool            `json:"tail_calls,omitempty"`
+	TailCall    bool            `json:"tail_call,omitempty"`
+}
+
+// Import of a module. The span is the span of the imported path.
+type Import struct {
+	Path  string `json:"path"`
+	Alias string `json:"alias,omitempty"`
+	Span  *Span  `json:"span,omitempty"`
+}
+
+// Encodes the module, including the resolved types when the module was
+// checked.
+func Encode(root *ast.Module) ([]byte, error) {
+	var data []byte
+	err := errors.WithRecovery(func() {
+		doc := &Document{
+			Version: Version,
+			Root:    NewEncoder().Encode(root),
+		}
+
+		var err error
+		data, err = json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			errors.Throw(errors.SerializationError, "could not encode the module: %v", err)
+		}
+	})
+	return data, err
+}
+
+// Decodes a document created by Encode. Types are rebuilt from their
+// signatures, but they are not bound to scopes, so the module must be checked
+// again before being compiled.
+func Decode(data []byte) (*ast.Module, error) {
+	var root *ast.Module
+	err := errors.WithRecovery(func() {
+		doc := &Document{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			errors.Throw(errors.SerializationError, "invalid document: %v", err)
+		}
+		if doc.Version != Version {
+			errors.Throw(errors.SerializationError, "unsupported document version %d, expected %d", doc.Version, Version)
+		}
+		root = NewDecoder().Decode(doc.Root)
+	})
+	return root, err
+}
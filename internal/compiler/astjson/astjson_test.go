@@ -0,0 +1,174 @@
+package astjson_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/astjson"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `
+let limit = 9223372036854775807
+let ratio = 0.1
+let greeting = "hello\n\tworld"
+fn twice(a Int) Int { a * 2 }
+fn apply(f Fn(Int) Int, v Int) Int { f(v) }
+@inline
+fn negate(b Bool) Bool { !b }
+fn count(n, acc Int) Int {
+  if_zero(n)
+  return count(n - 1, acc + 1)
+}
+fn if_zero(n Int) Bool { n == 0 and true }
+fn main() {
+  let inc = fn (x Int) Int { x + 1 }
+  apply(inc, twice(3))
+  negate(limit > 0)
+}
+`
+
+func parse(t *testing.T) *ast.Module {
+	tokens, err := syntax.NewLexer("test.gold", []byte(source)).Lex()
+	require.NoError(t, err)
+	root, err := syntax.NewParser(tokens).Parse()
+	require.NoError(t, err)
+	return root
+}
+
+func check(t *testing.T) *ast.Module {
+	dir := buildertest.Project(t, map[string]string{"test.gold": source})
+	opts := buildertest.Options(t, filepath.Join(dir, "test.gold"))
+	opts.NoCache = true
+
+	var root *ast.Module
+	opts.OnTypeCheckReady.Subscribe(func(_ *builder.File, r *ast.Module, _ *env.Scope) { root = r })
+	_, err := builder.NewBuilder(opts).Check()
+	require.NoError(t, err)
+	require.NotNil(t, root)
+	return root
+}
+
+func roundTrip(t *testing.T, root *ast.Module) {
+	first, err := astjson.Encode(root)
+	require.NoError(t, err)
+
+	decoded, err := astjson.Decode(first)
+	require.NoError(t, err)
+
+	second, err := astjson.Encode(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(first), string(second))
+}
+
+func TestRoundTripParsed(t *testing.T) {
+	roundTrip(t, parse(t))
+}
+
+func TestRoundTripTyped(t *testing.T) {
+	root := check(t)
+	roundTrip(t, root)
+
+	data, err := astjson.Encode(root)
+	require.NoError(t, err)
+	decoded, err := astjson.Decode(data)
+	require.NoError(t, err)
+
+	decl := decoded.Exprs[4].(*ast.FnDecl)
+	assert.Equal(t, "apply", decl.Name.Unwrap().Value)
+	assert.Equal(t, "Fn(Fn(Int) Int, Int) Int", decl.GetType().Unwrap().GetSignature())
+}
+
+func TestDecodeLiterals(t *testing.T) {
+	data, err := astjson.Encode(parse(t))
+	require.NoError(t, err)
+	root, err := astjson.Decode(data)
+	require.NoError(t, err)
+
+	limit := root.Exprs[0].(*ast.VarDecl).ValueExpr.(*ast.Int)
+	assert.Equal(t, int64(9223372036854775807), limit.Value)
+
+	ratio := root.Exprs[1].(*ast.VarDecl).ValueExpr.(*ast.Float)
+	assert.Equal(t, 0.1, ratio.Value)
+
+	greeting := root.Exprs[2].(*ast.VarDecl).ValueExpr.(*ast.String)
+	assert.Equal(t, "hello\n\tworld", greeting.Value)
+
+	negate := root.Exprs[5].(*ast.FnDecl)
+	assert.True(t, negate.HasAnnotation("inline"))
+
+	tok := limit.GetToken()
+	assert.Equal(t, "test.gold", tok.Loc.Filename)
+	assert.Equal(t, 2, tok.Loc.FromLine)
+}
+
+func TestSpans(t *testing.T) {
+	data, err := astjson.Encode(parse(t))
+	require.NoError(t, err)
+	root, err := astjson.Decode(data)
+	require.NoError(t, err)
+
+	text := func(n ast.Node) string {
+		span := n.GetSpan()
+		return source[span.FromOffset:span.ToOffset]
+	}
+
+	main := root.Exprs[len(root.Exprs)-1].(*ast.FnDecl)
+	body := main.ValueExpr.Exprs
+	assert.Equal(t, "let inc = fn (x Int) Int { x + 1 }", text(body[0]))
+	assert.Equal(t, "apply(inc, twice(3))", text(body[1]))
+	assert.Equal(t, "limit > 0", text(body[2].(*ast.Application).Args[0]))
+
+	span := main.GetSpan()
+	assert.Equal(t, 14, span.FromLine)
+	assert.Equal(t, 18, span.ToLine)
+	assert.Equal(t, 2, span.ToColumn)
+
+	negate := root.Exprs[5]
+	assert.Equal(t, "@inline\nfn negate(b Bool) Bool { !b }", text(negate))
+}
+
+func TestRoundTripImports(t *testing.T) {
+	tokens, err := syntax.NewLexer("test.gold", []byte("import \"@/lib/math\" as m\nimport \"io\"\nlet x = m.max(1, 2)\n")).Lex()
+	require.NoError(t, err)
+	root, err := syntax.NewParser(tokens).Parse()
+	require.NoError(t, err)
+	roundTrip(t, root)
+
+	data, err := astjson.Encode(root)
+	require.NoError(t, err)
+	decoded, err := astjson.Decode(data)
+	require.NoError(t, err)
+
+	require.Len(t, decoded.Imports, 2)
+	assert.Equal(t, "@/lib/math", decoded.Imports[0].Path.Literal)
+	assert.Equal(t, "m", decoded.Imports[0].Alias.Unwrap().Literal)
+	assert.False(t, decoded.Imports[1].Alias.Has())
+
+	access := decoded.Exprs[0].(*ast.VarDecl).ValueExpr.(*ast.Application).Target.(*ast.Access)
+	assert.Equal(t, "m", access.Target.(*ast.VarIdent).Value)
+	assert.Equal(t, "max", access.Name.Value)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := astjson.Decode([]byte(`{"version": 999, "root": {"kind": "module"}}`))
+	assert.ErrorContains(t, err, "unsupported document version")
+
+	_, err = astjson.Decode([]byte(`{"version": 1, "root": {"kind": "block"}}`))
+	assert.ErrorContains(t, err, "root must be a module")
+
+	_, err = astjson.Decode([]byte(`{"version": 1, "root": {"kind": "module", "exprs": [{"kind": "loop"}]}}`))
+	assert.ErrorContains(t, err, "unknown node kind 'loop'")
+
+	_, err = astjson.Decode([]byte(`{"version": 1, "root": {"kind": "module", "exprs": [{"kind": "int", "type": "Matrix", "value": 1}]}}`))
+	assert.ErrorContains(t, err, "unknown type 'Matrix'")
+
+	_, err = astjson.Decode([]byte(`not json`))
+	assert.ErrorContains(t, err, "invalid document")
+}
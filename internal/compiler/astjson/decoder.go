@@ -0,0 +1,281 @@
+package astjson
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/safe"
+)
+
+type Decoder struct {
+	root *ast.Module // Module being decoded, the definition of the module type
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+func (d *Decoder) Decode(n *Node) *ast.Module {
+	if n == nil || n.Kind != KindModule {
+		errors.Throw(errors.SerializationError, "document root must be a module")
+	}
+	return d.decode(n).(*ast.Module)
+}
+
+func (d *Decoder) decode(n *Node) ast.Node {
+	tok := d.token(n)
+
+	var node ast.Node
+	switch n.Kind {
+	case KindModule:
+		d.root = ast.NewModule(tok, nil)
+		for _, i := range n.Imports {
+			path := d.spanToken(i.Span)
+			path.Kind, path.Literal = token.TString, i.Path
+			alias := safe.None[*token.Token]()
+			if i.Alias != "" {
+				alias = safe.Some(&token.Token{Kind: token.TVarIdent, Loc: path.Loc, Literal: i.Alias})
+			}
+			d.root.Imports = append(d.root.Imports, ast.NewImport(&token.Token{Kind: token.TImport, Loc: path.Loc, Literal: "import"}, path, alias))
+		}
+		d.root.Exprs = d.list(n.Exprs)
+		node = d.root
+
+	case KindVarDecl:
+		tp := safe.None[ast.Node]()
+		if n.TypeExpr != nil {
+			tp = safe.Some(d.decode(n.TypeExpr))
+		}
+		node = ast.NewVarDecl(tok, d.ident(n.Name), tp, d.child(n.ValueExpr))
+
+	case KindInt:
+		var v int64
+		d.value(n, &v)
+		node = ast.NewInt(tok, v)
+
+	case KindFloat:
+		var v float64
+		d.value(n, &v)
+		node = ast.NewFloat(tok, v)
+
+	case KindString:
+		var v string
+		d.value(n, &v)
+		node = ast.NewString(tok, v)
+
+	case KindBool:
+		var v bool
+		d.value(n, &v)
+		node = ast.NewBool(tok, v)
+
+	case KindVarIdent:
+		var v string
+		d.value(n, &v)
+		node = ast.NewVarIdent(tok, v)
+
+	case KindTypeIdent:
+		var v string
+		d.value(n, &v)
+		node = ast.NewTypeIdent(tok, v)
+
+	case KindBinOp:
+		node = ast.NewBinOp(tok, n.Op, d.child(n.Left), d.child(n.Right))
+
+	case KindUnaryOp:
+		node = ast.NewUnaryOp(tok, n.Op, d.child(n.Right))
+
+	case KindBlock:
+		node = ast.NewBlock(tok, d.list(n.Exprs))
+
+	case KindFnDecl:
+		name := safe.None[*ast.VarIdent]()
+		if n.Name != nil {
+			name = safe.Some(d.ident(n.Name))
+		}
+		params := d.params(n.Params)
+		body, ok := d.child(n.ValueExpr).(*ast.Block)
+		if !ok {
+			errors.Throw(errors.SerializationError, "function body must be a block")
+		}
+		fn := ast.NewFnDecl(tok, name, params, d.child(n.TypeExpr), body)
+		for _, a := range n.Annotations {
+			fn.Annotations = append(fn.Annotations, &token.Token{Kind: token.TAnnotation, Loc: tok.Loc, Literal: a})
+		}
+		fn.Recursive = n.Recursive
+		fn.TailCalls = n.TailCalls
+		node = fn
+
+	case KindExternFn:
+		bindings := []*token.Token{}
+		for _, b := range n.Bindings {
+			bindings = append(bindings, &token.Token{Kind: token.TString, Loc: tok.Loc, Literal: b})
+		}
+		node = ast.NewExternFnDecl(tok, d.ident(n.Name), d.params(n.Params), d.child(n.TypeExpr), bindings)
+
+	case KindFnDeclParam:
+		param := ast.NewFnDeclParam(d.ident(n.Name), d.child(n.TypeExpr))
+		param.SetToken(tok)
+		node = param
+
+	case KindTypeFn:
+		node = ast.NewTypeFn(tok, d.list(n.Params), d.child(n.TypeExpr))
+
+	case KindApplication:
+		node = ast.NewApplication(tok, d.child(n.Target), d.list(n.Args))
+
+	case KindAccess:
+		node = ast.NewAccess(tok, d.child(n.Target), d.ident(n.Name))
+
+	case KindReturn:
+		val := safe.None[ast.Node]()
+		if n.ValueExpr != nil {
+			val = safe.Some(d.decode(n.ValueExpr))
+		}
+		ret := ast.NewReturn(tok, val)
+		ret.TailCall = n.TailCall
+		node = ret
+
+	default:
+		errors.Throw(errors.SerializationError, "unknown node kind '%s'", n.Kind)
+	}
+
+	node.SetSpan(tok.Loc)
+	if n.Type != "" {
+		node.SetType(d.parseType(n.Type))
+	}
+	return node
+}
+
+// Creates the token of the node. Only the span of the whole node is encoded,
+// so the token is located at the whole node.
+func (d *Decoder) token(n *Node) *token.Token {
+	return d.spanToken(n.Span)
+}
+
+func (d *Decoder) spanToken(span *Span) *token.Token {
+	tok := &token.Token{}
+	if span != nil {
+		tok.Loc = &token.Span{
+			Filename:   span.File,
+			FromLine:   span.FromLine,
+			FromColumn: span.FromColumn,
+			FromOffset: span.FromOffset,
+			ToLine:     span.ToLine,
+			ToColumn:   span.ToColumn,
+			ToOffset:   span.ToOffset,
+		}
+	}
+	return tok
+}
+
+// Decodes a required child node.
+func (d *Decoder) child(n *Node) ast.Node {
+	if n == nil {
+		errors.Throw(errors.SerializationError, "missing node")
+	}
+	return d.decode(n)
+}
+
+func (d *Decoder) ident(n *Node) *ast.VarIdent {
+	ident, ok := d.child(n).(*ast.VarIdent)
+	if !ok {
+		errors.Throw(errors.SerializationError, "identifier expected, got '%s'", n.Kind)
+	}
+	return ident
+}
+
+func (d *Decoder) params(nodes []*Node) []*ast.FnDeclParam {
+	params := []*ast.FnDeclParam{}
+	for _, p := range nodes {
+		param, ok := d.child(p).(*ast.FnDeclParam)
+		if !ok {
+			errors.Throw(errors.SerializationError, "function parameter expected, got '%s'", p.Kind)
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func (d *Decoder) list(nodes []*Node) []ast.Node {
+	res := []ast.Node{}
+	for _, n := range nodes {
+		res = append(res, d.child(n))
+	}
+	return res
+}
+
+func (d *Decoder) value(n *Node, v any) {
+	if err := json.Unmarshal(n.Value, v); err != nil {
+		errors.Throw(errors.SerializationError, "invalid value of '%s' node: %v", n.Kind, err)
+	}
+}
+
+//
+//
+//
+
+// Rebuilds a type from its signature, as produced by `ast.Type.GetSignature`.
+func (d *Decoder) parseType(signature string) ast.Type {
+	tp, rest := d.parseTypeSignature(signature)
+	if rest != "" {
+		errors.Throw(errors.SerializationError, "invalid type signature '%s'", signature)
+	}
+	return tp
+}
+
+// Parses the type at the beginning of the signature, returning the remaining
+// of the signature.
+func (d *Decoder) parseTypeSignature(s string) (ast.Type, string) {
+	switch {
+	case strings.HasPrefix(s, "Fn("):
+		s = s[len("Fn("):]
+		params := []ast.Type{}
+		for !strings.HasPrefix(s, ")") {
+			var p ast.Type
+			p, s = d.parseTypeSignature(s)
+			params = append(params, p)
+			s = strings.TrimPrefix(s, ", ")
+			if s == "" {
+				errors.Throw(errors.SerializationError, "unterminated function signature")
+			}
+		}
+		s = s[1:]
+
+		var ret ast.Type = types.Void
+		if strings.HasPrefix(s, " ") {
+			ret, s = d.parseTypeSignature(s[1:])
+		}
+		return types.NewFunction(nil, params, ret), s
+
+	case strings.HasPrefix(s, "Module('"):
+		end := strings.Index(s, "')")
+		if end < 0 {
+			errors.Throw(errors.SerializationError, "unterminated module signature")
+		}
+		return types.NewModule(d.root, s[len("Module('"):end], nil), s[end+2:]
+	}
+
+	name := s
+	if i := strings.IndexAny(s, ",) "); i >= 0 {
+		name = s[:i]
+	}
+	switch name {
+	case types.Int.Name:
+		return types.Int, s[len(name):]
+	case types.Float.Name:
+		return types.Float, s[len(name):]
+	case types.Bool.Name:
+		return types.Bool, s[len(name):]
+	case types.String.Name:
+		return types.String, s[len(name):]
+	case types.Void.GetSignature():
+		return types.Void, s[len(name):]
+	}
+
+	errors.Throw(errors.SerializationError, "unknown type '%s'", name)
+	return nil, ""
+}
@@ -0,0 +1,228 @@
+package astjson
+
+import (
+	"encoding/json"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+var _ ast.Visitor = &Encoder{}
+
+type Encoder struct {
+	stack []*Node
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (e *Encoder) Push(n *Node) {
+	e.stack = append(e.stack, n)
+}
+
+func (e *Encoder) Pop() *Node {
+	n := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return n
+}
+
+func (e *Encoder) Encode(root *ast.Module) *Node {
+	root.Visit(e)
+	return e.Pop()
+}
+
+func (e *Encoder) VisitModule(node *ast.Module) ast.Node {
+	e.Push(e.node(KindModule, node, func(n *Node) {
+		for _, imp := range node.Imports {
+			i := &Import{Path: imp.Path.Literal, Span: e.span(imp.Path.Loc)}
+			imp.Alias.If(func(t *token.Token) { i.Alias = t.Literal })
+			n.Imports = append(n.Imports, i)
+		}
+		n.Exprs = e.list(node.Exprs)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitVarDecl(node *ast.VarDecl) ast.Node {
+	e.Push(e.node(KindVarDecl, node, func(n *Node) {
+		n.Name = e.child(node.Name)
+		node.TypeExpr.If(func(tp ast.Node) { n.TypeExpr = e.child(tp) })
+		n.ValueExpr = e.child(node.ValueExpr)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitInt(node *ast.Int) ast.Node {
+	e.Push(e.node(KindInt, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitFloat(node *ast.Float) ast.Node {
+	e.Push(e.node(KindFloat, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitString(node *ast.String) ast.Node {
+	e.Push(e.node(KindString, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitBool(node *ast.Bool) ast.Node {
+	e.Push(e.node(KindBool, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	e.Push(e.node(KindVarIdent, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
+	e.Push(e.node(KindTypeIdent, node, func(n *Node) { n.Value = e.value(node.Value) }))
+	return node
+}
+
+func (e *Encoder) VisitBinOp(node *ast.BinOp) ast.Node {
+	e.Push(e.node(KindBinOp, node, func(n *Node) {
+		n.Op = node.Op
+		n.Left = e.child(node.LeftExpr)
+		n.Right = e.child(node.RightExpr)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
+	e.Push(e.node(KindUnaryOp, node, func(n *Node) {
+		n.Op = node.Op
+		n.Right = e.child(node.RightExpr)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitBlock(node *ast.Block) ast.Node {
+	e.Push(e.node(KindBlock, node, func(n *Node) {
+		n.Exprs = e.list(node.Exprs)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	e.Push(e.node(KindFnDecl, node, func(n *Node) {
+		node.Name.If(func(name *ast.VarIdent) { n.Name = e.child(name) })
+		for _, p := range node.Params {
+			n.Params = append(n.Params, e.child(p))
+		}
+		n.TypeExpr = e.child(node.TypeExpr)
+		n.ValueExpr = e.child(node.ValueExpr)
+		for _, a := range node.Annotations {
+			n.Annotations = append(n.Annotations, a.Literal)
+		}
+		n.Recursive = node.Recursive
+		n.TailCalls = node.TailCalls
+	}))
+	return node
+}
+
+func (e *Encoder) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	e.Push(e.node(KindExternFn, node, func(n *Node) {
+		n.Name = e.child(node.Name)
+		for _, p := range node.Params {
+			n.Params = append(n.Params, e.child(p))
+		}
+		n.TypeExpr = e.child(node.TypeExpr)
+		for _, b := range node.Bindings {
+			n.Bindings = append(n.Bindings, b.Literal)
+		}
+	}))
+	return node
+}
+
+func (e *Encoder) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
+	e.Push(e.node(KindFnDeclParam, node, func(n *Node) {
+		n.Name = e.child(node.Name)
+		n.TypeExpr = e.child(node.TypeExpr)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitTypeFn(node *ast.TypeFn) ast.Node {
+	e.Push(e.node(KindTypeFn, node, func(n *Node) {
+		n.Params = e.list(node.Parameters)
+		n.TypeExpr = e.child(node.ReturnExpr)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitApplication(node *ast.Application) ast.Node {
+	e.Push(e.node(KindApplication, node, func(n *Node) {
+		n.Target = e.child(node.Target)
+		n.Args = e.list(node.Args)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitAccess(node *ast.Access) ast.Node {
+	e.Push(e.node(KindAccess, node, func(n *Node) {
+		n.Target = e.child(node.Target)
+		n.Name = e.child(node.Name)
+	}))
+	return node
+}
+
+func (e *Encoder) VisitReturn(node *ast.Return) ast.Node {
+	e.Push(e.node(KindReturn, node, func(n *Node) {
+		node.ValueExpr.If(func(v ast.Node) { n.ValueExpr = e.child(v) })
+		n.TailCall = node.TailCall
+	}))
+	return node
+}
+
+//
+//
+//
+
+// Creates the encoding of the node with the fields shared by all kinds.
+func (e *Encoder) node(kind string, node ast.Node, fill func(*Node)) *Node {
+	n := &Node{Kind: kind, Span: e.span(node.GetSpan())}
+	node.GetType().If(func(tp ast.Type) { n.Type = tp.GetSignature() })
+	fill(n)
+	return n
+}
+
+func (e *Encoder) span(span *token.Span) *Span {
+	if span == nil {
+		return nil
+	}
+	return &Span{
+		File:       span.Filename,
+		FromLine:   span.FromLine,
+		FromColumn: span.FromColumn,
+		FromOffset: span.FromOffset,
+		ToLine:     span.ToLine,
+		ToColumn:   span.ToColumn,
+		ToOffset:   span.ToOffset,
+	}
+}
+
+func (e *Encoder) child(node ast.Node) *Node {
+	node.Visit(e)
+	return e.Pop()
+}
+
+func (e *Encoder) list(nodes []ast.Node) []*Node {
+	res := []*Node{}
+	for _, node := range nodes {
+		res = append(res, e.child(node))
+	}
+	return res
+}
+
+func (e *Encoder) value(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		errors.Throw(errors.SerializationError, "could not encode value %v: %v", v, err)
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+// Package builtins declares the primitives implemented by the runtimes of the
+// backends. They are only visible to the modules of the standard library,
+// which wrap them in Golden functions, and their names start with `__`, which
+// is reserved to the compiler.
+package builtins
+
+import (
+	"slices"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/types"
+)
+
+// Alias of the prelude in the modules outside of the standard library, which
+// import it implicitly. Names not declared by the module are looked up in it.
+const PreludeAlias = "__prelude"
+
+type Builtin struct {
+	Name   string
+	Params []ast.Type
+	Return ast.Type
+}
+
+func fn(name string, ret ast.Type, params ...ast.Type) *Builtin {
+	return &Builtin{Name: name, Params: params, Return: ret}
+}
+
+var Builtins = []*Builtin{
+	fn("__print", types.Void, types.String),
+	fn("__println", types.Void, types.String),
+
+	fn("__format_int", types.String, types.Int),
+	fn("__format_float", types.String, types.Float),
+	fn("__format_bool", types.String, types.Bool),
+
+	fn("__string_length", types.Int, types.String),
+	fn("__string_repeat", types.String, types.String, types.Int),
+	fn("__string_contains", types.Bool, types.String, types.String),
+	fn("__string_has_prefix", types.Bool, types.String, types.String),
+	fn("__string_has_suffix", types.Bool, types.String, types.String),
+	fn("__string_upper", types.String, types.String),
+	fn("__string_lower", types.String, types.String),
+	fn("__string_trim", types.String, types.String),
+
+	fn("__float_abs", types.Float, types.Float),
+	fn("__float_sqrt", types.Float, types.Float),
+	fn("__float_pow", types.Float, types.Float, types.Float),
+	fn("__float_floor", types.Float, types.Float),
+	fn("__float_ceil", types.Float, types.Float),
+	fn("__float_min", types.Float, types.Float, types.Float),
+	fn("__float_max", types.Float, types.Float, types.Float),
+
+	fn("__int_abs", types.Int, types.Int),
+	fn("__int_min", types.Int, types.Int, types.Int),
+	fn("__int_max", types.Int, types.Int, types.Int),
+	fn("__int_to_float", types.Float, types.Int),
+	fn("__float_to_int", types.Int, types.Float),
+}
+
+func Lookup(name string) (*Builtin, bool) {
+	i := slices.IndexFunc(Builtins, func(b *Builtin) bool { return b.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+	return Builtins[i], true
+}
+
+// Creates the scope of the standard library, with the builtins on top of the
+// given scope. The function types are created for each scope, since they are
+// numbered by the compilation session.
+func NewScope(parent *env.Scope) *env.Scope {
+	scope := parent.New()
+	for _, b := range Builtins {
+		scope.Values.Set(b.Name, env.VB(nil, types.NewFunction(nil, b.Params, b.Return)))
+	}
+	return scope
+}
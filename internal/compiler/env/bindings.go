@@ -1,9 +1,12 @@
 package env
 
-import "github.com/renatopp/golden/internal/compiler/ast"
-
-var _bindingId uint64 = 0
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/ids"
+)
 
+// Bindings are created without an id, they receive one from the scope when
+// declared.
 type ValueBinding struct {
 	Id             uint64
 	DefinitionNode ast.Node
@@ -14,9 +17,7 @@ type ValueBinding struct {
 }
 
 func NewValueBinding(n ast.Node, t ast.Type) *ValueBinding {
-	_bindingId++
 	return &ValueBinding{
-		Id:             _bindingId,
 		DefinitionNode: n,
 		Assignments:    []ast.Node{n},
 		References:     []ast.Node{},
@@ -40,6 +41,12 @@ func (b *ValueBinding) IsSolved() bool {
 
 var VB = NewValueBinding
 
+func (b *ValueBinding) assignId(gen *ids.Generator) {
+	if b != nil && b.Id == 0 {
+		b.Id = gen.Next()
+	}
+}
+
 type TypeBinding struct {
 	Id             uint64
 	DefinitionNode ast.Node
@@ -47,9 +54,7 @@ type TypeBinding struct {
 }
 
 func NewTypeBinding(t ast.Type, n ast.Node) *TypeBinding {
-	_bindingId++
 	return &TypeBinding{
-		Id:             _bindingId,
 		DefinitionNode: n,
 		Type:           t,
 	}
@@ -60,3 +65,9 @@ func (b *TypeBinding) IsSolved() bool {
 }
 
 var TB = NewTypeBinding
+
+func (b *TypeBinding) assignId(gen *ids.Generator) {
+	if b != nil && b.Id == 0 {
+		b.Id = gen.Next()
+	}
+}
@@ -1,8 +1,20 @@
 package env
 
-type scopeMap[T any] struct {
+import (
+	"slices"
+	"sort"
+
+	"github.com/renatopp/golden/internal/helpers/ids"
+)
+
+type binding interface {
+	assignId(gen *ids.Generator)
+}
+
+type scopeMap[T binding] struct {
 	Parent   *scopeMap[T]
 	Bindings map[string]T
+	ids      *ids.Generator
 }
 
 func (s *scopeMap[T]) Get(key string, or T) T {
@@ -23,9 +35,23 @@ func (s *scopeMap[T]) GetLocal(key string, or T) T {
 }
 
 func (s *scopeMap[T]) Set(key string, binding T) {
+	binding.assignId(s.ids)
 	s.Bindings[key] = binding
 }
 
+// Returns the names visible from the scope, including the ones declared in
+// the parent scopes.
+func (s *scopeMap[T]) Names() []string {
+	names := []string{}
+	for cur := s; cur != nil; cur = cur.Parent {
+		for name := range cur.Bindings {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
 func (s *scopeMap[T]) Clear() {
 	s.Bindings = map[string]T{}
 }
@@ -42,12 +68,14 @@ type Scope struct {
 	Values   *scopeMap[*ValueBinding]
 }
 
-func NewScope() *Scope {
+// Creates a root scope. All bindings declared in the scope and its children
+// are numbered by the generator.
+func NewScope(gen *ids.Generator) *Scope {
 	return &Scope{
 		Depth:  0,
 		Parent: nil,
-		Types:  &scopeMap[*TypeBinding]{Bindings: map[string]*TypeBinding{}},
-		Values: &scopeMap[*ValueBinding]{Bindings: map[string]*ValueBinding{}},
+		Types:  &scopeMap[*TypeBinding]{Bindings: map[string]*TypeBinding{}, ids: gen},
+		Values: &scopeMap[*ValueBinding]{Bindings: map[string]*ValueBinding{}, ids: gen},
 	}
 }
 
@@ -55,7 +83,7 @@ func (s *Scope) New() *Scope {
 	return &Scope{
 		Depth:  s.Depth + 1,
 		Parent: s,
-		Types:  &scopeMap[*TypeBinding]{Parent: s.Types, Bindings: map[string]*TypeBinding{}},
-		Values: &scopeMap[*ValueBinding]{Parent: s.Values, Bindings: map[string]*ValueBinding{}},
+		Types:  &scopeMap[*TypeBinding]{Parent: s.Types, Bindings: map[string]*TypeBinding{}, ids: s.Types.ids},
+		Values: &scopeMap[*ValueBinding]{Parent: s.Values, Bindings: map[string]*ValueBinding{}, ids: s.Values.ids},
 	}
 }
@@ -0,0 +1,322 @@
+package ir
+
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// Represents a lowered golden module. Every module-level variable becomes a
+// global, which is initialized by the module `init` function, and every
+// function, including the anonymous ones, becomes a flat function.
+type Module struct {
+	Name      string
+	Path      string
+	Globals   []*Global
+	Foreigns  []*Foreign
+	Functions []*Function
+	Init      *Function
+}
+
+func NewModule(name, path string) *Module {
+	return &Module{
+		Name:      name,
+		Path:      path,
+		Globals:   []*Global{},
+		Foreigns:  []*Foreign{},
+		Functions: []*Function{},
+	}
+}
+
+type Global struct {
+	Name string
+	Type ast.Type
+}
+
+// Function declared with `extern`, implemented by the host of the backend.
+// Bindings are in the `<target>:<name>` format.
+type Foreign struct {
+	Name     string
+	Type     ast.Type
+	Bindings []string
+	Node     *ast.ExternFnDecl
+}
+
+// Returns the binding of the target, without the target prefix.
+func (f *Foreign) Binding(target string) (string, bool) {
+	return f.Node.Binding(target)
+}
+
+// Functions are lists of basic blocks, the first block being the entry.
+// Captures are the values the function closes over, which are passed by the
+// enclosing function when the closure is created.
+type Function struct {
+	Name     string
+	Params   []*Param
+	Captures []*Param
+	Return   ast.Type
+	Type     ast.Type
+	Blocks   []*Block
+	Node     ast.Node
+
+	nextTemp  int
+	nextBlock int
+}
+
+func NewFunction(name string, tp ast.Type, ret ast.Type, node ast.Node) *Function {
+	return &Function{
+		Name:     name,
+		Params:   []*Param{},
+		Captures: []*Param{},
+		Return:   ret,
+		Type:     tp,
+		Blocks:   []*Block{},
+		Node:     node,
+	}
+}
+
+func (f *Function) Entry() *Block {
+	return f.Blocks[0]
+}
+
+func (f *Function) NewBlock() *Block {
+	b := &Block{Id: f.nextBlock, Instrs: []Instr{}}
+	f.nextBlock++
+	f.Blocks = append(f.Blocks, b)
+	return b
+}
+
+func (f *Function) NewTemp(tp ast.Type) *Temp {
+	t := &Temp{Id: f.nextTemp, Tp: tp}
+	f.nextTemp++
+	return t
+}
+
+// Basic blocks are straight sequences of instructions finished by a single
+// terminator.
+type Block struct {
+	Id     int
+	Instrs []Instr
+	Term   Terminator
+}
+
+func (b *Block) Add(instr Instr) {
+	b.Instrs = append(b.Instrs, instr)
+}
+
+func (b *Block) IsTerminated() bool {
+	return b.Term != nil
+}
+
+func (b *Block) Successors() []*Block {
+	switch t := b.Term.(type) {
+	case *Jump:
+		return []*Block{t.Target}
+	case *Branch:
+		return []*Block{t.Then, t.Else}
+	}
+	return []*Block{}
+}
+
+// Values ---------------------------------------------------------------------
+
+type Value interface {
+	Type() ast.Type
+	String() string
+}
+
+type Const struct {
+	Tp    ast.Type
+	Value any // int64, float64, string or bool
+}
+
+func (v *Const) Type() ast.Type { return v.Tp }
+
+type Temp struct {
+	Id int
+	Tp ast.Type
+}
+
+func (v *Temp) Type() ast.Type { return v.Tp }
+
+type Param struct {
+	Name string
+	Tp   ast.Type
+}
+
+func (v *Param) Type() ast.Type { return v.Tp }
+
+type GlobalRef struct {
+	Global *Global
+}
+
+func (v *GlobalRef) Type() ast.Type { return v.Global.Type }
+
+// Reference to a function. Inside the function itself, it refers to the
+// running closure, with the same captures.
+type FuncRef struct {
+	Function *Function
+}
+
+func (v *FuncRef) Type() ast.Type { return v.Function.Type }
+
+// Reference to a declaration of another module, which is lowered separately.
+type ExternRef struct {
+	Module string // Alias of the module in the referencing module
+	Path   string // Absolute path of the module
+	Name   string
+	Tp     ast.Type
+}
+
+func (v *ExternRef) Type() ast.Type { return v.Tp }
+
+type ForeignRef struct {
+	Foreign *Foreign
+}
+
+func (v *ForeignRef) Type() ast.Type { return v.Foreign.Type }
+
+// Reference to a primitive implemented by the runtime of the backends, see
+// the `builtins` package.
+type BuiltinRef struct {
+	Name string
+	Tp   ast.Type
+}
+
+func (v *BuiltinRef) Type() ast.Type { return v.Tp }
+
+// Reference to a value declared by the host program in the global scope, see
+// `backend.Host`.
+type HostRef struct {
+	Name string
+	Tp   ast.Type
+}
+
+func (v *HostRef) Type() ast.Type { return v.Tp }
+
+// Instructions ---------------------------------------------------------------
+
+type Instr interface {
+	Operands() []Value
+	String() string
+}
+
+// Instructions that produce a value.
+type Definition interface {
+	Instr
+	Dest() *Temp
+}
+
+type BinOp struct {
+	Dst   *Temp
+	Op    string
+	Left  Value
+	Right Value
+}
+
+func (i *BinOp) Dest() *Temp       { return i.Dst }
+func (i *BinOp) Operands() []Value { return []Value{i.Left, i.Right} }
+
+type UnaryOp struct {
+	Dst     *Temp
+	Op      string
+	Operand Value
+}
+
+func (i *UnaryOp) Dest() *Temp       { return i.Dst }
+func (i *UnaryOp) Operands() []Value { return []Value{i.Operand} }
+
+// Calls returning `Void` have no destination.
+type Call struct {
+	Dst    *Temp
+	Target Value
+	Args   []Value
+}
+
+func (i *Call) Dest() *Temp       { return i.Dst }
+func (i *Call) Operands() []Value { return append([]Value{i.Target}, i.Args...) }
+
+type Load struct {
+	Dst    *Temp
+	Global *GlobalRef
+}
+
+func (i *Load) Dest() *Temp       { return i.Dst }
+func (i *Load) Operands() []Value { return []Value{i.Global} }
+
+type Store struct {
+	Global *GlobalRef
+	Value  Value
+}
+
+func (i *Store) Operands() []Value { return []Value{i.Global, i.Value} }
+
+// Creates a function value binding the captured values of the enclosing
+// function.
+type Closure struct {
+	Dst      *Temp
+	Function *FuncRef
+	Captures []Value
+}
+
+func (i *Closure) Dest() *Temp       { return i.Dst }
+func (i *Closure) Operands() []Value { return append([]Value{i.Function}, i.Captures...) }
+
+type PhiEdge struct {
+	Block *Block
+	Value Value
+}
+
+type Phi struct {
+	Dst   *Temp
+	Edges []PhiEdge
+}
+
+func (i *Phi) Dest() *Temp { return i.Dst }
+func (i *Phi) Operands() []Value {
+	res := []Value{}
+	for _, e := range i.Edges {
+		res = append(res, e.Value)
+	}
+	return res
+}
+
+// Terminators ----------------------------------------------------------------
+
+type Terminator interface {
+	Instr
+	isTerminator()
+}
+
+type Return struct {
+	Value Value // nil for `Void` functions
+}
+
+func (i *Return) isTerminator() {}
+func (i *Return) Operands() []Value {
+	if i.Value == nil {
+		return []Value{}
+	}
+	return []Value{i.Value}
+}
+
+type Jump struct {
+	Target *Block
+}
+
+func (i *Jump) isTerminator()     {}
+func (i *Jump) Operands() []Value { return []Value{} }
+
+type Branch struct {
+	Cond Value
+	Then *Block
+	Else *Block
+}
+
+func (i *Branch) isTerminator()     {}
+func (i *Branch) Operands() []Value { return []Value{i.Cond} }
+
+// Marks blocks that can never be reached, such as the code following a
+// `return`.
+type Unreachable struct{}
+
+func (i *Unreachable) isTerminator()     {}
+func (i *Unreachable) Operands() []Value { return []Value{} }
@@ -0,0 +1,508 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+var _ ast.Visitor = &Lowerer{}
+
+var binOpNames = map[string]string{
+	"+":   "add",
+	"-":   "sub",
+	"*":   "mul",
+	"/":   "div",
+	"%":   "rem",
+	"==":  "eq",
+	"!=":  "ne",
+	"<":   "lt",
+	"<=":  "le",
+	">":   "gt",
+	">=":  "ge",
+	"<=>": "cmp",
+	"xor": "xor",
+}
+
+var unaryOpNames = map[string]string{
+	"-": "neg",
+	"!": "not",
+}
+
+// Tracks the function being lowered and its lexical scopes. Names that are
+// not found in the function are looked up in the enclosing function and
+// captured.
+type frame struct {
+	parent   *frame
+	fn       *Function
+	block    *Block
+	scopes   []map[string]Value
+	captures map[string]Value // values captured from the parent frame
+	lambdas  int
+}
+
+func (f *frame) lookup(name string) Value {
+	for i := len(f.scopes) - 1; i >= 0; i-- {
+		if v, ok := f.scopes[i][name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (f *frame) bind(name string, v Value) {
+	f.scopes[len(f.scopes)-1][name] = v
+}
+
+// Lowerer converts a checked module into its IR representation. Module-level
+// names are resolved first, so declarations may be used in any order.
+type Lowerer struct {
+	module   *Module
+	globals  map[string]*Global
+	foreigns map[string]*Foreign
+	funcs    map[string]*Function
+	host     *env.Scope // Global scope, with the values of the host program
+	frame    *frame
+	stack    []Value
+}
+
+func NewLowerer(name, path string, host *env.Scope) *Lowerer {
+	return &Lowerer{
+		host:     host,
+		module:   NewModule(name, path),
+		globals:  map[string]*Global{},
+		foreigns: map[string]*Foreign{},
+		funcs:    map[string]*Function{},
+		stack:    []Value{},
+	}
+}
+
+// Lowers the checked module into IR and verifies the result. The global scope
+// may be nil when the program does not use values of the host.
+func Lower(name, path string, root *ast.Module, global *env.Scope) (res *Module, err error) {
+	err = errors.WithRecovery(func() {
+		l := NewLowerer(name, path, global)
+		root.Visit(l)
+		res = l.module
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, Verify(res)
+}
+
+func (l *Lowerer) push(v Value) { l.stack = append(l.stack, v) }
+func (l *Lowerer) pop() Value {
+	v := l.stack[len(l.stack)-1]
+	l.stack = l.stack[:len(l.stack)-1]
+	return v
+}
+
+// Lowers the expression and returns its value, `nil` for `Void` expressions.
+func (l *Lowerer) value(node ast.Node) Value {
+	node.Visit(l)
+	return l.pop()
+}
+
+// Returns the block being lowered. Code following a terminator, such as the
+// expressions after a `return`, goes to a new block without predecessors.
+func (l *Lowerer) current() *Block {
+	if l.frame.block.IsTerminated() {
+		l.frame.block = l.frame.fn.NewBlock()
+	}
+	return l.frame.block
+}
+
+func (l *Lowerer) emit(instr Instr) {
+	l.current().Add(instr)
+}
+
+func (l *Lowerer) terminate(term Terminator) {
+	l.current().Term = term
+}
+
+func (l *Lowerer) startBlock(b *Block) {
+	l.frame.block = b
+}
+
+func (l *Lowerer) pushFrame(fn *Function) {
+	l.frame = &frame{
+		parent:   l.frame,
+		fn:       fn,
+		scopes:   []map[string]Value{{}},
+		captures: map[string]Value{},
+	}
+	l.frame.block = fn.NewBlock()
+}
+
+func (l *Lowerer) popFrame() *frame {
+	f := l.frame
+	l.frame = f.parent
+	return f
+}
+
+func (l *Lowerer) typeOf(node ast.Node) ast.Type {
+	tp := node.GetType()
+	if !tp.Has() {
+		errors.ThrowAtNode(node, errors.InternalError, "node has no type, modules must be checked before lowering")
+	}
+	return tp.Unwrap()
+}
+
+func (l *Lowerer) resolve(node *ast.VarIdent) Value {
+	if v := l.capture(l.frame, node.Value); v != nil {
+		return v
+	}
+
+	if g, ok := l.globals[node.Value]; ok {
+		dst := l.frame.fn.NewTemp(g.Type)
+		l.emit(&Load{Dst: dst, Global: &GlobalRef{g}})
+		return dst
+	}
+
+	if fn, ok := l.funcs[node.Value]; ok {
+		return &FuncRef{fn}
+	}
+
+	if f, ok := l.foreigns[node.Value]; ok {
+		return &ForeignRef{f}
+	}
+
+	if b, ok := builtins.Lookup(node.Value); ok {
+		return &BuiltinRef{Name: b.Name, Tp: l.typeOf(node)}
+	}
+
+	if l.host != nil && l.host.Values.GetLocal(node.Value, nil) != nil {
+		return &HostRef{Name: node.Value, Tp: l.typeOf(node)}
+	}
+
+	errors.ThrowAtNode(node, errors.NotImplemented, "name '%s' cannot be lowered to IR", node.Value)
+	return nil
+}
+
+// Looks up the name in the frame, capturing it from the enclosing frames if
+// needed.
+func (l *Lowerer) capture(f *frame, name string) Value {
+	if f == nil {
+		return nil
+	}
+	if v := f.lookup(name); v != nil {
+		return v
+	}
+
+	outer := l.capture(f.parent, name)
+	if outer == nil {
+		return nil
+	}
+
+	param := &Param{Name: name, Tp: outer.Type()}
+	f.fn.Captures = append(f.fn.Captures, param)
+	f.captures[name] = outer
+	f.scopes[0][name] = param
+	return param
+}
+
+// Finishes the current block of the function with an implicit return.
+func (l *Lowerer) finishFunction(value Value) {
+	if l.frame.block.IsTerminated() {
+		return
+	}
+
+	fn := l.frame.fn
+	switch {
+	case types.Void.IsCompatible(fn.Return):
+		l.terminate(&Return{})
+	case value != nil:
+		l.terminate(&Return{Value: value})
+	default:
+		l.terminate(&Unreachable{})
+	}
+}
+
+func (l *Lowerer) lowerFunction(fn *Function, node *ast.FnDecl) {
+	l.pushFrame(fn)
+	if node.Name.Has() {
+		// bound before the body, so nested functions may call themselves
+		l.frame.bind(node.Name.Unwrap().Value, &FuncRef{fn})
+	}
+	for _, p := range node.Params {
+		param := &Param{Name: p.Name.Value, Tp: l.typeOf(p)}
+		fn.Params = append(fn.Params, param)
+		l.frame.bind(param.Name, param)
+	}
+	l.finishFunction(l.value(node.ValueExpr))
+}
+
+// Module-level variables are initialized in dependency order by the module
+// `init` function.
+func (l *Lowerer) lowerInit(decls []*ast.VarDecl, fns map[string]*ast.FnDecl) {
+	init := NewFunction("$init", types.NoopFn, types.Void, nil)
+	l.module.Init = init
+	l.module.Functions = append(l.module.Functions, init)
+
+	l.pushFrame(init)
+	defer l.popFrame()
+	for _, decl := range initializationOrder(decls, fns) {
+		value := l.value(decl.ValueExpr)
+		l.emit(&Store{Global: &GlobalRef{l.globals[decl.Name.Value]}, Value: value})
+	}
+	l.finishFunction(nil)
+}
+
+// Visitor --------------------------------------------------------------------
+
+func (l *Lowerer) VisitModule(node *ast.Module) ast.Node {
+	decls := []*ast.VarDecl{}
+	fns := map[string]*ast.FnDecl{}
+	order := []*ast.FnDecl{}
+
+	for _, e := range node.Exprs {
+		switch n := e.(type) {
+		case *ast.VarDecl:
+			g := &Global{Name: n.Name.Value, Type: l.typeOf(n)}
+			l.globals[g.Name] = g
+			l.module.Globals = append(l.module.Globals, g)
+			decls = append(decls, n)
+
+		case *ast.FnDecl:
+			name := n.Name.Unwrap().Value
+			tp := l.typeOf(n)
+			fn := NewFunction(name, tp, tp.(*types.Function).Return, n)
+			l.funcs[name] = fn
+			l.module.Functions = append(l.module.Functions, fn)
+			fns[name] = n
+			order = append(order, n)
+
+		case *ast.ExternFnDecl:
+			f := &Foreign{Name: n.Name.Value, Type: l.typeOf(n), Bindings: []string{}, Node: n}
+			for _, b := range n.Bindings {
+				f.Bindings = append(f.Bindings, b.Literal)
+			}
+			l.foreigns[f.Name] = f
+			l.module.Foreigns = append(l.module.Foreigns, f)
+		}
+	}
+
+	for _, n := range order {
+		l.lowerFunction(l.funcs[n.Name.Unwrap().Value], n)
+		l.popFrame()
+	}
+	l.lowerInit(decls, fns)
+	return node
+}
+
+// Extern functions are registered by the module, they have no body to lower.
+func (l *Lowerer) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	errors.ThrowAtNode(node, errors.InternalError, "extern functions must be declared in module scope")
+	return node
+}
+
+func (l *Lowerer) VisitVarDecl(node *ast.VarDecl) ast.Node {
+	value := l.value(node.ValueExpr)
+	l.frame.bind(node.Name.Value, value)
+	l.push(nil)
+	return node
+}
+
+func (l *Lowerer) VisitInt(node *ast.Int) ast.Node {
+	l.push(&Const{Tp: l.typeOf(node), Value: node.Value})
+	return node
+}
+
+func (l *Lowerer) VisitFloat(node *ast.Float) ast.Node {
+	l.push(&Const{Tp: l.typeOf(node), Value: node.Value})
+	return node
+}
+
+func (l *Lowerer) VisitString(node *ast.String) ast.Node {
+	l.push(&Const{Tp: l.typeOf(node), Value: node.Value})
+	return node
+}
+
+func (l *Lowerer) VisitBool(node *ast.Bool) ast.Node {
+	l.push(&Const{Tp: l.typeOf(node), Value: node.Value})
+	return node
+}
+
+func (l *Lowerer) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	l.push(l.resolve(node))
+	return node
+}
+
+func (l *Lowerer) VisitAccess(node *ast.Access) ast.Node {
+	target, ok := node.Target.(*ast.VarIdent)
+	mod, isModule := l.typeOf(node.Target).(*types.Module)
+	if !ok || !isModule {
+		errors.ThrowAtNode(node, errors.NotImplemented, "only module members can be accessed in IR")
+	}
+	l.push(&ExternRef{Module: target.Value, Path: mod.Path, Name: node.Name.Value, Tp: l.typeOf(node)})
+	return node
+}
+
+func (l *Lowerer) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
+	errors.ThrowAtNode(node, errors.InternalError, "type expressions cannot be lowered to IR")
+	return node
+}
+
+func (l *Lowerer) VisitBinOp(node *ast.BinOp) ast.Node {
+	if node.Op == "and" || node.Op == "or" {
+		l.lowerShortCircuit(node)
+		return node
+	}
+
+	op, ok := binOpNames[node.Op]
+	if !ok {
+		errors.ThrowAtNode(node, errors.NotImplemented, "binary operator '%s' cannot be lowered to IR", node.Op)
+	}
+
+	left := l.value(node.LeftExpr)
+	right := l.value(node.RightExpr)
+	dst := l.frame.fn.NewTemp(l.typeOf(node))
+	l.emit(&BinOp{Dst: dst, Op: op, Left: left, Right: right})
+	l.push(dst)
+	return node
+}
+
+// `a and b` and `a or b` only evaluate `b` when needed:
+//
+//	  br %a, rhs, end   (or: br %a, end, rhs)
+//	rhs:
+//	  jmp end
+//	end:
+//	  %r = phi [entry: %a], [rhs: %b]
+func (l *Lowerer) lowerShortCircuit(node *ast.BinOp) {
+	fn := l.frame.fn
+	left := l.value(node.LeftExpr)
+	from := l.current()
+
+	rhs := fn.NewBlock()
+	end := fn.NewBlock()
+	if node.Op == "and" {
+		l.terminate(&Branch{Cond: left, Then: rhs, Else: end})
+	} else {
+		l.terminate(&Branch{Cond: left, Then: end, Else: rhs})
+	}
+
+	l.startBlock(rhs)
+	right := l.value(node.RightExpr)
+	rhsEnd := l.current()
+	l.terminate(&Jump{Target: end})
+
+	l.startBlock(end)
+	dst := fn.NewTemp(l.typeOf(node))
+	l.emit(&Phi{Dst: dst, Edges: []PhiEdge{{from, left}, {rhsEnd, right}}})
+	l.push(dst)
+}
+
+func (l *Lowerer) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
+	operand := l.value(node.RightExpr)
+	if node.Op == "+" {
+		l.push(operand)
+		return node
+	}
+
+	op, ok := unaryOpNames[node.Op]
+	if !ok {
+		errors.ThrowAtNode(node, errors.NotImplemented, "unary operator '%s' cannot be lowered to IR", node.Op)
+	}
+
+	dst := l.frame.fn.NewTemp(l.typeOf(node))
+	l.emit(&UnaryOp{Dst: dst, Op: op, Operand: operand})
+	l.push(dst)
+	return node
+}
+
+func (l *Lowerer) VisitBlock(node *ast.Block) ast.Node {
+	f := l.frame
+	f.scopes = append(f.scopes, map[string]Value{})
+	defer func() { f.scopes = f.scopes[:len(f.scopes)-1] }()
+
+	var last Value
+	for _, e := range node.Exprs {
+		last = l.value(e)
+	}
+
+	if len(node.Exprs) == 0 || types.Void.IsCompatible(l.typeOf(node)) {
+		last = nil
+	}
+	l.push(last)
+	return node
+}
+
+func (l *Lowerer) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	parent := l.frame
+	parent.lambdas++
+
+	name := fmt.Sprintf("%s$%d", parent.fn.Name, parent.lambdas)
+	if node.Name.Has() {
+		name = fmt.Sprintf("%s$%s", parent.fn.Name, node.Name.Unwrap().Value)
+	}
+
+	tp := l.typeOf(node)
+	fn := NewFunction(name, tp, tp.(*types.Function).Return, node)
+	l.module.Functions = append(l.module.Functions, fn)
+	l.lowerFunction(fn, node)
+	inner := l.popFrame()
+
+	var value Value = &FuncRef{fn}
+	if len(fn.Captures) > 0 {
+		caps := []Value{}
+		for _, c := range fn.Captures {
+			caps = append(caps, inner.captures[c.Name])
+		}
+		dst := parent.fn.NewTemp(tp)
+		l.emit(&Closure{Dst: dst, Function: &FuncRef{fn}, Captures: caps})
+		value = dst
+	}
+
+	if node.Name.Has() {
+		parent.bind(node.Name.Unwrap().Value, value)
+	}
+	l.push(value)
+	return node
+}
+
+func (l *Lowerer) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
+	errors.ThrowAtNode(node, errors.InternalError, "parameters are lowered with their functions")
+	return node
+}
+
+func (l *Lowerer) VisitTypeFn(node *ast.TypeFn) ast.Node {
+	errors.ThrowAtNode(node, errors.InternalError, "type expressions cannot be lowered to IR")
+	return node
+}
+
+func (l *Lowerer) VisitApplication(node *ast.Application) ast.Node {
+	target := l.value(node.Target)
+	args := []Value{}
+	for _, a := range node.Args {
+		args = append(args, l.value(a))
+	}
+
+	call := &Call{Target: target, Args: args}
+	tp := l.typeOf(node)
+	if types.Void.IsCompatible(tp) {
+		// void calls have no value, and a nil *Temp would not be a nil Value
+		l.emit(call)
+		l.push(nil)
+		return node
+	}
+	call.Dst = l.frame.fn.NewTemp(tp)
+	l.emit(call)
+	l.push(call.Dst)
+	return node
+}
+
+func (l *Lowerer) VisitReturn(node *ast.Return) ast.Node {
+	ret := &Return{}
+	if node.ValueExpr.Has() {
+		ret.Value = l.value(node.ValueExpr.Unwrap())
+	}
+	l.terminate(ret)
+	l.push(nil)
+	return node
+}
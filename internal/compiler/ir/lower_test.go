@@ -0,0 +1,155 @@
+package ir_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Lowers the source as the entry of a project, without optimizations.
+func lower(t *testing.T, source string) *ir.Module {
+	dir := buildertest.Project(t, map[string]string{"main.gold": source})
+	entry := filepath.Join(dir, "main.gold")
+	opts := buildertest.Options(t, entry)
+	opts.NoCache = true
+	opts.OptimizationLevel = optimizations.LevelNone
+
+	var res *ir.Module
+	opts.OnIRReady.Subscribe(func(f *builder.File, mod *ir.Module) {
+		if f.Path == entry {
+			res = mod
+		}
+	})
+	_, err := builder.NewBuilder(opts).Lower()
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	return res
+}
+
+// Returns the textual representation of the function of the module.
+func dump(t *testing.T, mod *ir.Module, name string) string {
+	for _, f := range mod.Functions {
+		if f.Name == name {
+			sb := &strings.Builder{}
+			ir.DumpFunction(sb, f)
+			return sb.String()
+		}
+	}
+	require.Failf(t, "function not found", "function '%s' not found", name)
+	return ""
+}
+
+func TestLower(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:   "arithmetic",
+			source: "fn f(a Int, b Int) Int { a + b * 2 }",
+			expected: `fn @f(%a Int, %b Int) Int {
+b0:
+  %0 = mul Int %b, 2
+  %1 = add Int %a, %0
+  ret %1
+}
+`,
+		},
+		{
+			name:   "and",
+			source: "fn f(a Bool, b Bool) Bool { a and b }",
+			expected: `fn @f(%a Bool, %b Bool) Bool {
+b0:
+  br %a, b1, b2
+b1:
+  jmp b2
+b2:
+  %0 = phi Bool [b0: %a], [b1: %b]
+  ret %0
+}
+`,
+		},
+		{
+			name:   "or",
+			source: "fn f(a Bool, b Bool) Bool { a or b }",
+			expected: `fn @f(%a Bool, %b Bool) Bool {
+b0:
+  br %a, b2, b1
+b1:
+  jmp b2
+b2:
+  %0 = phi Bool [b0: %a], [b1: %b]
+  ret %0
+}
+`,
+		},
+		{
+			name:   "nested and",
+			source: "fn f(a Bool, b Bool, c Bool) Bool { a and b and c }",
+			expected: `fn @f(%a Bool, %b Bool, %c Bool) Bool {
+b0:
+  br %a, b1, b2
+b1:
+  jmp b2
+b2:
+  %0 = phi Bool [b0: %a], [b1: %b]
+  br %0, b3, b4
+b3:
+  jmp b4
+b4:
+  %1 = phi Bool [b2: %0], [b3: %c]
+  ret %1
+}
+`,
+		},
+		{
+			name:   "void call",
+			source: "fn g() { println(\"hi\") }\nfn f() { return g() }",
+			expected: `fn @f() Void {
+b0:
+  call @g()
+  ret
+}
+`,
+		},
+		{
+			name:   "closure",
+			source: "fn f(n Int) Int {\n  let add = fn (x Int) Int { x + n }\n  add(1)\n}",
+			expected: `fn @f(%n Int) Int {
+b0:
+  %0 = closure @f$1 [%n]
+  %1 = call Int %0(1)
+  ret %1
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod := lower(t, tt.source+"\nfn main() {}\n")
+			assert.Equal(t, tt.expected, dump(t, mod, "f"))
+			assert.NoError(t, ir.Verify(mod))
+		})
+	}
+}
+
+func TestLowerRecursiveClosure(t *testing.T) {
+	mod := lower(t, "fn f(n Int) Int {\n  fn g(x Int) Int { g(x + n) }\n  g(1)\n}\nfn main() {}\n")
+	assert.Equal(t, `fn @f$g(%x Int) [%n Int] Int {
+b0:
+  %0 = add Int %x, %n
+  %1 = call Int @f$g(%0)
+  ret %1
+}
+`, dump(t, mod, "f$g"))
+	assert.NoError(t, ir.Verify(mod))
+}
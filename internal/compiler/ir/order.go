@@ -0,0 +1,61 @@
+package ir
+
+import "github.com/renatopp/golden/internal/compiler/ast"
+
+// Sorts the module variables so every variable is initialized after the ones
+// its initializer depends on, directly or through the functions it calls.
+func initializationOrder(decls []*ast.VarDecl, fns map[string]*ast.FnDecl) []*ast.VarDecl {
+	byName := map[string]*ast.VarDecl{}
+	for _, d := range decls {
+		byName[d.Name.Value] = d
+	}
+
+	order := []*ast.VarDecl{}
+	visited := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		var deps []string
+		if d, ok := byName[name]; ok {
+			deps = referencedNames(d.ValueExpr)
+		} else if fn, ok := fns[name]; ok {
+			deps = referencedNames(fn.ValueExpr)
+		} else {
+			return
+		}
+
+		for _, dep := range deps {
+			visit(dep)
+		}
+		if d, ok := byName[name]; ok {
+			order = append(order, d)
+		}
+	}
+
+	for _, d := range decls {
+		visit(d.Name.Value)
+	}
+	return order
+}
+
+// Collects the names of all identifiers used in the expression.
+func referencedNames(node ast.Node) []string {
+	c := &nameCollector{names: []string{}}
+	c.Visiter = ast.NewVisiter(c)
+	node.Visit(c)
+	return c.names
+}
+
+type nameCollector struct {
+	*ast.Visiter
+	names []string
+}
+
+func (c *nameCollector) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	c.names = append(c.names, node.Value)
+	return node
+}
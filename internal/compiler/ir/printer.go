@@ -0,0 +1,137 @@
+package ir
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/renatopp/golden/internal/helpers/codegen"
+)
+
+func (v *Const) String() string {
+	switch val := v.Value.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case string:
+		return strconv.Quote(val)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	return fmt.Sprintf("%v", v.Value)
+}
+
+func (v *Temp) String() string       { return fmt.Sprintf("%%%d", v.Id) }
+func (v *Param) String() string      { return "%" + v.Name }
+func (v *GlobalRef) String() string  { return "@" + v.Global.Name }
+func (v *FuncRef) String() string    { return "@" + v.Function.Name }
+func (v *ExternRef) String() string  { return "@" + v.Module + "." + v.Name }
+func (v *ForeignRef) String() string { return "@" + v.Foreign.Name }
+func (v *BuiltinRef) String() string { return "@" + v.Name }
+func (v *HostRef) String() string    { return "@" + v.Name }
+
+func (b *Block) String() string { return fmt.Sprintf("b%d", b.Id) }
+
+func (i *BinOp) String() string {
+	return fmt.Sprintf("%s = %s %s %s, %s", i.Dst, i.Op, sig(i.Dst), i.Left, i.Right)
+}
+
+func (i *UnaryOp) String() string {
+	return fmt.Sprintf("%s = %s %s %s", i.Dst, i.Op, sig(i.Dst), i.Operand)
+}
+
+func (i *Call) String() string {
+	args := codegen.JoinList(", ", i.Args, func(v Value) string { return v.String() })
+	if i.Dst == nil {
+		return fmt.Sprintf("call %s(%s)", i.Target, args)
+	}
+	return fmt.Sprintf("%s = call %s %s(%s)", i.Dst, sig(i.Dst), i.Target, args)
+}
+
+func (i *Load) String() string {
+	return fmt.Sprintf("%s = load %s %s", i.Dst, sig(i.Dst), i.Global)
+}
+
+func (i *Store) String() string {
+	return fmt.Sprintf("store %s, %s", i.Global, i.Value)
+}
+
+func (i *Closure) String() string {
+	caps := codegen.JoinList(", ", i.Captures, func(v Value) string { return v.String() })
+	return fmt.Sprintf("%s = closure %s [%s]", i.Dst, i.Function, caps)
+}
+
+func (i *Phi) String() string {
+	edges := codegen.JoinList(", ", i.Edges, func(e PhiEdge) string {
+		return fmt.Sprintf("[%s: %s]", e.Block, e.Value)
+	})
+	return fmt.Sprintf("%s = phi %s %s", i.Dst, sig(i.Dst), edges)
+}
+
+func (i *Return) String() string {
+	if i.Value == nil {
+		return "ret"
+	}
+	return fmt.Sprintf("ret %s", i.Value)
+}
+
+func (i *Jump) String() string   { return fmt.Sprintf("jmp %s", i.Target) }
+func (i *Branch) String() string { return fmt.Sprintf("br %s, %s, %s", i.Cond, i.Then, i.Else) }
+
+func (i *Unreachable) String() string { return "unreachable" }
+
+// Returns the textual representation of the module.
+func Dump(m *Module) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "module %s -- %s\n", m.Name, m.Path)
+
+	if len(m.Globals) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, g := range m.Globals {
+		fmt.Fprintf(sb, "global @%s %s\n", g.Name, g.Type.GetSignature())
+	}
+
+	if len(m.Foreigns) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, f := range m.Foreigns {
+		bindings := codegen.JoinList(", ", f.Bindings, strconv.Quote)
+		fmt.Fprintf(sb, "extern @%s %s = %s\n", f.Name, f.Type.GetSignature(), bindings)
+	}
+
+	for _, f := range m.Functions {
+		sb.WriteString("\n")
+		DumpFunction(sb, f)
+	}
+	return sb.String()
+}
+
+func DumpFunction(sb *strings.Builder, f *Function) {
+	params := codegen.JoinList(", ", f.Params, func(p *Param) string {
+		return fmt.Sprintf("%s %s", p, p.Tp.GetSignature())
+	})
+	caps := ""
+	if len(f.Captures) > 0 {
+		caps = " [" + codegen.JoinList(", ", f.Captures, func(p *Param) string {
+			return fmt.Sprintf("%s %s", p, p.Tp.GetSignature())
+		}) + "]"
+	}
+
+	fmt.Fprintf(sb, "fn @%s(%s)%s %s {\n", f.Name, params, caps, f.Return.GetSignature())
+	for _, b := range f.Blocks {
+		fmt.Fprintf(sb, "%s:\n", b)
+		for _, i := range b.Instrs {
+			fmt.Fprintf(sb, "  %s\n", i)
+		}
+		if b.Term != nil {
+			fmt.Fprintf(sb, "  %s\n", b.Term)
+		}
+	}
+	sb.WriteString("}\n")
+}
+
+func sig(v Value) string {
+	return v.Type().GetSignature()
+}
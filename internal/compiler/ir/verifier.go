@@ -0,0 +1,285 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// Checks the structural invariants of the module:
+//
+// - every block ends with a single terminator and branches stay inside the
+// function;
+// - every temporary is defined once, before its uses, in a block dominating
+// them;
+// - phis only appear at the start of blocks and refer to predecessors;
+// - operands, returns, calls and stores are consistent with their types.
+func Verify(m *Module) error {
+	v := &verifier{module: m, problems: []string{}}
+	for _, f := range m.Functions {
+		v.function(f)
+	}
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errors.NewError(errors.InternalError, "invalid IR for module '%s':\n- %s", m.Name, strings.Join(v.problems, "\n- "))
+}
+
+type verifier struct {
+	module   *Module
+	fn       *Function
+	problems []string
+}
+
+func (v *verifier) fail(msg string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf("@%s: ", v.fn.Name)+fmt.Sprintf(msg, args...))
+}
+
+func (v *verifier) function(f *Function) {
+	v.fn = f
+	if len(f.Blocks) == 0 {
+		v.fail("function has no blocks")
+		return
+	}
+
+	owned := map[*Block]bool{}
+	for _, b := range f.Blocks {
+		owned[b] = true
+	}
+
+	// structure
+	preds := map[*Block][]*Block{}
+	for _, b := range f.Blocks {
+		if b.Term == nil {
+			v.fail("block %s is not terminated", b)
+			continue
+		}
+		for _, s := range b.Successors() {
+			if !owned[s] {
+				v.fail("block %s jumps to a block outside the function", b)
+				continue
+			}
+			preds[s] = append(preds[s], b)
+		}
+	}
+	if len(v.problems) > 0 {
+		return
+	}
+
+	// definitions
+	defs := map[*Temp]*Block{}
+	position := map[*Temp]int{}
+	for _, b := range f.Blocks {
+		for i, instr := range b.Instrs {
+			def, ok := instr.(Definition)
+			if !ok || def.Dest() == nil {
+				continue
+			}
+			dst := def.Dest()
+			if _, ok := defs[dst]; ok {
+				v.fail("%s is defined more than once", dst)
+			}
+			if dst.Tp == nil {
+				v.fail("%s has no type", dst)
+			}
+			defs[dst] = b
+			position[dst] = i
+		}
+	}
+
+	params := map[*Param]bool{}
+	for _, p := range f.Params {
+		params[p] = true
+	}
+	for _, p := range f.Captures {
+		params[p] = true
+	}
+
+	dom := dominators(f, preds)
+	dominates := func(a, b *Block) bool {
+		if a == b {
+			return true
+		}
+		return dom[b][a]
+	}
+
+	// checks a value used in the block at the given position
+	use := func(b *Block, pos int, val Value) {
+		switch val := val.(type) {
+		case nil:
+			v.fail("missing operand in block %s", b)
+		case *Temp:
+			db, ok := defs[val]
+			if !ok {
+				v.fail("%s is used but never defined", val)
+				return
+			}
+			if db == b && position[val] >= pos || db != b && !dominates(db, b) {
+				v.fail("%s is used in %s before its definition", val, b)
+			}
+		case *Param:
+			if !params[val] {
+				v.fail("%s does not belong to the function", val)
+			}
+		}
+	}
+
+	for _, b := range f.Blocks {
+		for i, instr := range b.Instrs {
+			if phi, ok := instr.(*Phi); ok {
+				v.phi(b, i, phi, preds[b], defs, dominates)
+				continue
+			}
+			for _, op := range instr.Operands() {
+				use(b, i, op)
+			}
+			v.types(instr)
+		}
+		for _, op := range b.Term.Operands() {
+			use(b, len(b.Instrs), op)
+		}
+		v.types(b.Term)
+	}
+}
+
+func (v *verifier) phi(b *Block, pos int, phi *Phi, preds []*Block, defs map[*Temp]*Block, dominates func(a, b *Block) bool) {
+	for _, prev := range b.Instrs[:pos] {
+		if _, ok := prev.(*Phi); !ok {
+			v.fail("phi %s is not at the start of block %s", phi.Dst, b)
+			break
+		}
+	}
+
+	for _, e := range phi.Edges {
+		isPred := false
+		for _, p := range preds {
+			isPred = isPred || p == e.Block
+		}
+		if !isPred {
+			v.fail("phi %s refers to %s, which is not a predecessor of %s", phi.Dst, e.Block, b)
+		}
+		if t, ok := e.Value.(*Temp); ok {
+			if db, ok := defs[t]; !ok || !dominates(db, e.Block) {
+				v.fail("phi %s uses %s, which is not available in %s", phi.Dst, t, e.Block)
+			}
+		}
+		v.compatible(phi.Dst.Tp, e.Value, "phi %s", phi.Dst)
+	}
+}
+
+func (v *verifier) types(instr Instr) {
+	switch i := instr.(type) {
+	case *BinOp:
+		switch i.Op {
+		case "eq", "ne":
+		default:
+			v.compatible(i.Left.Type(), i.Right, "%s", i.Op)
+		}
+
+	case *Branch:
+		v.compatible(types.Bool, i.Cond, "branch condition")
+
+	case *Return:
+		isVoid := types.Void.IsCompatible(v.fn.Return)
+		switch {
+		case i.Value == nil && !isVoid:
+			v.fail("missing return value of type '%s'", v.fn.Return.GetSignature())
+		case i.Value != nil && !isVoid:
+			v.compatible(v.fn.Return, i.Value, "return")
+		}
+
+	case *Call:
+		fn, ok := i.Target.Type().(*types.Function)
+		if !ok {
+			v.fail("call target %s is not a function", i.Target)
+			return
+		}
+		if len(fn.Params) != len(i.Args) {
+			v.fail("call to %s expects %d arguments, got %d", i.Target, len(fn.Params), len(i.Args))
+			return
+		}
+		for j, a := range i.Args {
+			v.compatible(fn.Params[j], a, "argument %d of %s", j, i.Target)
+		}
+
+	case *Store:
+		v.compatible(i.Global.Type(), i.Value, "store to %s", i.Global)
+	}
+}
+
+func (v *verifier) compatible(expected ast.Type, val Value, msg string, args ...any) {
+	if val == nil || val.Type() == nil {
+		return
+	}
+	if !expected.IsCompatible(val.Type()) {
+		v.fail("%s expects '%s', got '%s'", fmt.Sprintf(msg, args...), expected.GetSignature(), val.Type().GetSignature())
+	}
+}
+
+// Computes the dominator sets of the blocks reachable from the entry. Blocks
+// that cannot be reached are only dominated by themselves.
+func dominators(f *Function, preds map[*Block][]*Block) map[*Block]map[*Block]bool {
+	reachable := map[*Block]bool{}
+	var walk func(b *Block)
+	walk = func(b *Block) {
+		if reachable[b] {
+			return
+		}
+		reachable[b] = true
+		for _, s := range b.Successors() {
+			walk(s)
+		}
+	}
+	walk(f.Entry())
+
+	dom := map[*Block]map[*Block]bool{}
+	for _, b := range f.Blocks {
+		dom[b] = map[*Block]bool{b: true}
+		if reachable[b] && b != f.Entry() {
+			for _, o := range f.Blocks {
+				if reachable[o] {
+					dom[b][o] = true
+				}
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, b := range f.Blocks {
+			if !reachable[b] || b == f.Entry() {
+				continue
+			}
+
+			next := map[*Block]bool{}
+			first := true
+			for _, p := range preds[b] {
+				if !reachable[p] {
+					continue
+				}
+				if first {
+					for k := range dom[p] {
+						next[k] = true
+					}
+					first = false
+					continue
+				}
+				for k := range next {
+					if !dom[p][k] {
+						delete(next, k)
+					}
+				}
+			}
+			next[b] = true
+
+			if len(next) != len(dom[b]) {
+				dom[b] = next
+				changed = true
+			}
+		}
+	}
+	return dom
+}
@@ -0,0 +1,127 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates a module with a function `f(a Bool) Int`, which the tests fill.
+func newFunction() (*ir.Module, *ir.Function, *ir.Param) {
+	mod := ir.NewModule("main", "main.gold")
+	fn := ir.NewFunction("f", types.NewFunction(nil, nil, types.Int), types.Int, nil)
+	param := &ir.Param{Name: "a", Tp: types.Bool}
+	fn.Params = append(fn.Params, param)
+	mod.Functions = append(mod.Functions, fn)
+	return mod, fn, param
+}
+
+func TestVerify(t *testing.T) {
+	one := &ir.Const{Tp: types.Int, Value: int64(1)}
+
+	tests := []struct {
+		name     string
+		build    func(fn *ir.Function, a *ir.Param)
+		expected string
+	}{
+		{
+			name: "valid",
+			build: func(fn *ir.Function, a *ir.Param) {
+				b0, b1, b2 := fn.NewBlock(), fn.NewBlock(), fn.NewBlock()
+				b0.Term = &ir.Branch{Cond: a, Then: b1, Else: b2}
+				b1.Term = &ir.Jump{Target: b2}
+				dst := fn.NewTemp(types.Int)
+				b2.Add(&ir.Phi{Dst: dst, Edges: []ir.PhiEdge{{Block: b0, Value: one}, {Block: b1, Value: one}}})
+				b2.Term = &ir.Return{Value: dst}
+			},
+		},
+		{
+			name:     "no blocks",
+			build:    func(fn *ir.Function, a *ir.Param) {},
+			expected: "@f: function has no blocks",
+		},
+		{
+			name: "unterminated block",
+			build: func(fn *ir.Function, a *ir.Param) {
+				fn.NewBlock()
+			},
+			expected: "@f: block b0 is not terminated",
+		},
+		{
+			name: "use before definition",
+			build: func(fn *ir.Function, a *ir.Param) {
+				b0 := fn.NewBlock()
+				dst := fn.NewTemp(types.Int)
+				b0.Add(&ir.BinOp{Dst: fn.NewTemp(types.Int), Op: "add", Left: dst, Right: one})
+				b0.Add(&ir.BinOp{Dst: dst, Op: "add", Left: one, Right: one})
+				b0.Term = &ir.Return{Value: dst}
+			},
+			expected: "@f: %0 is used in b0 before its definition",
+		},
+		{
+			name: "definition not dominating",
+			build: func(fn *ir.Function, a *ir.Param) {
+				b0, b1, b2 := fn.NewBlock(), fn.NewBlock(), fn.NewBlock()
+				b0.Term = &ir.Branch{Cond: a, Then: b1, Else: b2}
+				dst := fn.NewTemp(types.Int)
+				b1.Add(&ir.BinOp{Dst: dst, Op: "add", Left: one, Right: one})
+				b1.Term = &ir.Jump{Target: b2}
+				b2.Term = &ir.Return{Value: dst}
+			},
+			expected: "@f: %0 is used in b2 before its definition",
+		},
+		{
+			name: "phi of a block that is not a predecessor",
+			build: func(fn *ir.Function, a *ir.Param) {
+				b0, b1, b2 := fn.NewBlock(), fn.NewBlock(), fn.NewBlock()
+				b0.Term = &ir.Jump{Target: b2}
+				b1.Term = &ir.Jump{Target: b2}
+				dst := fn.NewTemp(types.Int)
+				b2.Add(&ir.Phi{Dst: dst, Edges: []ir.PhiEdge{{Block: b0, Value: one}, {Block: b0, Value: one}}})
+				b2.Term = &ir.Return{Value: dst}
+				b0.Term = &ir.Return{Value: one}
+			},
+			expected: "@f: phi %0 refers to b0, which is not a predecessor of b2",
+		},
+		{
+			name: "branch on a non Bool",
+			build: func(fn *ir.Function, a *ir.Param) {
+				b0, b1 := fn.NewBlock(), fn.NewBlock()
+				b0.Term = &ir.Branch{Cond: one, Then: b1, Else: b1}
+				b1.Term = &ir.Return{Value: one}
+			},
+			expected: "@f: branch condition expects 'Bool', got 'Int'",
+		},
+		{
+			name: "missing return value",
+			build: func(fn *ir.Function, a *ir.Param) {
+				fn.NewBlock().Term = &ir.Return{}
+			},
+			expected: "@f: missing return value of type 'Int'",
+		},
+		{
+			name: "foreign parameter",
+			build: func(fn *ir.Function, a *ir.Param) {
+				fn.NewBlock().Term = &ir.Return{Value: &ir.Param{Name: "b", Tp: types.Int}}
+			},
+			expected: "@f: %b does not belong to the function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, fn, a := newFunction()
+			tt.build(fn, a)
+			err := ir.Verify(mod)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expected)
+			}
+		})
+	}
+}
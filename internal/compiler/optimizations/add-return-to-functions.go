@@ -1,35 +1,55 @@
 package optimizations
 
-// import "github.com/renatopp/golden/internal/compiler/ast"
-
-// type AddReturnToFunctions struct {
-// 	*ast.Visiter
-// }
-
-// func NewAddReturnToFunctions() *AddReturnToFunctions {
-// 	opt := &AddReturnToFunctions{}
-// 	opt.Visiter = ast.NewVisiter(opt)
-// 	return opt
-// }
-
-// func (a *AddReturnToFunctions) VisitFnDecl(node *ast.FnDecl) ast.Node {
-// 	defer a.Visiter.VisitFnDecl(node)
-
-// 	block := node.ValueExpr
-// 	if len(block.Exprs) == 0 {
-// 		return node
-// 	}
-
-// 	lastIdx := len(block.Exprs) - 1
-// 	lastExpr := block.Exprs[lastIdx]
-// 	if _, ok := lastExpr.(*ast.Return); ok {
-// 		return node
-// 	}
-
-// 	block.Exprs[lastIdx] = &ast.Return{
-// 		ValueExpr: lastExpr,
-// 	}
-// 	block.Exprs[lastIdx].SetType(lastExpr.GetType().Unwrap())
-
-// 	return node
-// }
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/safe"
+)
+
+// AddReturnToFunctions lowers the implicit return of function bodies, turning
+// the last expression of non-void functions into an explicit `return`, so the
+// backends only have to deal with explicit returns.
+type AddReturnToFunctions struct {
+	*ast.Visiter
+}
+
+func NewAddReturnToFunctions() *AddReturnToFunctions {
+	opt := &AddReturnToFunctions{}
+	opt.Visiter = ast.NewVisiter(opt)
+	return opt
+}
+
+func (a *AddReturnToFunctions) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	defer a.Visiter.VisitFnDecl(node)
+
+	fnType, ok := node.GetType().Unwrap().(*types.Function)
+	if !ok || fnType.Return == types.Void {
+		return node
+	}
+
+	block := node.ValueExpr
+	if len(block.Exprs) == 0 {
+		return node
+	}
+
+	lastIdx := len(block.Exprs) - 1
+	lastExpr := block.Exprs[lastIdx]
+	switch lastExpr.(type) {
+	case *ast.Return, *ast.VarDecl:
+		return node
+	}
+
+	// the last expression is unreachable after a return, so it is not the
+	// value of the function and its type was not checked against the return
+	for _, e := range block.Exprs[:lastIdx] {
+		if diverges(e) {
+			return node
+		}
+	}
+
+	ret := ast.NewReturn(lastExpr.GetToken(), safe.Some(lastExpr))
+	ret.SetType(lastExpr.GetType().Unwrap())
+	block.Exprs[lastIdx] = ret
+
+	return node
+}
@@ -0,0 +1,31 @@
+package optimizations_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddReturnToFunctions(t *testing.T) {
+	tests := []struct {
+		ret      string
+		body     string
+		expected string
+	}{
+		{"Int", "a + 1", "(block (return (bin-op + (var-ident a) (int 1))))"},
+		{"Int", "return a", "(block (return (var-ident a)))"},
+		{"", "a + 1", "(block (bin-op + (var-ident a) (int 1)))"},
+		{"Int", "let b = a\n  b", "(block (let (var-ident b) (var-ident a)) (return (var-ident b)))"},
+		{"Int", "return a\n  \"oops\"", "(block (return (var-ident a)) (string \"oops\"))"},
+		{"Int", "return a\n  a + 1", "(block (return (var-ident a)) (bin-op + (var-ident a) (int 1)))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			source := "fn f(a Int) " + tt.ret + " {\n  " + tt.body + "\n}\nfn main() {}\n"
+			root, err := optimize(t, source, "add-return")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, body(t, root, "f"))
+		})
+	}
+}
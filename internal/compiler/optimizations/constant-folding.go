@@ -0,0 +1,424 @@
+package optimizations
+
+import (
+	"math"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+	"github.com/renatopp/golden/internal/helpers/errors"
+)
+
+// ConstantFolding evaluates operations over literals at compile time and
+// propagates the module variables initialized with constants, which are always
+// immutable. Folded nodes keep the type, token and span of the expression
+// they replace.
+type ConstantFolding struct {
+	*ast.Visiter
+	ctx    *PassContext
+	scopes []map[string]ast.Node // constant value of each name, nil if not constant
+}
+
+func NewConstantFolding(ctx *PassContext) *ConstantFolding {
+	opt := &ConstantFolding{ctx: ctx}
+	opt.Visiter = ast.NewVisiter(opt)
+	return opt
+}
+
+func (c *ConstantFolding) pushScope() { c.scopes = append(c.scopes, map[string]ast.Node{}) }
+func (c *ConstantFolding) popScope()  { c.scopes = c.scopes[:len(c.scopes)-1] }
+func (c *ConstantFolding) declare(name string, value ast.Node) {
+	c.scopes[len(c.scopes)-1][name] = value
+}
+func (c *ConstantFolding) lookup(name string) ast.Node {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if v, ok := c.scopes[i][name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (c *ConstantFolding) VisitModule(node *ast.Module) ast.Node {
+	c.pushScope()
+	defer c.popScope()
+
+	// module variables can be declared in any order, so they are folded until
+	// no new constant is found
+	decls := []*ast.VarDecl{}
+	for _, e := range node.Exprs {
+		if decl, ok := e.(*ast.VarDecl); ok {
+			decls = append(decls, decl)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, decl := range decls {
+			if c.lookup(decl.Name.Value) != nil {
+				continue
+			}
+			decl.ValueExpr = decl.ValueExpr.Visit(c)
+			if isLiteral(decl.ValueExpr) {
+				c.declare(decl.Name.Value, decl.ValueExpr)
+				changed = true
+			}
+		}
+	}
+
+	for i, e := range node.Exprs {
+		if _, ok := e.(*ast.FnDecl); ok {
+			node.Exprs[i] = e.Visit(c)
+		}
+	}
+	return node
+}
+
+// Local variables are not propagated, they only shadow the module constants.
+func (c *ConstantFolding) VisitVarDecl(node *ast.VarDecl) ast.Node {
+	node.ValueExpr = node.ValueExpr.Visit(c)
+	c.declare(node.Name.Value, nil)
+	return node
+}
+
+func (c *ConstantFolding) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	value := c.lookup(node.Value)
+	if value == nil {
+		return node
+	}
+	c.ctx.Remark(node, "propagated constant '%s'", node.Value)
+	return copyLiteral(value, node)
+}
+
+func (c *ConstantFolding) VisitBlock(node *ast.Block) ast.Node {
+	c.pushScope()
+	defer c.popScope()
+	for i, e := range node.Exprs {
+		node.Exprs[i] = e.Visit(c)
+	}
+	return node
+}
+
+func (c *ConstantFolding) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	// the name is declared in the enclosing scope, shadowing constants
+	node.Name.If(func(n *ast.VarIdent) { c.declare(n.Value, nil) })
+
+	c.pushScope()
+	defer c.popScope()
+	for _, p := range node.Params {
+		c.declare(p.Name.Value, nil)
+	}
+	node.ValueExpr = node.ValueExpr.Visit(c).(*ast.Block)
+	return node
+}
+
+func (c *ConstantFolding) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
+	return node
+}
+
+func (c *ConstantFolding) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
+	node.RightExpr = node.RightExpr.Visit(c)
+
+	switch right := node.RightExpr.(type) {
+	case *ast.Int:
+		switch node.Op {
+		case "+":
+			return withNode(ast.NewInt(node.Token, right.Value), node)
+		case "-":
+			if right.Value == math.MinInt64 {
+				errors.ThrowAtNode(node, errors.IntegerOverflow, "integer overflow when negating %d", right.Value)
+			}
+			return withNode(ast.NewInt(node.Token, -right.Value), node)
+		}
+
+	case *ast.Float:
+		switch node.Op {
+		case "+":
+			return withNode(ast.NewFloat(node.Token, right.Value), node)
+		case "-":
+			return withNode(ast.NewFloat(node.Token, -right.Value), node)
+		}
+
+	case *ast.Bool:
+		if node.Op == "!" {
+			return withNode(ast.NewBool(node.Token, !right.Value), node)
+		}
+
+	case *ast.UnaryOp:
+		// !!a → a
+		if node.Op == "!" && right.Op == "!" {
+			return right.RightExpr
+		}
+	}
+
+	return node
+}
+
+func (c *ConstantFolding) VisitBinOp(node *ast.BinOp) ast.Node {
+	node.LeftExpr = node.LeftExpr.Visit(c)
+	node.RightExpr = node.RightExpr.Visit(c)
+
+	switch left := node.LeftExpr.(type) {
+	case *ast.Int:
+		if right, ok := node.RightExpr.(*ast.Int); ok {
+			return c.foldInt(node, left.Value, right.Value)
+		}
+	case *ast.Float:
+		if right, ok := node.RightExpr.(*ast.Float); ok {
+			return c.foldFloat(node, left.Value, right.Value)
+		}
+	case *ast.String:
+		if right, ok := node.RightExpr.(*ast.String); ok {
+			return c.foldString(node, left.Value, right.Value)
+		}
+	}
+
+	switch node.Op {
+	case "and", "or", "xor", "==", "!=":
+		return c.simplifyBool(node)
+	}
+	return node
+}
+
+func (c *ConstantFolding) foldInt(node *ast.BinOp, a, b int64) ast.Node {
+	tok := node.Token
+	switch node.Op {
+	case "+":
+		if b > 0 && a > math.MaxInt64-b || b < 0 && a < math.MinInt64-b {
+			errors.ThrowAtNode(node, errors.IntegerOverflow, "integer overflow in %d + %d", a, b)
+		}
+		return withNode(ast.NewInt(tok, a+b), node)
+	case "-":
+		if b < 0 && a > math.MaxInt64+b || b > 0 && a < math.MinInt64+b {
+			errors.ThrowAtNode(node, errors.IntegerOverflow, "integer overflow in %d - %d", a, b)
+		}
+		return withNode(ast.NewInt(tok, a-b), node)
+	case "*":
+		r := a * b
+		if a != 0 && (r/a != b || a == -1 && b == math.MinInt64) {
+			errors.ThrowAtNode(node, errors.IntegerOverflow, "integer overflow in %d * %d", a, b)
+		}
+		return withNode(ast.NewInt(tok, r), node)
+	case "/", "%":
+		// only constant divisions are reported, the others are checked when
+		// running, like float divisions
+		if b == 0 {
+			errors.ThrowAtNode(node, errors.DivisionByZero, "division by zero")
+		}
+		if node.Op == "/" {
+			if a == math.MinInt64 && b == -1 {
+				errors.ThrowAtNode(node, errors.IntegerOverflow, "integer overflow in %d / %d", a, b)
+			}
+			return withNode(ast.NewInt(tok, a/b), node)
+		}
+		return withNode(ast.NewInt(tok, a%b), node)
+	case "<=>":
+		return withNode(ast.NewInt(tok, int64(compare(a, b))), node)
+	case "==":
+		return withNode(ast.NewBool(tok, a == b), node)
+	case "!=":
+		return withNode(ast.NewBool(tok, a != b), node)
+	case "<":
+		return withNode(ast.NewBool(tok, a < b), node)
+	case "<=":
+		return withNode(ast.NewBool(tok, a <= b), node)
+	case ">":
+		return withNode(ast.NewBool(tok, a > b), node)
+	case ">=":
+		return withNode(ast.NewBool(tok, a >= b), node)
+	}
+	return node
+}
+
+func (c *ConstantFolding) foldFloat(node *ast.BinOp, a, b float64) ast.Node {
+	tok := node.Token
+	switch node.Op {
+	case "+":
+		return withNode(ast.NewFloat(tok, a+b), node)
+	case "-":
+		return withNode(ast.NewFloat(tok, a-b), node)
+	case "*":
+		return withNode(ast.NewFloat(tok, a*b), node)
+	case "/":
+		if b == 0 {
+			return node // infinities have no literal
+		}
+		return withNode(ast.NewFloat(tok, a/b), node)
+	case "<=>":
+		return withNode(ast.NewInt(tok, int64(compare(a, b))), node)
+	case "==":
+		return withNode(ast.NewBool(tok, a == b), node)
+	case "!=":
+		return withNode(ast.NewBool(tok, a != b), node)
+	case "<":
+		return withNode(ast.NewBool(tok, a < b), node)
+	case "<=":
+		return withNode(ast.NewBool(tok, a <= b), node)
+	case ">":
+		return withNode(ast.NewBool(tok, a > b), node)
+	case ">=":
+		return withNode(ast.NewBool(tok, a >= b), node)
+	}
+	return node
+}
+
+func (c *ConstantFolding) foldString(node *ast.BinOp, a, b string) ast.Node {
+	tok := node.Token
+	switch node.Op {
+	case "+":
+		return withNode(ast.NewString(tok, a+b), node)
+	case "==":
+		return withNode(ast.NewBool(tok, a == b), node)
+	case "!=":
+		return withNode(ast.NewBool(tok, a != b), node)
+	}
+	return node
+}
+
+// Applies the boolean identities, keeping the evaluation of operands with side
+// effects:
+//
+//	true and a → a        false and a → false
+//	a and true → a        a and false → false (if a is pure)
+//	true or a  → true     false or a  → a
+//	a or true  → true     a or false  → a     (if a is pure)
+//	a xor false → a       a xor true  → !a
+//	a == true  → a        a != false  → a     (if a is a Bool)
+func (c *ConstantFolding) simplifyBool(node *ast.BinOp) ast.Node {
+	left, lok := node.LeftExpr.(*ast.Bool)
+	right, rok := node.RightExpr.(*ast.Bool)
+
+	if lok && rok {
+		tok := node.Token
+		switch node.Op {
+		case "and":
+			return withNode(ast.NewBool(tok, left.Value && right.Value), node)
+		case "or":
+			return withNode(ast.NewBool(tok, left.Value || right.Value), node)
+		case "xor", "!=":
+			return withNode(ast.NewBool(tok, left.Value != right.Value), node)
+		case "==":
+			return withNode(ast.NewBool(tok, left.Value == right.Value), node)
+		}
+		return node
+	}
+
+	// the literal and the other operand, which is evaluated either way when
+	// the literal is on the right side
+	var lit *ast.Bool
+	var other ast.Node
+	switch {
+	case lok:
+		lit, other = left, node.RightExpr
+	case rok:
+		lit, other = right, node.LeftExpr
+	default:
+		return node
+	}
+
+	// the operands of equality are not checked to be Bools
+	if (node.Op == "==" || node.Op == "!=") && !isBool(other) {
+		return node
+	}
+
+	dropsOther := func() bool { return lok || isPure(other) }
+	res := ast.Node(node)
+	switch node.Op {
+	case "and":
+		if lit.Value {
+			res = other
+		} else if dropsOther() {
+			res = withNode(ast.NewBool(node.Token, false), node)
+		}
+	case "or":
+		if !lit.Value {
+			res = other
+		} else if dropsOther() {
+			res = withNode(ast.NewBool(node.Token, true), node)
+		}
+	case "xor", "!=":
+		if lit.Value {
+			res = withNode(ast.NewUnaryOp(node.Token, "!", other), node)
+		} else {
+			res = other
+		}
+	case "==":
+		if lit.Value {
+			res = other
+		} else {
+			res = withNode(ast.NewUnaryOp(node.Token, "!", other), node)
+		}
+	}
+
+	if res != ast.Node(node) {
+		c.ctx.Remark(node, "simplified boolean '%s' with constant %t", node.Op, lit.Value)
+	}
+	return res
+}
+
+//
+//
+//
+
+func compare[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Copies the type and span of the original node to the folded one.
+func withNode[T ast.Node](folded T, original ast.Node) T {
+	original.GetType().If(func(tp ast.Type) { folded.SetType(tp) })
+	folded.SetSpan(original.GetSpan())
+	return folded
+}
+
+func isBool(node ast.Node) bool {
+	tp := node.GetType().Or(nil)
+	return tp != nil && types.Bool.IsCompatible(tp)
+}
+
+func isLiteral(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Int, *ast.Float, *ast.String, *ast.Bool:
+		return true
+	}
+	return false
+}
+
+// Creates a new literal with the value of `lit`, placed at the `at` node.
+func copyLiteral(lit ast.Node, at ast.Node) ast.Node {
+	tok := at.GetToken()
+	var res ast.Node
+	switch n := lit.(type) {
+	case *ast.Int:
+		res = ast.NewInt(tok, n.Value)
+	case *ast.Float:
+		res = ast.NewFloat(tok, n.Value)
+	case *ast.String:
+		res = ast.NewString(tok, n.Value)
+	case *ast.Bool:
+		res = ast.NewBool(tok, n.Value)
+	default:
+		return at
+	}
+	lit.GetType().If(func(tp ast.Type) { res.SetType(tp) })
+	res.SetSpan(at.GetSpan())
+	return res
+}
+
+// Checks if evaluating the expression has no side effects, which is true for
+// any expression without function calls.
+func isPure(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Int, *ast.Float, *ast.String, *ast.Bool, *ast.VarIdent, *ast.Access, *ast.FnDecl:
+		return true
+	case *ast.UnaryOp:
+		return isPure(n.RightExpr)
+	case *ast.BinOp:
+		return isPure(n.LeftExpr) && isPure(n.RightExpr)
+	}
+	return false
+}
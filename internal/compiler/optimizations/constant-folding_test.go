@@ -0,0 +1,93 @@
+package optimizations_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstantFolding(t *testing.T) {
+	tests := []struct {
+		params   string
+		ret      string
+		expr     string
+		expected string
+	}{
+		{"", "Int", "1 + 2 * 3", "(int 7)"},
+		{"", "Int", "7 / 2 - 10 / 3", "(int 0)"},
+		{"", "Int", "-(-9223372036854775807)", "(int 9223372036854775807)"},
+		{"", "Int", "limit * 2", "(int 20)"},
+		{"", "Int", "2 <=> 1", "(int 1)"},
+		{"", "Float", "1.5 * 2.0", "(float 3.000000)"},
+		{"", "String", "\"a\" + \"b\"", "(string \"ab\")"},
+		{"", "Bool", "1 < 2 and !false", "(bool true)"},
+		{"", "Bool", "\"a\" != \"a\"", "(bool false)"},
+		{"limit Int", "Int", "limit * 2", "(bin-op * (var-ident limit) (int 2))"},
+		{"a Int", "Int", "a / 0", "(bin-op / (var-ident a) (int 0))"},
+		{"a Float", "Float", "a / 0.0", "(bin-op / (var-ident a) (float 0.000000))"},
+		{"", "Float", "1.0 / 0.0", "(bin-op / (float 1.000000) (float 0.000000))"},
+		{"a Bool", "Bool", "a and true", "(var-ident a)"},
+		{"a Bool", "Bool", "a or true", "(bool true)"},
+		{"a Bool", "Bool", "a xor true", "(unary-op ! (var-ident a))"},
+		{"a Bool", "Bool", "a == true", "(var-ident a)"},
+		{"a Bool", "Bool", "a != true", "(unary-op ! (var-ident a))"},
+		{"a Bool", "Bool", "!!a", "(var-ident a)"},
+		{"a Int", "Bool", "a == true", "(bin-op == (var-ident a) (bool true))"},
+		{"a Int", "Bool", "a != false", "(bin-op != (var-ident a) (bool false))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			source := "let limit = 10\nfn f(" + tt.params + ") " + tt.ret + " {\n  " + tt.expr + "\n}\nfn main() {}\n"
+			root, err := optimize(t, source, "const-fold")
+			require.NoError(t, err)
+			assert.Equal(t, "(block (return "+tt.expected+"))", body(t, root, "f"))
+		})
+	}
+}
+
+func TestConstantFoldingErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		code errors.ErrorCode
+	}{
+		{"9223372036854775807 + 1", errors.IntegerOverflow},
+		{"-9223372036854775807 - 2", errors.IntegerOverflow},
+		{"4611686018427387904 * 2", errors.IntegerOverflow},
+		{"-(-9223372036854775807 - 1)", errors.IntegerOverflow},
+		{"(-9223372036854775807 - 1) / -1", errors.IntegerOverflow},
+		{"1 / 0", errors.DivisionByZero},
+		{"1 / (2 - 2)", errors.DivisionByZero},
+		{"limit / 0", errors.DivisionByZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			source := "let limit = 10\nfn f() Int {\n  " + tt.expr + "\n}\nfn main() {}\n"
+			_, err := optimize(t, source, "const-fold")
+			require.Error(t, err)
+			e := errors.ToGoldenError(err)
+			assert.Equal(t, tt.code, e.Code)
+			assert.Equal(t, 3, e.Loc.Unwrap().FromLine)
+		})
+	}
+}
+
+// Folded nodes keep the span of the whole expression they replace.
+func TestConstantFoldingSpans(t *testing.T) {
+	source := "let limit = 10\nfn f() Int {\n  1 + 2 * limit\n}\nfn g() Int {\n  limit\n}\nfn main() {}\n"
+	root, err := optimize(t, source, "const-fold")
+	require.NoError(t, err)
+	assert.Equal(t, "(block (return (int 21)))", body(t, root, "f"))
+	assert.Equal(t, "(block (return (int 10)))", body(t, root, "g"))
+
+	returned := func(name string) *token.Span {
+		return function(root, name).ValueExpr.Exprs[0].(*ast.Return).ValueExpr.Unwrap().GetSpan()
+	}
+	span := returned("f")
+	assert.Equal(t, [3]int{3, 3, 16}, [3]int{span.FromLine, span.FromColumn, span.ToColumn})
+	span = returned("g")
+	assert.Equal(t, [3]int{6, 3, 8}, [3]int{span.FromLine, span.FromColumn, span.ToColumn})
+}
@@ -0,0 +1,193 @@
+package optimizations
+
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/types"
+)
+
+// DeadCodeElimination removes the module functions and variables that are not
+// reachable from the entry `main`, and the expressions following a `return`.
+// The reachability is computed over the whole program by `analyzeLiveness`.
+type DeadCodeElimination struct {
+	*ast.Visiter
+	ctx  *PassContext
+	live map[ast.Node]bool
+}
+
+func NewDeadCodeElimination(ctx *PassContext) *DeadCodeElimination {
+	opt := &DeadCodeElimination{ctx: ctx, live: ctx.Analysis.(map[ast.Node]bool)}
+	opt.Visiter = ast.NewVisiter(opt)
+	return opt
+}
+
+func (d *DeadCodeElimination) VisitModule(node *ast.Module) ast.Node {
+	exprs := []ast.Node{}
+	for _, e := range node.Exprs {
+		if name, ok := declarationName(e); ok && !d.live[e] {
+			switch e.(type) {
+			case *ast.FnDecl, *ast.ExternFnDecl:
+				d.ctx.Remark(e, "removed unused function '%s'", name)
+			default:
+				d.ctx.Remark(e, "removed unused variable '%s'", name)
+			}
+			continue
+		}
+		exprs = append(exprs, e.Visit(d))
+	}
+	node.Exprs = exprs
+	return node
+}
+
+func (d *DeadCodeElimination) VisitBlock(node *ast.Block) ast.Node {
+	for i, e := range node.Exprs {
+		node.Exprs[i] = e.Visit(d)
+	}
+
+	for i, e := range node.Exprs {
+		if diverges(e) && i < len(node.Exprs)-1 {
+			d.ctx.Remark(node.Exprs[i+1], "removed %d unreachable expression(s)", len(node.Exprs)-i-1)
+			node.Exprs = node.Exprs[:i+1]
+			break
+		}
+	}
+	return node
+}
+
+//
+//
+//
+
+// Marks the module declarations reachable from the entry `main`. Module
+// variables with initializers that may have side effects are always kept.
+func analyzeLiveness(program *Program) any {
+	live := map[ast.Node]bool{}
+	scopes := map[*ast.Module]map[string]ast.Node{}
+	owner := map[ast.Node]*ast.Module{}
+	paths := map[string]*ast.Module{}
+	pending := []ast.Node{}
+
+	for _, mod := range program.Modules {
+		scopes[mod] = map[string]ast.Node{}
+		if tp, ok := mod.GetType().Unwrap().(*types.Module); ok {
+			paths[tp.Path] = mod
+		}
+		for _, e := range mod.Exprs {
+			name, ok := declarationName(e)
+			if !ok {
+				continue
+			}
+			scopes[mod][name] = e
+			owner[e] = mod
+			if decl, ok := e.(*ast.VarDecl); ok && !isPure(decl.ValueExpr) {
+				pending = append(pending, e)
+			}
+		}
+	}
+	if main, ok := scopes[program.Entry]["main"]; ok {
+		pending = append(pending, main)
+	}
+
+	for len(pending) > 0 {
+		decl := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if live[decl] {
+			continue
+		}
+		live[decl] = true
+
+		// references are resolved by name in the module of the declaration,
+		// keeping a declaration whenever a local with the same name is used
+		var body ast.Node
+		switch n := decl.(type) {
+		case *ast.VarDecl:
+			body = n.ValueExpr
+		case *ast.FnDecl:
+			body = n.ValueExpr
+		case *ast.ExternFnDecl:
+			continue // implemented by the host
+		}
+		for _, name := range referencedNames(body) {
+			if dep, ok := scopes[owner[decl]][name]; ok && !live[dep] {
+				pending = append(pending, dep)
+			}
+		}
+		for _, member := range referencedMembers(body) {
+			if dep, ok := scopes[paths[member.path]][member.name]; ok && !live[dep] {
+				pending = append(pending, dep)
+			}
+		}
+	}
+	return live
+}
+
+// Returns the name of module-level declarations.
+func declarationName(node ast.Node) (string, bool) {
+	switch n := node.(type) {
+	case *ast.VarDecl:
+		return n.Name.Value, true
+	case *ast.FnDecl:
+		if n.Name.Has() {
+			return n.Name.Unwrap().Value, true
+		}
+	case *ast.ExternFnDecl:
+		return n.Name.Value, true
+	}
+	return "", false
+}
+
+// Checks if the control flow never continues after the expression, like the
+// checker does: blocks diverge when any of their expressions does.
+func diverges(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Return:
+		return true
+	case *ast.Block:
+		for _, e := range n.Exprs {
+			if diverges(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Collects the names of all identifiers used in the expression.
+func referencedNames(node ast.Node) []string {
+	c := &nameCollector{names: []string{}}
+	c.Visiter = ast.NewVisiter(c)
+	node.Visit(c)
+	return c.names
+}
+
+type nameCollector struct {
+	*ast.Visiter
+	names   []string
+	members []member
+}
+
+// Declaration of another module, by the path of the module.
+type member struct {
+	path string
+	name string
+}
+
+func (c *nameCollector) VisitVarIdent(node *ast.VarIdent) ast.Node {
+	c.names = append(c.names, node.Value)
+	return node
+}
+
+func (c *nameCollector) VisitAccess(node *ast.Access) ast.Node {
+	if mod, ok := node.Target.GetType().Or(nil).(*types.Module); ok {
+		c.members = append(c.members, member{mod.Path, node.Name.Value})
+	}
+	node.Target.Visit(c)
+	return node
+}
+
+// Collects the declarations of other modules used in the expression.
+func referencedMembers(node ast.Node) []member {
+	c := &nameCollector{members: []member{}}
+	c.Visiter = ast.NewVisiter(c)
+	node.Visit(c)
+	return c.members
+}
@@ -0,0 +1,90 @@
+package optimizations_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the names of the module declarations, in order.
+func declarations(root *ast.Module) []string {
+	names := []string{}
+	for _, expr := range root.Exprs {
+		switch n := expr.(type) {
+		case *ast.VarDecl:
+			names = append(names, n.Name.Value)
+		case *ast.FnDecl:
+			names = append(names, n.Name.Unwrap().Value)
+		case *ast.ExternFnDecl:
+			names = append(names, n.Name.Value)
+		}
+	}
+	return names
+}
+
+func TestDeadCodeElimination(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string][]string // declarations kept in each module
+		remark   string
+	}{
+		{
+			name: "unused functions",
+			files: map[string]string{
+				"main.gold": "fn a() Int { b() }\nfn b() Int { 1 }\nfn c() Int { 2 }\nfn main() { a() }\n",
+			},
+			expected: map[string][]string{"main.gold": {"a", "b", "main"}},
+			remark:   "removed unused function 'c'",
+		},
+		{
+			name: "unused variables",
+			files: map[string]string{
+				"main.gold": "let x = 1 + 2\nlet y = h()\nfn h() Int { 1 }\nfn main() {}\n",
+			},
+			expected: map[string][]string{"main.gold": {"y", "h", "main"}},
+			remark:   "removed unused variable 'x'",
+		},
+		{
+			name: "access to other modules",
+			files: map[string]string{
+				"main.gold":     "import \"lib/math\"\nfn main() { math.half(4) }\n",
+				"lib/math.gold": "fn half(a Int) Int { a / two() }\nfn two() Int { 2 }\nfn third(a Int) Int { a / 3 }\n",
+			},
+			expected: map[string][]string{"main.gold": {"main"}, "lib/math.gold": {"half", "two"}},
+			remark:   "removed unused function 'third'",
+		},
+		{
+			name: "names of locals",
+			files: map[string]string{
+				"main.gold": "fn c() Int { 2 }\nfn d() Int { 3 }\nfn main() {\n  let c = 1\n  c\n}\n",
+			},
+			expected: map[string][]string{"main.gold": {"c", "main"}},
+			remark:   "removed unused function 'd'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mods, remarks, err := optimizeProject(t, optimizations.LevelDefault, tt.files, "dce")
+			require.NoError(t, err)
+			for file, names := range tt.expected {
+				require.Contains(t, mods, file)
+				assert.Equal(t, names, declarations(mods[file]), file)
+			}
+			assert.Contains(t, strings.Join(remarks, "\n"), tt.remark)
+		})
+	}
+}
+
+func TestDeadCodeEliminationUnreachable(t *testing.T) {
+	source := "fn f(a Int) Int {\n  return a\n  a + 1\n  a + 2\n}\nfn main() { f(1) }\n"
+	root, remarks, err := optimizeAt(t, optimizations.LevelDefault, source, "dce")
+	require.NoError(t, err)
+	assert.Equal(t, "(block (return (var-ident a)))", body(t, root, "f"))
+	assert.Contains(t, remarks, "3:5: removed 2 unreachable expression(s)")
+}
@@ -0,0 +1,306 @@
+package optimizations
+
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// Maximum size of the functions inlined without the `@inline` annotation, per
+// optimization level.
+var inlineThreshold = map[int]int{
+	LevelDefault:    8,
+	LevelAggressive: 24,
+}
+
+// Inlining replaces the calls to small module functions by their bodies.
+//
+// Only functions whose body is a single expression are inlined, with the
+// parameters replaced by the arguments of the call. To preserve the evaluation
+// order, arguments with side effects are only accepted when every parameter
+// is used exactly once, in order, and the body has no calls of its own.
+// Recursive functions and the ones annotated with `@noinline` are never
+// inlined.
+type Inlining struct {
+	*ast.Visiter
+	ctx       *PassContext
+	functions map[string]*ast.FnDecl
+	scopes    []map[string]bool
+}
+
+func NewInlining(ctx *PassContext) *Inlining {
+	opt := &Inlining{ctx: ctx, functions: map[string]*ast.FnDecl{}}
+	opt.Visiter = ast.NewVisiter(opt)
+	return opt
+}
+
+func (n *Inlining) pushScope()          { n.scopes = append(n.scopes, map[string]bool{}) }
+func (n *Inlining) popScope()           { n.scopes = n.scopes[:len(n.scopes)-1] }
+func (n *Inlining) declare(name string) { n.scopes[len(n.scopes)-1][name] = true }
+func (n *Inlining) isLocal(name string) bool {
+	for _, s := range n.scopes {
+		if s[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Inlining) VisitModule(node *ast.Module) ast.Node {
+	for _, e := range node.Exprs {
+		if fn, ok := e.(*ast.FnDecl); ok && fn.Name.Has() {
+			n.functions[fn.Name.Unwrap().Value] = fn
+		}
+	}
+	for i, e := range node.Exprs {
+		node.Exprs[i] = e.Visit(n)
+	}
+	return node
+}
+
+func (n *Inlining) VisitVarDecl(node *ast.VarDecl) ast.Node {
+	node.ValueExpr = node.ValueExpr.Visit(n)
+	if len(n.scopes) > 0 {
+		n.declare(node.Name.Value)
+	}
+	return node
+}
+
+func (n *Inlining) VisitBlock(node *ast.Block) ast.Node {
+	n.pushScope()
+	defer n.popScope()
+	for i, e := range node.Exprs {
+		node.Exprs[i] = e.Visit(n)
+	}
+	return node
+}
+
+func (n *Inlining) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	if len(n.scopes) > 0 {
+		node.Name.If(func(name *ast.VarIdent) { n.declare(name.Value) })
+	}
+
+	n.pushScope()
+	defer n.popScope()
+	for _, p := range node.Params {
+		n.declare(p.Name.Value)
+	}
+	node.ValueExpr = node.ValueExpr.Visit(n).(*ast.Block)
+	return node
+}
+
+func (n *Inlining) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
+	return node
+}
+
+func (n *Inlining) VisitApplication(node *ast.Application) ast.Node {
+	node.Target = node.Target.Visit(n)
+	for i, a := range node.Args {
+		node.Args[i] = a.Visit(n)
+	}
+
+	target, ok := node.Target.(*ast.VarIdent)
+	if !ok || n.isLocal(target.Value) {
+		return node
+	}
+	fn, ok := n.functions[target.Value]
+	if !ok {
+		return node
+	}
+
+	name := target.Value
+	switch {
+	case fn.HasAnnotation("noinline"):
+		n.ctx.Remark(node, "not inlining '%s': annotated with @noinline", name)
+		return node
+	case fn.Recursive:
+		n.ctx.Remark(node, "not inlining '%s': function is recursive", name)
+		return node
+	}
+
+	body, ok := inlinableBody(fn)
+	if !ok {
+		if fn.HasAnnotation("inline") {
+			n.ctx.Remark(node, "not inlining '%s': body is not a single expression", name)
+		}
+		return node
+	}
+
+	size := expressionSize(body)
+	limit := inlineThreshold[n.ctx.Level]
+	if !fn.HasAnnotation("inline") && size > limit {
+		n.ctx.Remark(node, "not inlining '%s': size %d exceeds the limit of %d", name, size, limit)
+		return node
+	}
+
+	// the body must not refer to module names hidden by locals of the call site
+	params := map[string]int{}
+	for i, p := range fn.Params {
+		params[p.Name.Value] = i
+	}
+	uses := []int{}
+	for _, ref := range referencedNames(body) {
+		if i, ok := params[ref]; ok {
+			uses = append(uses, i)
+		} else if n.isLocal(ref) {
+			n.ctx.Remark(node, "not inlining '%s': '%s' is shadowed at the call site", name, ref)
+			return node
+		}
+	}
+
+	if reason := n.checkArguments(node.Args, uses, body); reason != "" {
+		n.ctx.Remark(node, "not inlining '%s': %s", name, reason)
+		return node
+	}
+
+	n.ctx.Remark(node, "inlined '%s' (size %d)", name, size)
+	return cloneExpression(body, node, func(ref *ast.VarIdent) ast.Node {
+		if i, ok := params[ref.Value]; ok {
+			return cloneExpression(node.Args[i], node.Args[i], nil)
+		}
+		return nil
+	})
+}
+
+// Returns a reason why the arguments cannot replace the parameters, given the
+// order in which the body uses the parameters.
+func (n *Inlining) checkArguments(args []ast.Node, uses []int, body ast.Node) string {
+	count := make([]int, len(args))
+	for _, i := range uses {
+		count[i]++
+	}
+
+	impure := false
+	for i, a := range args {
+		switch {
+		case !isPure(a):
+			impure = true
+			if count[i] != 1 {
+				return "argument with side effects is not used exactly once"
+			}
+		case count[i] > 1 && !isTrivial(a):
+			return "argument would be evaluated more than once"
+		}
+	}
+
+	if impure {
+		if !isPure(body) {
+			return "arguments and body have side effects"
+		}
+		if hasShortCircuit(body) {
+			return "argument with side effects could be skipped by 'and' or 'or'"
+		}
+		for i := range uses {
+			if uses[i] != i {
+				return "parameters are not used in order"
+			}
+		}
+	}
+	return ""
+}
+
+//
+//
+//
+
+// Returns the single expression of the function body, if the function can be
+// inlined.
+func inlinableBody(fn *ast.FnDecl) (ast.Node, bool) {
+	if len(fn.ValueExpr.Exprs) != 1 {
+		return nil, false
+	}
+
+	expr := fn.ValueExpr.Exprs[0]
+	if ret, ok := expr.(*ast.Return); ok {
+		if !ret.ValueExpr.Has() {
+			return nil, false
+		}
+		expr = ret.ValueExpr.Unwrap()
+	}
+
+	if expressionSize(expr) < 0 {
+		return nil, false
+	}
+	return expr, true
+}
+
+// Counts the nodes of the expression, or returns -1 if the expression contains
+// nodes that cannot be inlined.
+func expressionSize(node ast.Node) int {
+	sum := func(nodes ...ast.Node) int {
+		total := 1
+		for _, n := range nodes {
+			s := expressionSize(n)
+			if s < 0 {
+				return -1
+			}
+			total += s
+		}
+		return total
+	}
+
+	switch n := node.(type) {
+	case *ast.Int, *ast.Float, *ast.String, *ast.Bool, *ast.VarIdent, *ast.Access:
+		return 1
+	case *ast.UnaryOp:
+		return sum(n.RightExpr)
+	case *ast.BinOp:
+		return sum(n.LeftExpr, n.RightExpr)
+	case *ast.Application:
+		return sum(append([]ast.Node{n.Target}, n.Args...)...)
+	}
+	return -1
+}
+
+func hasShortCircuit(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.UnaryOp:
+		return hasShortCircuit(n.RightExpr)
+	case *ast.BinOp:
+		return n.Op == "and" || n.Op == "or" || hasShortCircuit(n.LeftExpr) || hasShortCircuit(n.RightExpr)
+	}
+	return false
+}
+
+// Checks if the expression can be duplicated without cost.
+func isTrivial(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Int, *ast.Float, *ast.String, *ast.Bool, *ast.VarIdent, *ast.Access:
+		return true
+	}
+	return false
+}
+
+// Copies an inlinable expression, placing the new nodes at the `at` node. The
+// `replace` function may return a node to be used instead of an identifier.
+func cloneExpression(node ast.Node, at ast.Node, replace func(*ast.VarIdent) ast.Node) ast.Node {
+	tok := at.GetToken()
+	var res ast.Node
+	switch n := node.(type) {
+	case *ast.VarIdent:
+		if replace != nil {
+			if r := replace(n); r != nil {
+				return r
+			}
+		}
+		res = ast.NewVarIdent(tok, n.Value)
+	case *ast.Access:
+		target := ast.NewVarIdent(tok, n.Target.(*ast.VarIdent).Value)
+		n.Target.GetType().If(func(tp ast.Type) { target.SetType(tp) })
+		name := ast.NewVarIdent(tok, n.Name.Value)
+		n.Name.GetType().If(func(tp ast.Type) { name.SetType(tp) })
+		res = ast.NewAccess(tok, target, name)
+	case *ast.UnaryOp:
+		res = ast.NewUnaryOp(tok, n.Op, cloneExpression(n.RightExpr, at, replace))
+	case *ast.BinOp:
+		res = ast.NewBinOp(tok, n.Op, cloneExpression(n.LeftExpr, at, replace), cloneExpression(n.RightExpr, at, replace))
+	case *ast.Application:
+		args := []ast.Node{}
+		for _, a := range n.Args {
+			args = append(args, cloneExpression(a, at, replace))
+		}
+		res = ast.NewApplication(tok, cloneExpression(n.Target, at, replace), args)
+	default:
+		return copyLiteral(node, at)
+	}
+	node.GetType().If(func(tp ast.Type) { res.SetType(tp) })
+	return res
+}
@@ -0,0 +1,93 @@
+package optimizations_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlining(t *testing.T) {
+	const (
+		small  = "fn g(a Int) Int { a * 2 }\n"
+		large  = "fn g(a Int, b Int) Int { a + b + a + b + a }\n"
+		impure = "@noinline\nfn h() Int { 1 }\n" // calls of `h` are not pure
+	)
+
+	tests := []struct {
+		name     string
+		level    int
+		decls    string // declarations used by `f`
+		call     string // body of `f(x Int) Int`
+		expected string // body of `f` after inlining
+		remark   string
+	}{
+		{
+			"small function", optimizations.LevelDefault, small, "g(x)",
+			"(bin-op * (var-ident x) (int 2))",
+			"inlined 'g' (size 3)",
+		},
+		{
+			"over the default threshold", optimizations.LevelDefault, large, "g(x, 1)",
+			"(application (var-ident g) (var-ident x) (int 1))",
+			"not inlining 'g': size 9 exceeds the limit of 8",
+		},
+		{
+			"under the aggressive threshold", optimizations.LevelAggressive, large, "g(x, 1)",
+			"(bin-op + (bin-op + (bin-op + (bin-op + (var-ident x) (int 1)) (var-ident x)) (int 1)) (var-ident x))",
+			"inlined 'g' (size 9)",
+		},
+		{
+			"annotated with @inline", optimizations.LevelDefault, "@inline\n" + large, "g(x, 1)",
+			"(bin-op + (bin-op + (bin-op + (bin-op + (var-ident x) (int 1)) (var-ident x)) (int 1)) (var-ident x))",
+			"inlined 'g' (size 9)",
+		},
+		{
+			"annotated with @noinline", optimizations.LevelDefault, "@noinline\n" + small, "g(x)",
+			"(application (var-ident g) (var-ident x))",
+			"not inlining 'g': annotated with @noinline",
+		},
+		{
+			"recursive function", optimizations.LevelDefault, "fn g(a Int) Int { g(a) }\n", "g(x)",
+			"(application (var-ident g) (var-ident x))",
+			"not inlining 'g': function is recursive",
+		},
+		{
+			"impure argument used once", optimizations.LevelDefault, small + impure, "g(h())",
+			"(bin-op * (application (var-ident h)) (int 2))",
+			"inlined 'g' (size 3)",
+		},
+		{
+			"impure argument used twice", optimizations.LevelDefault, "fn g(a Int) Int { a + a }\n" + impure, "g(h())",
+			"(application (var-ident g) (application (var-ident h)))",
+			"not inlining 'g': argument with side effects is not used exactly once",
+		},
+		{
+			"impure argument and body", optimizations.LevelDefault, "fn g(a Int) Int { a + h() }\n" + impure, "g(h())",
+			"(application (var-ident g) (application (var-ident h)))",
+			"not inlining 'g': arguments and body have side effects",
+		},
+		{
+			"impure arguments out of order", optimizations.LevelDefault, "fn g(a Int, b Int) Int { b - a }\n" + impure, "g(h(), h())",
+			"(application (var-ident g) (application (var-ident h)) (application (var-ident h)))",
+			"not inlining 'g': parameters are not used in order",
+		},
+		{
+			"shadowed module name", optimizations.LevelDefault, "let limit = 10\nfn g(a Int) Int { a + limit }\n", "let limit = 2\n  g(x)",
+			"(application (var-ident g) (var-ident x))",
+			"not inlining 'g': 'limit' is shadowed at the call site",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.decls + "fn f(x Int) Int {\n  " + tt.call + "\n}\nfn main() {}\n"
+			root, remarks, err := optimizeAt(t, tt.level, source, "inline")
+			require.NoError(t, err)
+			assert.Contains(t, body(t, root, "f"), tt.expected)
+			assert.Contains(t, strings.Join(remarks, "\n"), tt.remark)
+		})
+	}
+}
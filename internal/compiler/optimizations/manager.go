@@ -0,0 +1,129 @@
+package optimizations
+
+import (
+	"time"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/renatopp/golden/internal/helpers/str"
+)
+
+// PassManager runs the passes enabled by the optimization level, or the ones
+// explicitly selected, respecting their dependencies.
+type PassManager struct {
+	Level    int
+	Passes   []*Pass
+	OnReport func(*PassReport)
+	timings  map[*Pass]time.Duration
+}
+
+// Creates a manager for the given level. If `selection` is not empty, only
+// the selected passes (and their dependencies) run, besides the required
+// ones.
+func NewPassManager(level int, selection []string) *PassManager {
+	enabled := map[*Pass]bool{}
+	for _, p := range registry {
+		if p.Required || len(selection) == 0 && p.Level <= level {
+			enabled[p] = true
+		}
+	}
+	for _, name := range selection {
+		p := GetPass(name)
+		if p == nil {
+			names := str.MapHumanList(registry, func(p *Pass) string { return "'" + p.Name + "'" }, "or")
+			errors.Throw(errors.UnknownPass, "unknown pass '%s', expected one of %s", name, names)
+		}
+		enabled[p] = true
+	}
+
+	return &PassManager{
+		Level:   level,
+		Passes:  resolvePassOrder(enabled),
+		timings: map[*Pass]time.Duration{},
+	}
+}
+
+// Checks if any enabled pass analyzes the whole program before running.
+func (m *PassManager) AnalyzesProgram() bool {
+	for _, p := range m.Passes {
+		if p.Analyze != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Orders the enabled passes so every pass runs after its dependencies,
+// enabling the dependencies that were not enabled yet.
+func resolvePassOrder(enabled map[*Pass]bool) []*Pass {
+	order := []*Pass{}
+	visited := map[*Pass]bool{}
+	visiting := map[*Pass]bool{}
+
+	var visit func(p *Pass)
+	visit = func(p *Pass) {
+		if visited[p] {
+			return
+		}
+		if visiting[p] {
+			errors.Throw(errors.InternalError, "cyclic dependency between optimization passes at '%s'", p.Name)
+		}
+		visiting[p] = true
+		for _, name := range p.Requires {
+			dep := GetPass(name)
+			if dep == nil {
+				errors.Throw(errors.InternalError, "pass '%s' requires unknown pass '%s'", p.Name, name)
+			}
+			visit(dep)
+		}
+		visiting[p] = false
+		visited[p] = true
+		order = append(order, p)
+	}
+
+	for _, p := range registry {
+		if enabled[p] {
+			visit(p)
+		}
+	}
+	return order
+}
+
+// Runs each pass over all modules of the program, replacing the modules with
+// the transformed ones.
+func (m *PassManager) Run(program *Program) {
+	for _, p := range m.Passes {
+		var analysis any
+		if p.Analyze != nil {
+			start := time.Now()
+			analysis = p.Analyze(program)
+			m.timings[p] += time.Since(start)
+		}
+
+		for i, node := range program.Modules {
+			ctx := &PassContext{Pass: p, Level: m.Level, Program: program, Analysis: analysis}
+			start := time.Now()
+			node = node.Visit(p.New(ctx)).(*ast.Module)
+			elapsed := time.Since(start)
+			m.timings[p] += elapsed
+
+			if program.Entry == program.Modules[i] {
+				program.Entry = node
+			}
+			program.Modules[i] = node
+
+			if m.OnReport != nil {
+				m.OnReport(&PassReport{Pass: p, Module: node, Elapsed: elapsed, Remarks: ctx.Remarks})
+			}
+		}
+	}
+}
+
+// Returns the accumulated time of each pass, in execution order.
+func (m *PassManager) Timings() []PassTiming {
+	res := []PassTiming{}
+	for _, p := range m.Passes {
+		res = append(res, PassTiming{Pass: p, Elapsed: m.timings[p]})
+	}
+	return res
+}
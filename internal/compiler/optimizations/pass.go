@@ -0,0 +1,59 @@
+package optimizations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// Pass describes a transformation over the AST. Passes are registered with a
+// name, so they can be selected, ordered by their dependencies and reported
+// individually.
+type Pass struct {
+	Name        string
+	Description string
+	Level       int                        // Minimum optimization level that enables the pass
+	Required    bool                       // Lowering passes that always run, regardless of the level or selection
+	Requires    []string                   // Passes that must run before this one
+	Analyze     func(program *Program) any // Optional whole-program analysis, run once before visiting the modules
+	New         func(ctx *PassContext) ast.Visitor
+}
+
+// Program is the set of modules being compiled, in dependency order.
+type Program struct {
+	Modules []*ast.Module
+	Entry   *ast.Module
+}
+
+// PassContext is given to each pass instance, allowing the pass to report its
+// decisions.
+type PassContext struct {
+	Pass     *Pass
+	Level    int
+	Program  *Program
+	Analysis any // Result of the pass analysis, if any
+	Remarks  []string
+}
+
+func (c *PassContext) Remark(node ast.Node, msg string, args ...any) {
+	loc := ""
+	if tok := node.GetToken(); tok != nil && tok.Loc != nil {
+		loc = fmt.Sprintf("%d:%d: ", tok.Loc.FromLine, tok.Loc.FromColumn)
+	}
+	c.Remarks = append(c.Remarks, loc+fmt.Sprintf(msg, args...))
+}
+
+// PassReport is the result of running a pass over a module.
+type PassReport struct {
+	Pass    *Pass
+	Module  *ast.Module
+	Elapsed time.Duration
+	Remarks []string
+}
+
+// PassTiming is the accumulated time of a pass over all modules.
+type PassTiming struct {
+	Pass    *Pass
+	Elapsed time.Duration
+}
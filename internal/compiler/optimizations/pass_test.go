@@ -0,0 +1,81 @@
+package optimizations_test
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/renatopp/golden/internal/helpers/debug"
+	"github.com/stretchr/testify/require"
+)
+
+// Checks the source as the entry of a project and runs the selected passes,
+// returning the optimized entry module.
+func optimize(t *testing.T, source string, passes ...string) (*ast.Module, error) {
+	root, _, err := optimizeAt(t, optimizations.LevelDefault, source, passes...)
+	return root, err
+}
+
+// Runs the selected passes at the optimization level, also returning the
+// remarks of the passes on the entry module.
+func optimizeAt(t *testing.T, level int, source string, passes ...string) (*ast.Module, []string, error) {
+	mods, remarks, err := optimizeProject(t, level, map[string]string{"main.gold": source}, passes...)
+	return mods["main.gold"], remarks, err
+}
+
+// Runs the selected passes over the project with the entry `main.gold`,
+// returning the optimized modules of the project by file name and the remarks
+// of the passes on them.
+func optimizeProject(t *testing.T, level int, files map[string]string, passes ...string) (map[string]*ast.Module, []string, error) {
+	dir := buildertest.Project(t, files)
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	opts.OptimizationLevel = level
+	opts.Passes = passes
+	opts.NoCache = true
+
+	name := func(f *builder.File) (string, bool) {
+		rel, err := filepath.Rel(dir, f.Path)
+		return filepath.ToSlash(rel), err == nil && !strings.HasPrefix(rel, "..")
+	}
+	mods := map[string]*ast.Module{}
+	remarks := []string{}
+	opts.OnOptimizationReady.Subscribe(func(f *builder.File, mod *ast.Module) {
+		if rel, ok := name(f); ok {
+			mods[rel] = mod
+		}
+	})
+	opts.OnPassReady.Subscribe(func(f *builder.File, r *optimizations.PassReport) {
+		if _, ok := name(f); ok {
+			remarks = append(remarks, r.Remarks...)
+		}
+	})
+	_, err := builder.NewBuilder(opts).Lower()
+	return mods, remarks, err
+}
+
+// Returns the function declared in the module, or nil.
+func function(root *ast.Module, name string) *ast.FnDecl {
+	for _, expr := range root.Exprs {
+		if fn, ok := expr.(*ast.FnDecl); ok && fn.Name.Has() && fn.Name.Unwrap().Value == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+var typeAttr = regexp.MustCompile(` :type "[^"]*"`)
+
+// Returns the body of the function as a s-expression in a single line,
+// without the types of the nodes.
+func body(t *testing.T, root *ast.Module, name string) string {
+	fn := function(root, name)
+	require.NotNil(t, fn, "function '%s' not found", name)
+	sb := &strings.Builder{}
+	require.NoError(t, debug.PrintAst(sb, "main.gold", fn.ValueExpr, debug.FormatSexpr))
+	return typeAttr.ReplaceAllString(strings.Join(strings.Fields(sb.String()), " "), "")
+}
@@ -0,0 +1,63 @@
+package optimizations
+
+import "github.com/renatopp/golden/internal/compiler/ast"
+
+const (
+	LevelNone       = 0 // -O0, only lowering passes
+	LevelDefault    = 1 // -O1, cheap optimizations
+	LevelAggressive = 2 // -O2, optimizations that may increase code size
+)
+
+// Registered passes, in the order they run when no dependency says otherwise.
+var registry = []*Pass{
+	{
+		Name:        "add-return",
+		Description: "turns the implicit return of functions into explicit returns",
+		Level:       LevelNone,
+		Required:    true,
+		New:         func(*PassContext) ast.Visitor { return NewAddReturnToFunctions() },
+	},
+	{
+		Name:        "const-fold",
+		Description: "evaluates constant expressions and propagates constant variables",
+		Level:       LevelDefault,
+		New:         func(ctx *PassContext) ast.Visitor { return NewConstantFolding(ctx) },
+	},
+	{
+		Name:        "inline",
+		Description: "replaces calls to small functions by their bodies",
+		Level:       LevelDefault,
+		Requires:    []string{"add-return"},
+		New:         func(ctx *PassContext) ast.Visitor { return NewInlining(ctx) },
+	},
+	{
+		Name:        "tail-calls",
+		Description: "marks self tail calls, which backends turn into loops",
+		Level:       LevelNone,
+		Requires:    []string{"add-return"},
+		New:         func(ctx *PassContext) ast.Visitor { return NewTailCalls(ctx) },
+	},
+	{
+		Name:        "dce",
+		Description: "removes declarations unreachable from main and code after returns",
+		Level:       LevelDefault,
+		Requires:    []string{"add-return"},
+		Analyze:     analyzeLiveness,
+		New:         func(ctx *PassContext) ast.Visitor { return NewDeadCodeElimination(ctx) },
+	},
+}
+
+// Returns the pass registered with the given name, or nil.
+func GetPass(name string) *Pass {
+	for _, p := range registry {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Returns all registered passes.
+func Passes() []*Pass {
+	return append([]*Pass{}, registry...)
+}
@@ -0,0 +1,135 @@
+package optimizations
+
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+)
+
+// TailCalls marks the returns that call the enclosing function, so backends
+// can replace the call by a jump to the beginning of the function, running
+// tail-recursive functions in constant stack space.
+//
+// Functions containing other functions are skipped, since their closures could
+// capture parameters that the loop reassigns.
+type TailCalls struct {
+	*ast.Visiter
+	ctx       *PassContext
+	functions []*ast.FnDecl // enclosing functions eligible for elimination, nil if not eligible
+}
+
+func NewTailCalls(ctx *PassContext) *TailCalls {
+	opt := &TailCalls{ctx: ctx}
+	opt.Visiter = ast.NewVisiter(opt)
+	return opt
+}
+
+func (t *TailCalls) VisitFnDecl(node *ast.FnDecl) ast.Node {
+	var eligible *ast.FnDecl
+	if node.Name.Has() && !hasNestedFunctions(node.ValueExpr) && !shadowsName(node, node.Name.Unwrap().Value) {
+		eligible = node
+	}
+
+	t.functions = append(t.functions, eligible)
+	defer func() { t.functions = t.functions[:len(t.functions)-1] }()
+	node.ValueExpr = node.ValueExpr.Visit(t).(*ast.Block)
+	return node
+}
+
+func (t *TailCalls) VisitReturn(node *ast.Return) ast.Node {
+	if len(t.functions) == 0 || !node.ValueExpr.Has() {
+		return node
+	}
+	fn := t.functions[len(t.functions)-1]
+	if fn == nil {
+		return node
+	}
+
+	call, ok := node.ValueExpr.Unwrap().(*ast.Application)
+	if !ok {
+		return node
+	}
+	target, ok := call.Target.(*ast.VarIdent)
+	if !ok || target.Value != fn.Name.Unwrap().Value {
+		return node
+	}
+
+	node.TailCall = true
+	fn.TailCalls = true
+	t.ctx.Remark(node, "eliminated tail call to '%s'", target.Value)
+	return node
+}
+
+//
+//
+//
+
+func hasNestedFunctions(node ast.Node) bool {
+	found := false
+	var visit func(n ast.Node)
+	visit = func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FnDecl:
+			found = true
+		case *ast.Block:
+			for _, e := range n.Exprs {
+				visit(e)
+			}
+		case *ast.VarDecl:
+			visit(n.ValueExpr)
+		case *ast.Return:
+			n.ValueExpr.If(visit)
+		case *ast.BinOp:
+			visit(n.LeftExpr)
+			visit(n.RightExpr)
+		case *ast.UnaryOp:
+			visit(n.RightExpr)
+		case *ast.Application:
+			visit(n.Target)
+			for _, a := range n.Args {
+				visit(a)
+			}
+		}
+	}
+	visit(node)
+	return found
+}
+
+// Checks if a parameter or local variable of the function hides the name, in
+// any of the scopes nested in its body.
+func shadowsName(fn *ast.FnDecl, name string) bool {
+	found := false
+	var visit func(n ast.Node)
+	visit = func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FnDecl:
+			n.Name.If(func(v *ast.VarIdent) { found = found || v.Value == name })
+			for _, p := range n.Params {
+				found = found || p.Name.Value == name
+			}
+			visit(n.ValueExpr)
+		case *ast.Block:
+			for _, e := range n.Exprs {
+				visit(e)
+			}
+		case *ast.VarDecl:
+			found = found || n.Name.Value == name
+			visit(n.ValueExpr)
+		case *ast.Return:
+			n.ValueExpr.If(visit)
+		case *ast.BinOp:
+			visit(n.LeftExpr)
+			visit(n.RightExpr)
+		case *ast.UnaryOp:
+			visit(n.RightExpr)
+		case *ast.Application:
+			visit(n.Target)
+			for _, a := range n.Args {
+				visit(a)
+			}
+		}
+	}
+	for _, p := range fn.Params {
+		found = found || p.Name.Value == name
+	}
+	visit(fn.ValueExpr)
+	return found
+}
@@ -0,0 +1,108 @@
+package optimizations_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/optimizations"
+	"github.com/renatopp/golden/internal/compiler/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Counts the returns of the function marked as tail calls.
+func tailCalls(fn *ast.FnDecl) int {
+	count := 0
+	ast.Walk(fn, func(n ast.Node) {
+		if ret, ok := n.(*ast.Return); ok && ret.TailCall {
+			count++
+		}
+	})
+	return count
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string // declarations besides `main`, including `f`
+		expected int    // tail calls marked in `f`
+		remarks  []string
+	}{
+		{
+			name:     "self tail call",
+			source:   "fn f(n Int) Int {\n  return f(n + 1)\n}\n",
+			expected: 1,
+			remarks:  []string{"2:3: eliminated tail call to 'f'"},
+		},
+		{
+			name:     "implicit return",
+			source:   "fn f(n Int) Int {\n  f(n + 1)\n}\n",
+			expected: 1,
+			remarks:  []string{"2:4: eliminated tail call to 'f'"},
+		},
+		{
+			name:     "call in an operand",
+			source:   "fn f(n Int) Int {\n  1 + f(n)\n}\n",
+			expected: 0,
+			remarks:  []string{},
+		},
+		{
+			name:     "call to another function",
+			source:   "fn g(n Int) Int { n }\nfn f(n Int) Int {\n  return g(n)\n}\n",
+			expected: 0,
+			remarks:  []string{},
+		},
+		{
+			name:     "name shadowed by a local",
+			source:   "fn g(n Int) Int { n }\nfn f(n Int) Int {\n  let f = g\n  return f(n)\n}\n",
+			expected: 0,
+			remarks:  []string{},
+		},
+		{
+			name:     "nested function",
+			source:   "fn f(n Int) Int {\n  let g = fn (x Int) Int { x + n }\n  return f(g(n))\n}\n",
+			expected: 0,
+			remarks:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, remarks, err := optimizeAt(t, optimizations.LevelNone, tt.source+"fn main() {}\n", "tail-calls")
+			require.NoError(t, err)
+			fn := function(root, "f")
+			require.NotNil(t, fn)
+			assert.Equal(t, tt.expected, tailCalls(fn))
+			assert.Equal(t, tt.expected > 0, fn.TailCalls)
+			assert.Equal(t, tt.remarks, remarks)
+		})
+	}
+}
+
+// Blocks cannot be written inside other blocks yet, but passes may create
+// them, so the body of `f` is moved into a nested block.
+func TestTailCallsNestedBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		local    string
+		expected int
+	}{
+		{"name not shadowed", "let h = g", 1},
+		{"name shadowed", "let f = g", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := "fn f(n Int) Int {\n  " + tt.local + "\n  return f(n)\n}\n"
+			tokens, err := syntax.NewLexer("main.gold", []byte(source)).Lex()
+			require.NoError(t, err)
+			root, err := syntax.NewParser(tokens).Parse()
+			require.NoError(t, err)
+
+			fn := function(root, "f")
+			fn.ValueExpr.Exprs = []ast.Node{ast.NewBlock(fn.ValueExpr.Token, fn.ValueExpr.Exprs)}
+			fn.Visit(optimizations.NewTailCalls(&optimizations.PassContext{}))
+			assert.Equal(t, tt.expected, tailCalls(fn))
+		})
+	}
+}
@@ -2,13 +2,17 @@ package semantic
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/builtins"
 	"github.com/renatopp/golden/internal/compiler/env"
 	"github.com/renatopp/golden/internal/compiler/types"
 	"github.com/renatopp/golden/internal/helpers/ds"
 	"github.com/renatopp/golden/internal/helpers/errors"
 	"github.com/renatopp/golden/internal/helpers/iter"
+	"github.com/renatopp/golden/internal/helpers/naming"
 	"github.com/renatopp/golden/internal/helpers/safe"
 	"github.com/renatopp/golden/internal/helpers/str"
 )
@@ -20,6 +24,7 @@ type Checker struct {
 	state               *State
 	scopeStack          *ds.Stack[*env.Scope]
 	initializationStack *ds.Stack[ast.Node]
+	Warnings            []errors.GoldenError
 }
 
 func NewChecker() *Checker {
@@ -27,6 +32,7 @@ func NewChecker() *Checker {
 		state:               NewState(),
 		scopeStack:          ds.NewStack[*env.Scope](),
 		initializationStack: ds.NewStack[ast.Node](),
+		Warnings:            []errors.GoldenError{},
 	}
 }
 
@@ -54,9 +60,13 @@ func (c *Checker) scope() *env.Scope {
 func (c *Checker) declare(name ast.Node, node ast.Node, tp ast.Type) {
 	scope := c.scope().Values
 	lit := name.GetToken().Literal
+	checkReservedName(name, lit)
 	bind := scope.GetLocal(lit, nil)
-	if bind != nil && bind.IsSolved() {
-		errors.ThrowAtNode(name, errors.NameAlreadyDefined, "name '%s' already defined", lit)
+	// recursive functions solve their own binding while being initialized,
+	// before being declared
+	isSelf := bind != nil && bind.DefinitionNode == node && c.initializationStack.Has(node)
+	if bind != nil && bind.IsSolved() && !isSelf {
+		alreadyDefined(name, lit, bind)
 	}
 
 	if bind != nil {
@@ -66,13 +76,75 @@ func (c *Checker) declare(name ast.Node, node ast.Node, tp ast.Type) {
 	}
 }
 
+func alreadyDefined(name ast.Node, lit string, bind *env.ValueBinding) {
+	err := errors.NewError(errors.NameAlreadyDefined, "name '%s' already defined", lit).WithNode(name)
+	if bind.DefinitionNode != nil {
+		err = err.WithLabel(definitionName(bind.DefinitionNode).GetSpan(), "'%s' first defined here", lit)
+	}
+	errors.ThrowError(err)
+}
+
+// Names starting with `__` are used by the compiler, see `naming.IsReservedName`.
+func checkReservedName(name ast.Node, lit string) {
+	if naming.IsReservedName(lit) {
+		errors.ThrowAtNode(name, errors.ReservedName, "name '%s' is reserved to the compiler", lit)
+	}
+}
+
+// Returns the node naming the definition, used to point to the definition in
+// errors.
+func definitionName(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.VarDecl:
+		return n.Name
+	case *ast.FnDecl:
+		if n.Name.Has() {
+			return n.Name.Unwrap()
+		}
+	case *ast.FnDeclParam:
+		return n.Name
+	case *ast.ExternFnDecl:
+		return n.Name
+	}
+	return node
+}
+
+// When the function body ends declaring a variable of the return type, the
+// variable is given as fix to the missing return.
+func returnLastDeclaration(err errors.GoldenError, body *ast.Block, tp ast.Type) errors.GoldenError {
+	if len(body.Exprs) == 0 {
+		return err
+	}
+	decl, ok := body.Exprs[len(body.Exprs)-1].(*ast.VarDecl)
+	if !ok || !decl.Name.GetType().Has() || !tp.IsCompatible(decl.Name.GetType().Unwrap()) {
+		return err
+	}
+	span := decl.GetSpan()
+	name := decl.Name.Value
+	indent := strings.Repeat(" ", max(0, span.FromColumn-1))
+	return err.WithFix(fmt.Sprintf("return '%s'", name), errors.InsertAfter(span, "\n"+indent+"return "+name))
+}
+
+// Adds the names close to the missing one as suggestion to the error. The
+// closest name is also given as fix.
+func suggestNames(err errors.GoldenError, node ast.Node, name string, visible []string) errors.GoldenError {
+	names := str.Closest(name, visible)
+	if len(names) == 0 {
+		return err
+	}
+	names = names[:min(len(names), 3)]
+	return err.WithHelp("did you mean %s?", str.MapHumanList(names, func(n string) string {
+		return fmt.Sprintf("'%s'", n)
+	}, "or")).WithFix(fmt.Sprintf("replace '%s' with '%s'", name, names[0]), errors.Replace(node.GetSpan(), names[0]))
+}
+
 // Initialization Stack
 
 func (c *Checker) pushInitialization(node ast.Node) {
 	for _, e := range c.initializationStack.Iter() {
 		if e.IsEqual(node) {
 			// TODO: improve error message
-			errors.ThrowAtNode(node, errors.CircularReferenceError, "circular initialization detected")
+			errors.ThrowAtNode(node, errors.CircularInitialization, "circular initialization detected")
 		}
 	}
 	c.initializationStack.Push(node)
@@ -81,6 +153,27 @@ func (c *Checker) popInitialization() ast.Node {
 	return c.initializationStack.Pop(nil)
 }
 
+// Referencing a function that is still being initialized means that the
+// function reaches itself, so every function in the cycle is recursive.
+func (c *Checker) markRecursion(fn *ast.FnDecl) {
+	if !c.initializationStack.Has(fn) {
+		return
+	}
+	inCycle := false
+	for _, e := range c.initializationStack.Iter() {
+		inCycle = inCycle || e == fn
+		if n, ok := e.(*ast.FnDecl); ok && inCycle {
+			n.Recursive = true
+		}
+	}
+}
+
+// Warnings
+
+func (c *Checker) warn(w errors.GoldenError) {
+	c.Warnings = append(c.Warnings, w)
+}
+
 // Checks
 
 func (c *Checker) expectNodeWithCompatibleType(node ast.Node, types ...ast.Type) {
@@ -97,13 +190,13 @@ func (c *Checker) expectNodeWithCompatibleType(node ast.Node, types ...ast.Type)
 	}
 
 	if len(types) == 1 {
-		errors.ThrowAtNode(node, errors.TypeError, "expected type '%s', but got '%s'", types[0].GetSignature(), tp.GetSignature())
+		errors.ThrowAtNode(node, errors.TypeMismatch, "expected type '%s', but got '%s'", types[0].GetSignature(), tp.GetSignature())
 	}
 
 	names := str.MapHumanList(types, func(t ast.Type) string {
 		return fmt.Sprintf("'%s'", t.GetSignature())
 	}, "or")
-	errors.ThrowAtNode(node, errors.TypeError, "expected one of  %s, but got '%s'", names, tp.GetSignature())
+	errors.ThrowAtNode(node, errors.TypeMismatch, "expected one of %s, but got '%s'", names, tp.GetSignature())
 }
 func (c *Checker) expectCompatibleNodeTypes(receiver, giver ast.Node) {
 	aWrappedType := receiver.GetType()
@@ -121,7 +214,7 @@ func (c *Checker) expectCompatibleNodeTypes(receiver, giver ast.Node) {
 	giverType := bWrappedType.Unwrap()
 
 	if !receiverType.IsCompatible(giverType) {
-		errors.ThrowAtNode(receiver, errors.TypeError, "expected type '%s', but got '%s'", receiverType.GetSignature(), giverType.GetSignature())
+		errors.ThrowAtNode(receiver, errors.TypeMismatch, "expected type '%s', but got '%s'", receiverType.GetSignature(), giverType.GetSignature())
 	}
 }
 
@@ -135,19 +228,35 @@ func (c *Checker) PreCheck(root *ast.Module) {
 	for _, e := range root.Exprs {
 		switch n := e.(type) {
 		case *ast.VarDecl:
-			v := n.Name.Value
-			c.scope().Values.Set(v, env.VB(n, nil))
+			c.preDeclare(n.Name, n)
 		case *ast.FnDecl:
 			if n.Name.Has() {
-				v := n.Name.Unwrap().Value
-				c.scope().Values.Set(v, env.VB(n, nil))
+				c.preDeclare(n.Name.Unwrap(), n)
 			} else {
 				errors.ThrowAtNode(n, errors.InternalError, "functions must have a name in module scope")
 			}
+		case *ast.ExternFnDecl:
+			c.preDeclare(n.Name, n)
 		}
 	}
 }
 
+// Declares the module name before its type is known. Module declarations
+// cannot reuse the alias of an imported module nor the name of another
+// declaration.
+func (c *Checker) preDeclare(name *ast.VarIdent, node ast.Node) {
+	checkReservedName(name, name.Value)
+	if bind := c.scope().Values.GetLocal(name.Value, nil); bind != nil {
+		if _, ok := bind.Type.(*types.Module); ok {
+			errors.ThrowAtNode(name, errors.NameAlreadyDefined, "name '%s' already defined by an import", name.Value)
+		}
+		if bind.DefinitionNode != nil && bind.DefinitionNode != node {
+			alreadyDefined(name, name.Value, bind)
+		}
+	}
+	c.scope().Values.Set(name.Value, env.VB(node, nil))
+}
+
 func (c *Checker) Check(root *ast.Module) (res *ast.Module, err error) {
 	err = errors.WithRecovery(func() {
 		res = c.VisitModule(root).(*ast.Module)
@@ -222,7 +331,19 @@ func (c *Checker) VisitVarIdent(node *ast.VarIdent) ast.Node {
 	name := node.Value
 	bind := c.scope().Values.Get(name, nil)
 	if bind == nil {
-		errors.ThrowAtNode(node, errors.NameNotFound, "variable '%s' not defined", name)
+		prelude := c.preludeNames()
+		if slices.Contains(prelude, name) {
+			access := ast.NewAccess(node.Token, ast.NewVarIdent(node.Token, builtins.PreludeAlias), node)
+			return access.Visit(c)
+		}
+		err := errors.NewError(errors.NameNotFound, "variable '%s' not defined", name).WithNode(node)
+		errors.ThrowError(suggestNames(err, node, name, append(c.scope().Values.Names(), prelude...)))
+	}
+	if _, ok := bind.Type.(*types.Module); ok {
+		errors.ThrowAtNode(node, errors.TypeMismatch, "module '%s' cannot be used as a value, only its members can", name)
+	}
+	if fn, ok := bind.DefinitionNode.(*ast.FnDecl); ok {
+		c.markRecursion(fn)
 	}
 	if !bind.IsSolved() {
 		bind.LastNode.Visit(c)
@@ -233,13 +354,76 @@ func (c *Checker) VisitVarIdent(node *ast.VarIdent) ast.Node {
 	return node
 }
 
+// Returns the names exported by the prelude, if the module imports it. Names
+// not declared by the module are accessed as members of the prelude.
+func (c *Checker) preludeNames() []string {
+	bind := c.scope().Values.Get(builtins.PreludeAlias, nil)
+	if bind == nil {
+		return []string{}
+	}
+	return exportedNames(bind.Type.(*types.Module))
+}
+
+// Resolves the member in the scope of the imported module. Private members
+// and the modules imported by it are not visible.
+func (c *Checker) VisitAccess(node *ast.Access) ast.Node {
+	c.pushState(node)
+	defer c.popState()
+	target, ok := node.Target.(*ast.VarIdent)
+	if !ok {
+		errors.ThrowAtNode(node.Target, errors.TypeMismatch, "only members of modules can be accessed")
+	}
+	bind := c.scope().Values.Get(target.Value, nil)
+	if bind == nil {
+		err := errors.NewError(errors.NameNotFound, "module '%s' not imported", target.Value).WithNode(target)
+		errors.ThrowError(suggestNames(err, target, target.Value, c.scope().Values.Names()))
+	}
+	mod, ok := bind.Type.(*types.Module)
+	if !ok {
+		errors.ThrowAtNode(target, errors.TypeMismatch, "value of type '%s' has no members", bind.Type.GetSignature())
+	}
+	target.SetType(mod)
+
+	name := node.Name.Value
+	members := exportedNames(mod)
+	if !slices.Contains(members, name) {
+		err := errors.NewError(errors.NameNotFound, "module '%s' has no member '%s'", target.Value, name).WithNode(node.Name)
+		if naming.IsPrivateName(name) && mod.Scope.Values.GetLocal(name, nil) != nil {
+			err = err.WithNote("'%s' is private to the module", name)
+		}
+		errors.ThrowError(suggestNames(err, node.Name, name, members))
+	}
+	member := mod.Scope.Values.GetLocal(name, nil)
+	if !member.IsSolved() {
+		errors.ThrowAtNode(node.Name, errors.InternalError, "member '%s' of module '%s' used before being checked", name, target.Value)
+	}
+	node.Name.SetType(member.Type)
+	node.SetType(member.Type)
+	return node
+}
+
+// Returns the public declarations of the module, which can be accessed by the
+// modules importing it.
+func exportedNames(mod *types.Module) []string {
+	names := []string{}
+	for name, bind := range mod.Scope.Values.Bindings {
+		if _, ok := bind.Type.(*types.Module); ok || naming.IsPrivateName(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 func (c *Checker) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
 	c.pushState(node)
 	defer c.popState()
 	name := node.Value
 	bind := c.scope().Types.Get(name, nil)
 	if bind == nil {
-		errors.ThrowAtNode(node, errors.NameNotFound, "type '%s' not defined", name)
+		err := errors.NewError(errors.TypeNotFound, "type '%s' not defined", name).WithNode(node)
+		errors.ThrowError(suggestNames(err, node, name, c.scope().Types.Names()))
 	}
 	if !bind.IsSolved() {
 		bind.DefinitionNode.Visit(c)
@@ -252,8 +436,8 @@ func (c *Checker) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
 func (c *Checker) VisitBinOp(node *ast.BinOp) ast.Node {
 	c.pushState(node)
 	defer c.popState()
-	node.LeftExpr.Visit(c)
-	node.RightExpr.Visit(c)
+	node.LeftExpr = node.LeftExpr.Visit(c)
+	node.RightExpr = node.RightExpr.Visit(c)
 
 	switch node.Op {
 	case "+":
@@ -296,7 +480,7 @@ func (c *Checker) VisitBinOp(node *ast.BinOp) ast.Node {
 func (c *Checker) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
 	c.pushState(node)
 	defer c.popState()
-	node.RightExpr.Visit(c)
+	node.RightExpr = node.RightExpr.Visit(c)
 
 	switch node.Op {
 	case "-", "+":
@@ -319,10 +503,27 @@ func (c *Checker) VisitBlock(node *ast.Block) ast.Node {
 	c.pushScope(c.scope().New())
 	defer c.popScope()
 
-	for _, exp := range node.Exprs {
-		exp.Visit(c)
+	node.Exprs = iter.Map(node.Exprs, func(e ast.Node) ast.Node { return e.Visit(c) })
+
+	for i, exp := range node.Exprs[:max(0, len(node.Exprs)-1)] {
+		if isDiverging(exp) {
+			unreachable := node.Exprs[i+1].GetSpan()
+			last := node.Exprs[len(node.Exprs)-1].GetSpan()
+			w := errors.NewWarning(errors.UnreachableCode, "unreachable code after return").WithNode(node.Exprs[i+1])
+			if span := exp.GetSpan(); span != nil && unreachable != nil && last != nil {
+				w = w.WithFix("remove the unreachable code", errors.Delete(span.End().Through(last)))
+			}
+			c.warn(w)
+			break
+		}
+	}
+
+	// blocks evaluate to their last expression
+	if last := blockValue(node); last.Has() {
+		node.SetType(last.Unwrap().GetType().Unwrap())
+	} else {
+		node.SetType(types.Void)
 	}
-	node.SetType(types.Void)
 	return node
 }
 
@@ -337,6 +538,7 @@ func (c *Checker) VisitFnDecl(node *ast.FnDecl) ast.Node {
 
 	c.pushInitialization(node)
 	defer c.popInitialization()
+	c.checkAnnotations(node)
 
 	fnScope := c.scope().New()
 	node.TypeExpr = node.TypeExpr.Visit(c)
@@ -349,24 +551,98 @@ func (c *Checker) VisitFnDecl(node *ast.FnDecl) ast.Node {
 	fnType := types.NewFunction(node, tps, node.TypeExpr.GetType().Unwrap())
 	node.SetType(fnType)
 
+	// declared before the body, so nested functions may call themselves
+	if node.Name.Has() {
+		name := node.Name.Unwrap()
+		name.SetType(fnType)
+		c.declare(name, node, fnType)
+	}
+
 	c.pushScope(fnScope)
 	iter.Each(node.Params, func(p *ast.FnDeclParam) { c.declare(p.Name, p, p.Type.Unwrap()) })
 	node.ValueExpr = node.ValueExpr.Visit(c).(*ast.Block)
 	c.popScope()
 
-	if node.Name.Has() {
-		name := node.Name.Unwrap()
-		name.SetType(fnType)
-		c.declare(name, node, fnType)
+	// the body must either return on every path or evaluate to the return
+	// type through its last expression
+	if fnType.Return != types.Void && !isDiverging(node.ValueExpr) {
+		last := blockValue(node.ValueExpr)
+		if !last.Has() {
+			errors.ThrowError(returnLastDeclaration(
+				errors.NewError(errors.MissingReturn, "missing return statement").WithNode(node),
+				node.ValueExpr,
+				fnType.Return,
+			))
+		}
+		c.expectNodeWithCompatibleType(last.Unwrap(), fnType.Return)
 	}
 
-	if fnType.Return != types.Void && !c.state.HasReturns() {
-		errors.ThrowAtNode(node, errors.TypeError, "missing return statement")
+	return node
+}
+
+// The signature of extern functions is trusted, only the bindings are checked.
+func (c *Checker) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	c.pushState(node)
+	defer c.popState()
+
+	if node.Type.Has() {
+		return node
 	}
 
+	c.checkExternBindings(node)
+	node.TypeExpr = node.TypeExpr.Visit(c)
+	tps := []ast.Type{}
+	for i := range node.Params {
+		node.Params[i] = node.Params[i].Visit(c).(*ast.FnDeclParam)
+		tps = append(tps, node.Params[i].Type.Unwrap())
+	}
+	fnType := types.NewFunction(node, tps, node.TypeExpr.GetType().Unwrap())
+	node.SetType(fnType)
+	node.Name.SetType(fnType)
+	c.declare(node.Name, node, fnType)
 	return node
 }
 
+func (c *Checker) checkExternBindings(node *ast.ExternFnDecl) {
+	seen := map[string]bool{}
+	for _, b := range node.Bindings {
+		target, name, ok := strings.Cut(b.Literal, ":")
+		switch {
+		case !ok || name == "":
+			errors.ThrowAtToken(b, errors.InvalidExternBinding, "expected binding in the '<target>:<name>' format, but got '%s'", b.Literal)
+		case target != "go" && target != "js":
+			errors.ThrowAtToken(b, errors.InvalidExternBinding, "unknown target '%s', expected 'go' or 'js'", target)
+		case seen[target]:
+			errors.ThrowAtToken(b, errors.InvalidExternBinding, "target '%s' is bound more than once", target)
+		case target == "go" && !isGoBinding(name):
+			errors.ThrowAtToken(b, errors.InvalidExternBinding, "expected Go binding in the '<package>.<Function>' format, but got '%s'", name)
+		}
+		seen[target] = true
+	}
+}
+
+func isGoBinding(name string) bool {
+	_, _, ok := ast.SplitGoBinding(name)
+	return ok
+}
+
+func (c *Checker) checkAnnotations(node *ast.FnDecl) {
+	for _, a := range node.Annotations {
+		switch a.Literal {
+		case "@inline", "@noinline":
+		default:
+			err := errors.NewError(errors.UnknownAnnotation, "unknown annotation '%s'", a.Literal).WithToken(a)
+			if names := str.Closest(a.Literal, []string{"@inline", "@noinline"}); len(names) > 0 {
+				err = err.WithFix(fmt.Sprintf("replace with '%s'", names[0]), errors.Replace(a.Loc, names[0]))
+			}
+			errors.ThrowError(err)
+		}
+	}
+	if node.HasAnnotation("inline") && node.HasAnnotation("noinline") {
+		errors.ThrowAtNode(node, errors.ConflictingAnnotations, "function cannot be annotated with both '@inline' and '@noinline'")
+	}
+}
+
 func (c *Checker) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
 	c.pushState(node)
 	defer c.popState()
@@ -398,9 +674,12 @@ func (c *Checker) VisitApplication(node *ast.Application) ast.Node {
 	node.Target = node.Target.Visit(c)
 	node.Args = iter.Map(node.Args, func(a ast.Node) ast.Node { return a.Visit(c) })
 
-	fn := node.Target.GetType().Unwrap().(*types.Function)
+	fn, ok := node.Target.GetType().Unwrap().(*types.Function)
+	if !ok {
+		errors.ThrowAtNode(node.Target, errors.NotCallable, "value of type '%s' is not a function", node.Target.GetType().Unwrap().GetSignature())
+	}
 	if len(node.Args) != len(fn.Params) {
-		errors.ThrowAtNode(node, errors.TypeError, "expected %d arguments, but got %d", len(fn.Params), len(node.Args))
+		errors.ThrowAtNode(node, errors.ArgumentCountMismatch, "expected %d arguments, but got %d", len(fn.Params), len(node.Args))
 	}
 
 	for i, a := range node.Args {
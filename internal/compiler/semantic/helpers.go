@@ -3,6 +3,7 @@ package semantic
 import (
 	"github.com/renatopp/golden/internal/compiler/ast"
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/helpers/safe"
 )
 
 // Helper function to track if the checker is within a function and its scope.
@@ -18,3 +19,36 @@ func NewFunctionScope(fn *ast.FnDecl, scope *env.Scope) *FunctionScope {
 		Scope: scope,
 	}
 }
+
+// Returns the expression that gives the value of the block, which is the last
+// expression of the block. Declarations and returns do not produce values, so
+// blocks ending with them evaluate to `Void`.
+func blockValue(block *ast.Block) safe.Optional[ast.Node] {
+	if len(block.Exprs) == 0 {
+		return safe.None[ast.Node]()
+	}
+
+	last := block.Exprs[len(block.Exprs)-1]
+	switch last.(type) {
+	case *ast.VarDecl, *ast.Return:
+		return safe.None[ast.Node]()
+	}
+	return safe.Some(last)
+}
+
+// Checks if the node unconditionally leaves the current function. Nested
+// function declarations are not considered, since their returns belong to
+// themselves.
+func isDiverging(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Return:
+		return true
+	case *ast.Block:
+		for _, e := range n.Exprs {
+			if isDiverging(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -12,6 +12,7 @@ type BaseParser struct {
 	ValueSolver *PrattSolver
 	TypeSolver  *PrattSolver
 	Scanner     *Scanner[*token.Token]
+	last        *token.Token // Last token consumed
 }
 
 func NewBaseParser(tokens []*token.Token) *BaseParser {
@@ -23,14 +24,35 @@ func NewBaseParser(tokens []*token.Token) *BaseParser {
 	return p
 }
 
-func (p *BaseParser) Eat() *token.Token { return p.Scanner.Eat() }
+func (p *BaseParser) Eat() *token.Token {
+	tok := p.Scanner.Eat()
+	if !tok.Is(token.TEof) {
+		p.last = tok
+	}
+	return tok
+}
 
-func (p *BaseParser) EatN(n int) []*token.Token { return p.Scanner.EatN(n) }
+func (p *BaseParser) EatN(n int) []*token.Token {
+	res := make([]*token.Token, n)
+	for i := range res {
+		res[i] = p.Eat()
+	}
+	return res
+}
 
 func (p *BaseParser) Peek() *token.Token { return p.Scanner.Peek() }
 
 func (p *BaseParser) PeekN(n int) *token.Token { return p.Scanner.PeekAt(n) }
 
+// Returns the span from the given location to the end of the last token
+// consumed.
+func (p *BaseParser) SpanFrom(from *token.Span) *token.Span {
+	if p.last == nil {
+		return from
+	}
+	return from.Through(p.last.Loc)
+}
+
 func (p *BaseParser) Skip(kinds ...token.TokenKind) []*token.Token {
 	res := []*token.Token{}
 	for p.IsNext(kinds...) {
@@ -85,7 +107,7 @@ func (p *BaseParser) Expect(kinds ...token.TokenKind) {
 		}
 		list := strings.Join(names, ", ")
 		tok := p.Peek()
-		errors.ThrowAtToken(tok, errors.ParserError, "expected token '%s', got '%s'", list, tok.Display())
+		errors.ThrowAtToken(tok, errors.UnexpectedToken, "expected token '%s', got '%s'", list, tok.Display())
 	}
 }
 
@@ -96,7 +118,7 @@ func (p *BaseParser) ExpectAndEat(kinds ...token.TokenKind) *token.Token {
 
 func (p *BaseParser) ThrowExpectedValueExpression(msg string, args ...any) {
 	next := p.Peek()
-	errors.ThrowAtToken(next, errors.ParserError, "expected value expression %s, got '%s' instead", fmt.Sprintf(msg, args...), next.Display())
+	errors.ThrowAtToken(next, errors.ExpectedExpression, "expected value expression %s, got '%s' instead", fmt.Sprintf(msg, args...), next.Display())
 }
 
 func (p *BaseParser) ValuePrecedence(t *token.Token) int {
@@ -125,8 +147,8 @@ func (p *BaseParser) ValuePrecedence(t *token.Token) int {
 		return 120
 	case t.Is(token.TLeftParen):
 		return 130
-		// case t.Is(token.TDot):
-		// return 140
+	case t.Is(token.TDot):
+		return 140
 	}
 	return 0
 }
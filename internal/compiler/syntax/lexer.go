@@ -3,6 +3,7 @@ package syntax
 import (
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/helpers/errors"
@@ -14,8 +15,10 @@ type Lexer struct {
 	filename   string
 	line       int
 	column     int
+	offset     int
 	fromLine   int
 	fromColumn int
+	fromOffset int
 	scanner    *Scanner[rune]
 }
 
@@ -51,6 +54,7 @@ func (l *Lexer) next() (*token.Token, bool) {
 	for !l.scanner.IsFinished() {
 		l.fromLine = l.line
 		l.fromColumn = l.column
+		l.fromOffset = l.offset
 
 		c0 := l.scanner.PeekAt(0)
 		c1 := l.scanner.PeekAt(1)
@@ -160,6 +164,19 @@ func (l *Lexer) next() (*token.Token, bool) {
 				}, true
 			}
 
+		// Annotations
+		case c0 == '@':
+			l.eat()
+			name := l.eatIdentifier()
+			if name == "" {
+				errors.ThrowAtLocation(l.span(), errors.MissingAnnotationName, "expected annotation name after '@'")
+			}
+			return &token.Token{
+				Kind:    token.TAnnotation,
+				Literal: "@" + name,
+				Loc:     l.span(),
+			}, true
+
 		// Strings
 		case runes.IsOneOf(c0, '"'):
 			return &token.Token{
@@ -205,7 +222,7 @@ func (l *Lexer) next() (*token.Token, bool) {
 
 			// Unknown
 			l.eat()
-			errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected character: %s", s1)
+			errors.ThrowAtLocation(l.span(), errors.UnexpectedCharacter, "unexpected character: %s", s1)
 		}
 	}
 	return &token.Token{}, false
@@ -216,13 +233,18 @@ func (l *Lexer) span() *token.Span {
 		Filename:   l.filename,
 		FromLine:   l.fromLine,
 		FromColumn: l.fromColumn,
+		FromOffset: l.fromOffset,
 		ToLine:     l.line,
 		ToColumn:   l.column,
+		ToOffset:   l.offset,
 	}
 }
 
 func (l *Lexer) eat() rune {
 	c := l.scanner.Eat()
+	if c != 0 {
+		l.offset += utf8.RuneLen(c)
+	}
 	if c == '\n' {
 		l.line++
 		l.column = 1
@@ -314,7 +336,7 @@ func (l *Lexer) eatNumber() string {
 
 		case c == '.':
 			if dot || exp {
-				errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected dot")
+				errors.ThrowAtLocation(l.span(), errors.UnexpectedCharacter, "unexpected dot")
 				l.eat()
 				continue
 			}
@@ -323,7 +345,7 @@ func (l *Lexer) eatNumber() string {
 
 		case runes.IsOneOf(c, 'e', 'E'):
 			if exp {
-				errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected e")
+				errors.ThrowAtLocation(l.span(), errors.UnexpectedCharacter, "unexpected e")
 				l.eat()
 				continue
 			}
@@ -432,10 +454,10 @@ func (l *Lexer) eatString() string {
 		}
 
 		if runes.IsEof(c) {
-			errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected end of file")
+			errors.ThrowAtLocation(l.span(), errors.UnterminatedString, "unexpected end of file")
 			break
 		} else if runes.IsOneOf(c, '\n') {
-			errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected new line")
+			errors.ThrowAtLocation(l.span(), errors.UnterminatedString, "unexpected new line")
 			break
 		}
 
@@ -449,11 +471,13 @@ func (l *Lexer) eatString() string {
 			continue
 		}
 
-		if escaping && c != first {
+		if escaping && c == first {
+			escaping = false
+		} else if escaping {
 			escaping = false
 			r, err := strconv.Unquote(`"\` + string(c) + `"`)
 			if err != nil {
-				errors.ThrowAtLocation(l.span(), errors.ParserError, "%v", err.Error())
+				errors.ThrowAtLocation(l.span(), errors.InvalidEscapeSequence, "%v", err.Error())
 			}
 			c = []rune(r)[0]
 		}
@@ -486,7 +510,7 @@ func (l *Lexer) eatRawString() string {
 		}
 
 		if runes.IsEof(c) {
-			errors.ThrowAtLocation(l.span(), errors.ParserError, "unexpected end of file")
+			errors.ThrowAtLocation(l.span(), errors.UnterminatedString, "unexpected end of file")
 			break
 		}
 
@@ -500,11 +524,13 @@ func (l *Lexer) eatRawString() string {
 			continue
 		}
 
-		if escaping && c != first {
+		if escaping && c == first {
+			escaping = false
+		} else if escaping {
 			escaping = false
 			r, err := strconv.Unquote(`"\` + string(c) + `"`)
 			if err != nil {
-				errors.ThrowAtLocation(l.span(), errors.ParserError, "%v", err.Error())
+				errors.ThrowAtLocation(l.span(), errors.InvalidEscapeSequence, "%v", err.Error())
 			}
 			c = []rune(r)[0]
 		}
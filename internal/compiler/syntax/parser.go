@@ -1,6 +1,7 @@
 package syntax
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
@@ -49,6 +50,7 @@ func NewParser(tokens []*token.Token) *Parser {
 	p.ValueSolver.RegisterInfixFn(token.TOr, p.parseBinOp)
 	p.ValueSolver.RegisterInfixFn(token.TXor, p.parseBinOp)
 	p.ValueSolver.RegisterInfixFn(token.TLeftParen, p.parseApplication)
+	p.ValueSolver.RegisterInfixFn(token.TDot, p.parseAccess)
 
 	p.TypeSolver.RegisterPrefixFn(token.TTypeIdent, p.parseTypeIdentType)
 	p.TypeSolver.RegisterPrefixFn(token.TFN, p.parseFnType)
@@ -73,6 +75,7 @@ func (p *Parser) parseTypeExpression(prec int) safe.Optional[ast.Node] {
 
 // file
 func (p *Parser) parseModule() *ast.Module {
+	imports := []*ast.Import{}
 	exprs := []ast.Node{}
 	first := p.Peek()
 	for {
@@ -82,17 +85,40 @@ func (p *Parser) parseModule() *ast.Module {
 		}
 
 		switch p.Peek().Kind {
+		case token.TImport:
+			if len(exprs) > 0 {
+				errors.ThrowAtToken(p.Peek(), errors.UnexpectedToken, "imports must come before any declaration of the module")
+			}
+			imports = append(imports, p.parseImport())
 		case token.TLet:
 			exprs = append(exprs, p.parseLet())
 		case token.TFn:
 			exprs = append(exprs, p.parseFn())
+		case token.TAnnotation:
+			exprs = append(exprs, p.parseAnnotatedFn())
+		case token.TExtern:
+			exprs = append(exprs, p.parseExtern())
 		default:
-			errors.ThrowAtToken(p.Peek(), errors.ParserError, "unexpected token '%s'", p.Peek().Literal)
+			errors.ThrowAtToken(p.Peek(), errors.UnexpectedToken, "unexpected token '%s'", p.Peek().Literal)
 		}
 
 		p.SkipSeparator(token.TSemicolon)
 	}
-	return ast.NewModule(first, exprs)
+	module := ast.NewModule(first, exprs)
+	module.Imports = imports
+	return spanned(p, first.Loc, module)
+}
+
+// import "<path>" (as <var-ident>)?
+func (p *Parser) parseImport() *ast.Import {
+	tok := p.ExpectAndEat(token.TImport)  // import
+	path := p.ExpectAndEat(token.TString) // "<path>"
+	alias := safe.None[*token.Token]()
+	if p.IsNext(token.TAs) {
+		p.Eat()                                            // as
+		alias = safe.Some(p.ExpectAndEat(token.TVarIdent)) // var-ident
+	}
+	return ast.NewImport(tok, path, alias)
 }
 
 // let <var-ident> <type-expr>? = <value-expr>
@@ -105,7 +131,7 @@ func (p *Parser) parseLet() *ast.VarDecl {
 	if !val.Has() {
 		p.ThrowExpectedValueExpression("after assignment")
 	}
-	return ast.NewVarDecl(tok, name, safe.None[ast.Node](), val.Unwrap())
+	return spanned(p, tok.Loc, ast.NewVarDecl(tok, name, safe.None[ast.Node](), val.Unwrap()))
 }
 
 // foo, bar, _bar, _1, a_1, ...
@@ -119,7 +145,7 @@ func (p *Parser) parseInt() ast.Node {
 	tok := p.ExpectAndEat(token.TInt)
 	val, err := strconv.ParseInt(tok.Literal, 10, 64)
 	if err != nil {
-		errors.ThrowAtToken(tok, errors.ParserError, "invalid integer literal '%s'", tok.Literal)
+		errors.ThrowAtToken(tok, errors.InvalidNumberLiteral, "invalid integer literal '%s'", tok.Literal)
 	}
 	return ast.NewInt(tok, val)
 }
@@ -129,7 +155,7 @@ func (p *Parser) parseHex() ast.Node {
 	tok := p.ExpectAndEat(token.THex)
 	val, err := strconv.ParseInt(tok.Literal, 16, 64)
 	if err != nil {
-		errors.ThrowAtToken(tok, errors.ParserError, "invalid hexadecimal literal '%s'", tok.Literal)
+		errors.ThrowAtToken(tok, errors.InvalidNumberLiteral, "invalid hexadecimal literal '%s'", tok.Literal)
 	}
 	return ast.NewInt(tok, val)
 }
@@ -139,7 +165,7 @@ func (p *Parser) parseOctal() ast.Node {
 	tok := p.ExpectAndEat(token.TOctal)
 	val, err := strconv.ParseInt(tok.Literal, 8, 64)
 	if err != nil {
-		errors.ThrowAtToken(tok, errors.ParserError, "invalid octal literal '%s'", tok.Literal)
+		errors.ThrowAtToken(tok, errors.InvalidNumberLiteral, "invalid octal literal '%s'", tok.Literal)
 	}
 	return ast.NewInt(tok, val)
 }
@@ -149,7 +175,7 @@ func (p *Parser) parseBinary() ast.Node {
 	tok := p.ExpectAndEat(token.TBinary)
 	val, err := strconv.ParseInt(tok.Literal, 2, 64)
 	if err != nil {
-		errors.ThrowAtToken(tok, errors.ParserError, "invalid binary literal '%s'", tok.Literal)
+		errors.ThrowAtToken(tok, errors.InvalidNumberLiteral, "invalid binary literal '%s'", tok.Literal)
 	}
 	return ast.NewInt(tok, val)
 }
@@ -159,7 +185,7 @@ func (p *Parser) parseFloat() ast.Node {
 	tok := p.ExpectAndEat(token.TFloat)
 	val, err := strconv.ParseFloat(tok.Literal, 64)
 	if err != nil {
-		errors.ThrowAtToken(tok, errors.ParserError, "invalid float '%s'", tok.Literal)
+		errors.ThrowAtToken(tok, errors.InvalidNumberLiteral, "invalid float '%s'", tok.Literal)
 	}
 	return ast.NewFloat(tok, val)
 }
@@ -177,18 +203,24 @@ func (p *Parser) parseBool() ast.Node {
 }
 
 // <op><value-expr>
+//
+// Unary operators bind tighter than the binary operators, but looser than
+// calls and accesses, ex: `-a + b` is `(-a) + b` and `-f(x)` is `-(f(x))`.
 func (p *Parser) parseUnaryOp() ast.Node {
 	tok := p.Eat()
-	right := p.parseValueExpression(0)
+	right := p.parseValueExpression(125)
 	if !right.Has() {
 		p.ThrowExpectedValueExpression("after unary operator '%s'", tok.Literal)
 	}
-	return ast.NewUnaryOp(tok, tok.Literal, right.Unwrap())
+	return spanned(p, tok.Loc, ast.NewUnaryOp(tok, tok.Literal, right.Unwrap()))
 }
 
 // (<value-expr>)
+//
+// The span of the grouped expression is widened to include the parentheses,
+// so the expressions using it start at the opening one.
 func (p *Parser) parseParen() ast.Node {
-	p.ExpectAndEat(token.TLeftParen)
+	tok := p.ExpectAndEat(token.TLeftParen)
 	p.SkipNewlines()
 	node := p.parseValueExpression(0)
 	if !node.Has() {
@@ -196,7 +228,7 @@ func (p *Parser) parseParen() ast.Node {
 	}
 	p.SkipNewlines()
 	p.ExpectAndEat(token.TRightParen)
-	return node.Unwrap()
+	return spanned(p, tok.Loc, node.Unwrap())
 }
 
 // <value-expr><op><value-expr>
@@ -206,7 +238,7 @@ func (p *Parser) parseBinOp(left ast.Node) ast.Node {
 	if !right.Has() {
 		p.ThrowExpectedValueExpression("after binary operator '%s'", tok.Literal)
 	}
-	return ast.NewBinOp(tok, tok.Literal, left, right.Unwrap())
+	return spanned(p, left.GetSpan(), ast.NewBinOp(tok, tok.Literal, left, right.Unwrap()))
 }
 
 // { ... }
@@ -230,7 +262,7 @@ func (p *Parser) parseBlock() ast.Node {
 		p.SkipSeparator(token.TSemicolon)
 	}
 	p.ExpectAndEat(token.TRightBrace)
-	return ast.NewBlock(tok, exprs)
+	return spanned(p, tok.Loc, ast.NewBlock(tok, exprs))
 }
 
 // fn <var-ident>?(<var-ident> <type-expr>, ...):<type-expr> = ...
@@ -255,7 +287,48 @@ func (p *Parser) parseFn() ast.Node {
 	p.SkipNewlines()
 	p.Expect(token.TLeftBrace)
 	val := p.parseBlock().(*ast.Block)
-	return ast.NewFnDecl(tok, name, params, returnExpr, val)
+	return spanned(p, tok.Loc, ast.NewFnDecl(tok, name, params, returnExpr, val))
+}
+
+// @<name> ... fn ...
+func (p *Parser) parseAnnotatedFn() ast.Node {
+	annotations := []*token.Token{}
+	for p.IsNext(token.TAnnotation) {
+		annotations = append(annotations, p.Eat())
+		p.SkipNewlines()
+	}
+	p.Expect(token.TFn)
+	fn := p.parseFn().(*ast.FnDecl)
+	fn.Annotations = annotations
+	fn.SetSpan(p.SpanFrom(annotations[0].Loc))
+	return fn
+}
+
+// extern fn <var-ident> (<params>)? <type-expr>? = <string>, ...
+func (p *Parser) parseExtern() ast.Node {
+	tok := p.ExpectAndEat(token.TExtern)
+	p.ExpectAndEat(token.TFn)
+	p.Expect(token.TVarIdent)
+	name := p.parseVarIdent().(*ast.VarIdent)
+
+	params := []*ast.FnDeclParam{}
+	if p.IsNext(token.TLeftParen) {
+		params = p.parseFnParams()
+	}
+
+	var returnExpr ast.Node = ast.NewTypeIdent(p.Peek(), "Void")
+	if expr := p.parseTypeExpression(0); expr.Has() {
+		returnExpr = expr.Unwrap()
+	}
+
+	p.ExpectAndEat(token.TAssign)
+	bindings := []*token.Token{p.ExpectAndEat(token.TString)}
+	for p.IsNext(token.TComma) {
+		p.Eat()
+		p.SkipNewlines()
+		bindings = append(bindings, p.ExpectAndEat(token.TString))
+	}
+	return spanned(p, tok.Loc, ast.NewExternFnDecl(tok, name, params, returnExpr, bindings))
 }
 
 // (<var-ident> <type-expr>, ...)
@@ -269,7 +342,7 @@ func (p *Parser) parseFnParams() []*ast.FnDeclParam {
 		p.Expect(token.TVarIdent)
 		name := p.parseVarIdent().(*ast.VarIdent)
 		typeExpr := p.parseTypeExpression(0)
-		params = append(params, ast.NewFnDeclParam(name, typeExpr.Or(nil)))
+		params = append(params, spanned(p, name.GetSpan(), ast.NewFnDeclParam(name, typeExpr.Or(nil))))
 		p.SkipSeparator(token.TComma)
 	}
 	last := p.ExpectAndEat(token.TRightParen)
@@ -279,7 +352,8 @@ func (p *Parser) parseFnParams() []*ast.FnDeclParam {
 		lastNode := params[len(params)-1]
 		lastType := lastNode.TypeExpr
 		if lastType == nil {
-			errors.ThrowAtToken(last, errors.ParserError, "expected type expression after parameter name, but none was found")
+			err := errors.NewError(errors.MissingParameterType, "expected type expression after parameter name, but none was found").WithToken(last)
+			errors.ThrowError(p.suggestParameterType(err, params))
 			return nil
 		}
 		for i := len(params) - 1; i >= 0; i-- {
@@ -295,10 +369,22 @@ func (p *Parser) parseFnParams() []*ast.FnDeclParam {
 	return params
 }
 
+// When a previous parameter has a named type, the same type is given as fix
+// to the last parameter.
+func (p *Parser) suggestParameterType(err errors.GoldenError, params []*ast.FnDeclParam) errors.GoldenError {
+	for i := len(params) - 1; i >= 0; i-- {
+		if tp, ok := params[i].TypeExpr.(*ast.TypeIdent); ok {
+			last := params[len(params)-1].Name
+			return err.WithFix(fmt.Sprintf("add the type '%s' to '%s'", tp.Value, last.Value), errors.InsertAfter(last.GetSpan(), " "+tp.Value))
+		}
+	}
+	return err
+}
+
 func (p *Parser) parseReturn() ast.Node {
 	tok := p.ExpectAndEat(token.TReturn)
 	value := p.parseValueExpression(0)
-	return ast.NewReturn(tok, value)
+	return spanned(p, tok.Loc, ast.NewReturn(tok, value))
 }
 
 //
@@ -325,7 +411,7 @@ func (p *Parser) parseFnType() ast.Node {
 		returnExpr = expr.Unwrap()
 	}
 
-	return ast.NewTypeFn(tok, params, returnExpr)
+	return spanned(p, tok.Loc, ast.NewTypeFn(tok, params, returnExpr))
 }
 
 // (<type-expr>, ...)
@@ -338,7 +424,7 @@ func (p *Parser) parseFnTypeParams() []ast.Node {
 		}
 		typeExpr := p.parseTypeExpression(0)
 		if !typeExpr.Has() {
-			errors.ThrowAtToken(p.Peek(), errors.ParserError, "expected type expression, but none was found")
+			errors.ThrowAtToken(p.Peek(), errors.ExpectedTypeExpression, "expected type expression, but none was found")
 		}
 		params = append(params, typeExpr.Unwrap())
 		p.SkipSeparator(token.TComma)
@@ -363,5 +449,19 @@ func (p *Parser) parseApplication(left ast.Node) ast.Node {
 		p.SkipSeparator(token.TComma)
 	}
 	p.ExpectAndEat(token.TRightParen)
-	return ast.NewApplication(tok, left, args)
+	return spanned(p, left.GetSpan(), ast.NewApplication(tok, left, args))
+}
+
+// <target>.<var-ident>
+func (p *Parser) parseAccess(left ast.Node) ast.Node {
+	tok := p.ExpectAndEat(token.TDot)
+	name := p.parseVarIdent().(*ast.VarIdent)
+	return spanned(p, left.GetSpan(), ast.NewAccess(tok, left, name))
+}
+
+// Sets the span of the node from the given location to the last token
+// consumed, which must be the last token of the node.
+func spanned[T ast.Node](p *Parser, from *token.Span, node T) T {
+	node.SetSpan(p.SpanFrom(from))
+	return node
 }
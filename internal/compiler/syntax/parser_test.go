@@ -0,0 +1,57 @@
+package syntax_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/syntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Parses the value of `let v = <expr>`.
+func parseValue(t *testing.T, expr string) ast.Node {
+	tokens, err := syntax.NewLexer("test.gold", []byte("let v = "+expr+"\n")).Lex()
+	require.NoError(t, err)
+	root, err := syntax.NewParser(tokens).Parse()
+	require.NoError(t, err)
+	return root.Exprs[0].(*ast.VarDecl).ValueExpr
+}
+
+// Returns the byte offsets of the node span, relative to the expression.
+func offsets(n ast.Node) [2]int {
+	span := n.GetSpan()
+	return [2]int{span.FromOffset - len("let v = "), span.ToOffset - len("let v = ")}
+}
+
+func TestParseSpans(t *testing.T) {
+	node := parseValue(t, "(a + b) * c")
+	assert.Equal(t, [2]int{0, 11}, offsets(node))
+	assert.Equal(t, [2]int{0, 7}, offsets(node.(*ast.BinOp).LeftExpr))
+	assert.Equal(t, [2]int{10, 11}, offsets(node.(*ast.BinOp).RightExpr))
+
+	node = parseValue(t, "a * (b + c)")
+	assert.Equal(t, [2]int{0, 11}, offsets(node))
+	assert.Equal(t, [2]int{4, 11}, offsets(node.(*ast.BinOp).RightExpr))
+
+	node = parseValue(t, "(f)(a, (b))")
+	assert.Equal(t, [2]int{0, 11}, offsets(node))
+	assert.Equal(t, [2]int{0, 3}, offsets(node.(*ast.Application).Target))
+	assert.Equal(t, [2]int{4, 5}, offsets(node.(*ast.Application).Args[0]))
+	assert.Equal(t, [2]int{7, 10}, offsets(node.(*ast.Application).Args[1]))
+
+	node = parseValue(t, "f(a) + g(b)")
+	assert.Equal(t, [2]int{0, 11}, offsets(node))
+	assert.Equal(t, [2]int{0, 4}, offsets(node.(*ast.BinOp).LeftExpr))
+	assert.Equal(t, [2]int{7, 11}, offsets(node.(*ast.BinOp).RightExpr))
+
+	node = parseValue(t, "-(a + b)")
+	assert.Equal(t, [2]int{0, 8}, offsets(node))
+	assert.Equal(t, [2]int{1, 8}, offsets(node.(*ast.UnaryOp).RightExpr))
+
+	node = parseValue(t, "!(a)")
+	assert.Equal(t, [2]int{0, 4}, offsets(node))
+
+	node = parseValue(t, "((a))")
+	assert.Equal(t, [2]int{0, 5}, offsets(node))
+}
@@ -2,12 +2,66 @@ package token
 
 import "fmt"
 
+// Span is a range of the source. Lines and columns start at 1, offsets are in
+// bytes and start at 0. The end of the span is exclusive.
 type Span struct {
 	Filename   string
 	FromLine   int
 	FromColumn int
+	FromOffset int
 	ToLine     int
 	ToColumn   int
+	ToOffset   int
+}
+
+// Returns a span from the start of this span to the end of the other one.
+func (s *Span) Through(other *Span) *Span {
+	if s == nil {
+		return other
+	}
+	if other == nil {
+		return s
+	}
+	return &Span{
+		Filename:   s.Filename,
+		FromLine:   s.FromLine,
+		FromColumn: s.FromColumn,
+		FromOffset: s.FromOffset,
+		ToLine:     other.ToLine,
+		ToColumn:   other.ToColumn,
+		ToOffset:   other.ToOffset,
+	}
+}
+
+// Returns an empty span at the start of this span.
+func (s *Span) Start() *Span {
+	return &Span{
+		Filename:   s.Filename,
+		FromLine:   s.FromLine,
+		FromColumn: s.FromColumn,
+		FromOffset: s.FromOffset,
+		ToLine:     s.FromLine,
+		ToColumn:   s.FromColumn,
+		ToOffset:   s.FromOffset,
+	}
+}
+
+// Returns an empty span at the end of this span.
+func (s *Span) End() *Span {
+	return &Span{
+		Filename:   s.Filename,
+		FromLine:   s.ToLine,
+		FromColumn: s.ToColumn,
+		FromOffset: s.ToOffset,
+		ToLine:     s.ToLine,
+		ToColumn:   s.ToColumn,
+		ToOffset:   s.ToOffset,
+	}
+}
+
+// Checks if the span covers more than one line.
+func (s *Span) IsMultiline() bool {
+	return s.ToLine > s.FromLine
 }
 
 type TokenKind uint64
@@ -19,6 +73,7 @@ const (
 	TComment             // -- comment
 	TSemicolon           // ;
 	TComma               // ,
+	TDot                 // .
 
 	TVarIdent  // variable identifier
 	TTypeIdent // type identifier
@@ -26,6 +81,11 @@ const (
 	TFn        // fn
 	TFN        // Fn
 	TReturn    // return
+	TImport    // import
+	TAs        // as
+	TExtern    // extern
+
+	TAnnotation // @inline
 
 	// Groupings
 	TLeftBrace  // {
@@ -87,10 +147,14 @@ func (t *Token) Is(kind ...TokenKind) bool {
 var literal2kind = map[string]TokenKind{
 	";":      TSemicolon,
 	",":      TComma,
+	".":      TDot,
 	"let":    TLet,
 	"fn":     TFn,
 	"Fn":     TFN,
 	"return": TReturn,
+	"import": TImport,
+	"as":     TAs,
+	"extern": TExtern,
 	"true":   TTrue,
 	"false":  TFalse,
 	"{":      TLeftBrace,
@@ -123,10 +187,15 @@ var kind2literal = map[TokenKind]string{
 	TComment:      "--",
 	TSemicolon:    ";",
 	TComma:        ",",
+	TDot:          ".",
 	TLet:          "let",
 	TFn:           "fn",
 	TFN:           "Fn",
 	TReturn:       "return",
+	TImport:       "import",
+	TAs:           "as",
+	TExtern:       "extern",
+	TAnnotation:   "annotation",
 	TVarIdent:     "value identifier",
 	TTypeIdent:    "type identifier",
 	TLeftBrace:    "{",
@@ -7,7 +7,7 @@ import (
 	"github.com/renatopp/golden/internal/compiler/ast"
 )
 
-var NoopFn = NewFunction(nil, []ast.Type{}, Void)
+var NoopFn = builtin(NewFunction(nil, []ast.Type{}, Void))
 
 var _ ast.Type = &Function{}
 
@@ -32,5 +32,6 @@ func (m *Module) GetDefault() (ast.Node, error) {
 }
 
 func (m *Module) IsCompatible(other ast.Type) bool {
-	return other != nil && m.GetId() == other.GetId()
+	o, ok := other.(*Module)
+	return ok && o == m
 }
@@ -5,15 +5,9 @@ import (
 	"github.com/renatopp/golden/internal/compiler/token"
 )
 
+// The built-in types are shared by all compilation sessions, even concurrent
+// ones, so they are never changed after `init`.
 var (
-	Zero        *ast.Int
-	One         *ast.Int
-	FZero       *ast.Float
-	FOne        *ast.Float
-	False       *ast.Bool
-	True        *ast.Bool
-	EmptyString *ast.String
-
 	Int    *Primitive
 	Float  *Primitive
 	Bool   *Primitive
@@ -21,18 +15,14 @@ var (
 )
 
 func init() {
-	Zero = ast.NewInt(&token.Token{}, 0)
-	One = ast.NewInt(&token.Token{}, 1)
-	FZero = ast.NewFloat(&token.Token{}, 0)
-	FOne = ast.NewFloat(&token.Token{}, 1)
-	False = ast.NewBool(&token.Token{}, false)
-	True = ast.NewBool(&token.Token{}, true)
-	EmptyString = ast.NewString(&token.Token{}, "")
-
-	Int = NewPrimitive("Int", func() (ast.Node, error) { return Zero, nil })
-	Float = NewPrimitive("Float", func() (ast.Node, error) { return FZero, nil })
-	Bool = NewPrimitive("Bool", func() (ast.Node, error) { return False, nil })
-	String = NewPrimitive("String", func() (ast.Node, error) { return EmptyString, nil })
+	// default values are new nodes, since nodes are changed by the compilation
+	Int = NewPrimitive("Int", func() (ast.Node, error) { return ast.NewInt(&token.Token{}, 0), nil })
+	Float = NewPrimitive("Float", func() (ast.Node, error) { return ast.NewFloat(&token.Token{}, 0), nil })
+	Bool = NewPrimitive("Bool", func() (ast.Node, error) { return ast.NewBool(&token.Token{}, false), nil })
+	String = NewPrimitive("String", func() (ast.Node, error) { return ast.NewString(&token.Token{}, ""), nil })
+	for _, tp := range []*Primitive{Int, Float, Bool, String} {
+		tp.assignId(builtinIds)
+	}
 }
 
 //
@@ -58,5 +48,6 @@ func NewPrimitive(name string, fn func() (ast.Node, error)) *Primitive {
 func (p *Primitive) GetSignature() string          { return p.Name }
 func (p *Primitive) GetDefault() (ast.Node, error) { return p.DefaultFn() }
 func (p *Primitive) IsCompatible(other ast.Type) bool {
-	return other != nil && p.GetId() == other.GetId()
+	o, ok := other.(*Primitive)
+	return ok && o == p
 }
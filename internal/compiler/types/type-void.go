@@ -6,13 +6,7 @@ import (
 	"github.com/renatopp/golden/internal/compiler/ast"
 )
 
-var (
-	Void *Unit
-)
-
-func init() {
-	Void = NewUnit()
-}
+var Void = builtin(NewUnit())
 
 var _ ast.Type = &Unit{}
 
@@ -1,21 +1,62 @@
 package types
 
-import "github.com/renatopp/golden/internal/compiler/ast"
+import (
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/ids"
+)
 
-var _typeId uint64
+// Built-in types are shared by all compilation sessions, so they are numbered
+// when created, and the sessions number their own types after them. They are
+// immutable afterwards: `AssignIds` never renumbers a type with an id.
+var builtinIds = ids.NewGenerator(0)
 
+// Ids up to this value are reserved to the built-in types.
+const ReservedIds uint64 = 100
+
+// Numbers a built-in type, used in the package variables.
+func builtin[T interface{ assignId(*ids.Generator) }](tp T) T {
+	tp.assignId(builtinIds)
+	return tp
+}
+
+// Types are created without an id, see `AssignIds`.
 type BaseType struct {
 	Id         uint64
 	Definition ast.Node
 }
 
 func NewBaseType(def ast.Node) *BaseType {
-	_typeId++
 	return &BaseType{
-		Id:         _typeId,
 		Definition: def,
 	}
 }
 
 func (t *BaseType) GetId() uint64           { return t.Id }
 func (t *BaseType) GetDefinition() ast.Node { return t.Definition }
+
+func (t *BaseType) assignId(gen *ids.Generator) {
+	if t.Id == 0 {
+		t.Id = gen.Next()
+	}
+}
+
+// AssignIds gives an id to the type and to the types it is made of, when they
+// do not have one yet.
+func AssignIds(tp ast.Type, gen *ids.Generator) {
+	switch tp := tp.(type) {
+	case *Function:
+		tp.assignId(gen)
+		for _, p := range tp.Params {
+			AssignIds(p, gen)
+		}
+		if tp.Return != nil {
+			AssignIds(tp.Return, gen)
+		}
+	case *Module:
+		tp.assignId(gen)
+	case *Primitive:
+		tp.assignId(gen)
+	case *Unit:
+		tp.assignId(gen)
+	}
+}
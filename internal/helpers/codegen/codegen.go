@@ -1,6 +1,12 @@
 package codegen
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/helpers/fs"
+)
 
 func JoinList[T any](separator string, list []T, f func(T) string) string {
 	s := ""
@@ -38,3 +44,18 @@ func (i *Identer) Indent(block string) string {
 //
 //
 //
+
+// Returns the location of the node shown by runtime errors, as
+// `path:line:column`. Paths inside the project are written as `@/path`, so the
+// generated code does not depend on where the project was built.
+func Location(projectDir string, node ast.Node) string {
+	span := node.GetSpan()
+	if span == nil {
+		return ""
+	}
+	file := span.Filename
+	if fs.IsPathInside(projectDir, file) {
+		file = "@/" + strings.TrimPrefix(fs.ToLinuxSlash(fs.GetRelativePath(projectDir, file)), "/")
+	}
+	return fmt.Sprintf("%s:%d:%d", file, span.FromLine, span.FromColumn)
+}
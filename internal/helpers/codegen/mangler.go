@@ -0,0 +1,80 @@
+package codegen
+
+import "maps"
+
+// Mangler maps Golden identifiers to identifiers of the target language. The
+// name is first converted with the case rules of the target, then escaped
+// while it is a reserved word of the target or while it is already given to
+// another Golden name. The original names are kept, so the target names can
+// be translated back in runtime errors.
+type Mangler struct {
+	reserved map[string]bool
+	convert  func(string) string
+	escape   func(string) string
+	names    map[string]string // Target names, by Golden name
+	raw      map[string]string // Target names of the unconverted names, by Golden name
+	origins  map[string]string // Golden names, by target name
+}
+
+func NewMangler(reserved []string, convert, escape func(string) string) *Mangler {
+	m := &Mangler{
+		reserved: map[string]bool{},
+		convert:  convert,
+		escape:   escape,
+		names:    map[string]string{},
+		raw:      map[string]string{},
+		origins:  map[string]string{},
+	}
+	for _, r := range reserved {
+		m.reserved[r] = true
+	}
+	return m
+}
+
+// Returns the target name of the Golden name, always the same for the same
+// name.
+func (m *Mangler) Mangle(name string) string {
+	if target, ok := m.names[name]; ok {
+		return target
+	}
+	target := m.free(name, m.convert(name))
+	m.names[name] = target
+	return target
+}
+
+// Like Mangle, but without converting the case of the name. It is used for
+// names that follow other rules in the target, such as the aliases of the
+// imported modules.
+func (m *Mangler) MangleRaw(name string) string {
+	if target, ok := m.raw[name]; ok {
+		return target
+	}
+	target := m.free(name, name)
+	m.raw[name] = target
+	return target
+}
+
+// Returns the Golden name of the target name, if it was mangled.
+func (m *Mangler) Original(target string) (string, bool) {
+	name, ok := m.origins[target]
+	return name, ok
+}
+
+// Returns the Golden names, by their target names.
+func (m *Mangler) Origins() map[string]string {
+	return maps.Clone(m.origins)
+}
+
+// Escapes the candidate until it is neither reserved nor used by another
+// name. The wildcard `_` is never escaped, it discards the value in every
+// target.
+func (m *Mangler) free(name, candidate string) string {
+	if candidate == "_" {
+		return candidate
+	}
+	for m.reserved[candidate] || m.origins[candidate] != "" && m.origins[candidate] != name {
+		candidate = m.escape(candidate)
+	}
+	m.origins[candidate] = name
+	return candidate
+}
@@ -0,0 +1,45 @@
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/helpers/codegen"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMangler() *codegen.Mangler {
+	upper := func(s string) string { return strings.ToUpper(s[:1]) + s[1:] }
+	return codegen.NewMangler([]string{"Type", "len"}, upper, func(s string) string { return s + "_" })
+}
+
+func TestMangle(t *testing.T) {
+	m := newMangler()
+	assert.Equal(t, "Foo", m.Mangle("foo"))
+	assert.Equal(t, "Foo", m.Mangle("foo"))
+	assert.Equal(t, "Type_", m.Mangle("type"))
+	assert.Equal(t, "_", m.Mangle("_"))
+
+	name, ok := m.Original("Type_")
+	assert.True(t, ok)
+	assert.Equal(t, "type", name)
+	_, ok = m.Original("Type")
+	assert.False(t, ok)
+}
+
+func TestMangleCollisions(t *testing.T) {
+	m := newMangler()
+	assert.Equal(t, "Type_", m.Mangle("type"))
+	assert.Equal(t, "Type__", m.Mangle("type_"))
+
+	// unconverted names share the target names
+	assert.Equal(t, "len_", m.MangleRaw("len"))
+	assert.Equal(t, "Foo_", m.MangleRaw("Foo_"))
+	assert.Equal(t, "Foo", m.MangleRaw("Foo"))
+	assert.Equal(t, "Foo_", m.MangleRaw("Foo_"))
+	assert.Equal(t, "Foo__", m.Mangle("foo_"))
+
+	assert.Equal(t, map[string]string{
+		"Type_": "type", "Type__": "type_", "len_": "len", "Foo_": "Foo_", "Foo": "Foo", "Foo__": "foo_",
+	}, m.Origins())
+}
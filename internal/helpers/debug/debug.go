@@ -7,6 +7,7 @@ import (
 	"github.com/renatopp/golden/internal/builder"
 	"github.com/renatopp/golden/internal/compiler/ast"
 	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
 	"github.com/renatopp/golden/internal/compiler/token"
 )
 
@@ -28,6 +29,11 @@ func PrettyPrintAst(file *builder.File, root *ast.Module) {
 	println()
 }
 
+func PrettyPrintIR(file *builder.File, mod *ir.Module) {
+	fmt.Printf("IR for module %s:\n", file.Path)
+	fmt.Println(ir.Dump(mod))
+}
+
 func PrettyPrintScope(scope *env.Scope) {
 	if scope == nil {
 		return
@@ -0,0 +1,37 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format of the dumps of the compiler stages.
+type Format string
+
+const (
+	FormatText  Format = "text"  // Human readable
+	FormatJson  Format = "json"  // JSON document per module
+	FormatSexpr Format = "sexpr" // S-expressions
+	FormatDot   Format = "dot"   // Graphviz DOT graphs
+)
+
+var Formats = []Format{FormatText, FormatJson, FormatSexpr, FormatDot}
+
+func ParseFormat(s string) (Format, bool) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+func unsupported(what string, format Format) error {
+	return fmt.Errorf("%s cannot be printed as %s", what, format)
+}
+
+// Quotes the string as a DOT or S-expression string.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
@@ -2,43 +2,153 @@ package debug
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
+	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/helpers/iter"
 	"github.com/renatopp/golden/internal/helpers/str"
 )
 
 var _ ast.Visitor = &AstPrinter{}
 
+// AstPrinter writes the tree of nodes as indented text, S-expressions or as
+// the nodes and edges of a DOT graph.
 type AstPrinter struct {
-	depth int
+	out    io.Writer
+	format Format
+	depth  int
+	ids    []int // DOT ids of the open nodes, by depth
+	count  int
 }
 
 func NewAstPrinter() *AstPrinter {
+	return NewAstPrinterTo(os.Stdout, FormatText)
+}
+
+func NewAstPrinterTo(out io.Writer, format Format) *AstPrinter {
 	return &AstPrinter{
-		depth: 0,
+		out:    out,
+		format: format,
+		depth:  0,
+	}
+}
+
+// Prints the whole tree in the given format. JSON is not supported, it is
+// produced by the `astjson` package instead.
+func PrintAst(out io.Writer, name string, root ast.Node, format Format) error {
+	switch format {
+	case FormatText, FormatSexpr:
+		root.Visit(NewAstPrinterTo(out, format))
+		fmt.Fprintln(out)
+	case FormatDot:
+		fmt.Fprintf(out, "digraph %s {\n", quote(name))
+		fmt.Fprintln(out, "  node [shape=box, fontname=monospace];")
+		root.Visit(NewAstPrinterTo(out, format))
+		fmt.Fprintln(out, "}")
+	default:
+		return unsupported("AST", format)
 	}
+	return nil
 }
 
-func (p *AstPrinter) inc()           { p.depth++ }
-func (p *AstPrinter) dec()           { p.depth-- }
+func (p *AstPrinter) inc() { p.depth++ }
+func (p *AstPrinter) dec() {
+	if p.format == FormatSexpr {
+		fmt.Fprint(p.out, ")")
+	}
+	p.ids = p.ids[:min(len(p.ids), p.depth-1)]
+	p.depth--
+}
 func (p *AstPrinter) indent() string { return strings.Repeat("  ", p.depth-1) }
-func (p *AstPrinter) print(n ast.Node, s string, args ...any) {
-	first := fmt.Sprintf(p.indent()+s, args...)
-	fmt.Print(first)
-	n.GetType().IfElse(func(tp ast.Type) {
-		second := fmt.Sprintf(" → %s", tp.GetSignature())
-		println(str.Repeat(" ", 50-len(first)), second)
-	}, func() {
-		println()
-	})
+
+// Prints the node, its value and attributes, such as annotations, followed by
+// its type.
+func (p *AstPrinter) print(n ast.Node, kind, value string, attrs ...string) {
+	sig := ""
+	n.GetType().If(func(tp ast.Type) { sig = tp.GetSignature() })
+
+	switch p.format {
+	case FormatSexpr:
+		if p.depth > 1 {
+			fmt.Fprint(p.out, "\n")
+		}
+		fmt.Fprintf(p.out, "%s(%s", p.indent(), kind)
+		if value != "" {
+			fmt.Fprintf(p.out, " %s", value)
+		}
+		for _, a := range attrs {
+			fmt.Fprintf(p.out, " %s", a)
+		}
+		if sig != "" {
+			fmt.Fprintf(p.out, " :type %s", quote(sig))
+		}
+
+	case FormatDot:
+		id := p.count
+		p.count++
+		label := kind
+		if value != "" {
+			label += ": " + value
+		}
+		for _, a := range attrs {
+			label += " " + a
+		}
+		if sig != "" {
+			label += "\n" + sig
+		}
+		fmt.Fprintf(p.out, "  n%d [label=%s];\n", id, quote(label))
+		if len(p.ids) > 0 {
+			fmt.Fprintf(p.out, "  n%d -> n%d;\n", p.ids[len(p.ids)-1], id)
+		}
+		p.ids = append(p.ids, id)
+
+	default:
+		first := p.indent() + "[" + kind
+		if value != "" {
+			first += ":" + value
+		}
+		first += "]"
+		for _, a := range attrs {
+			first += " " + a
+		}
+		fmt.Fprint(p.out, first)
+		if sig != "" {
+			fmt.Fprint(p.out, str.Repeat(" ", 50-len(first)), "  → ", sig)
+		}
+		fmt.Fprintln(p.out)
+	}
+}
+
+// Quotes the string values, which are escaped in the text format.
+func (p *AstPrinter) quote(s string) string {
+	if p.format == FormatText {
+		return "'" + Escape(s) + "'"
+	}
+	return quote(s)
 }
 
 func (p *AstPrinter) VisitModule(node *ast.Module) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[module]")
+	imports := []string{}
+	for _, imp := range node.Imports {
+		alias := ""
+		imp.Alias.If(func(t *token.Token) { alias = t.Literal })
+		switch {
+		case p.format == FormatSexpr && alias != "":
+			imports = append(imports, fmt.Sprintf("(import %s :as %s)", p.quote(imp.Path.Literal), alias))
+		case p.format == FormatSexpr:
+			imports = append(imports, fmt.Sprintf("(import %s)", p.quote(imp.Path.Literal)))
+		case alias != "":
+			imports = append(imports, fmt.Sprintf("import %s as %s", p.quote(imp.Path.Literal), alias))
+		default:
+			imports = append(imports, "import "+p.quote(imp.Path.Literal))
+		}
+	}
+	p.print(node, "module", "", imports...)
 	iter.Each(node.Exprs, func(e ast.Node) { e.Visit(p) })
 	return node
 }
@@ -46,7 +156,7 @@ func (p *AstPrinter) VisitModule(node *ast.Module) ast.Node {
 func (p *AstPrinter) VisitVarDecl(node *ast.VarDecl) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[let]")
+	p.print(node, "let", "")
 	node.Name.Visit(p)
 	node.TypeExpr.If(func(n ast.Node) { n.Visit(p) })
 	node.ValueExpr.Visit(p)
@@ -56,49 +166,49 @@ func (p *AstPrinter) VisitVarDecl(node *ast.VarDecl) ast.Node {
 func (p *AstPrinter) VisitInt(node *ast.Int) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[int:%d]", node.Value)
+	p.print(node, "int", fmt.Sprintf("%d", node.Value))
 	return node
 }
 
 func (p *AstPrinter) VisitFloat(node *ast.Float) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[float:%f]", node.Value)
+	p.print(node, "float", fmt.Sprintf("%f", node.Value))
 	return node
 }
 
 func (p *AstPrinter) VisitString(node *ast.String) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[string:'%s']", Escape(node.Value))
+	p.print(node, "string", p.quote(node.Value))
 	return node
 }
 
 func (p *AstPrinter) VisitBool(node *ast.Bool) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[bool:%t]", node.Value)
+	p.print(node, "bool", fmt.Sprintf("%t", node.Value))
 	return node
 }
 
 func (p *AstPrinter) VisitVarIdent(node *ast.VarIdent) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[var-ident:%s]", node.Value)
+	p.print(node, "var-ident", node.Value)
 	return node
 }
 
 func (p *AstPrinter) VisitTypeIdent(node *ast.TypeIdent) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[type-ident:%s]", node.Value)
+	p.print(node, "type-ident", node.Value)
 	return node
 }
 
 func (p *AstPrinter) VisitBinOp(node *ast.BinOp) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[bin-op:%s]", node.Op)
+	p.print(node, "bin-op", node.Op)
 	node.LeftExpr.Visit(p)
 	node.RightExpr.Visit(p)
 	return node
@@ -107,7 +217,7 @@ func (p *AstPrinter) VisitBinOp(node *ast.BinOp) ast.Node {
 func (p *AstPrinter) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[unary-op:%s]", node.Op)
+	p.print(node, "unary-op", node.Op)
 	node.RightExpr.Visit(p)
 	return node
 }
@@ -115,7 +225,7 @@ func (p *AstPrinter) VisitUnaryOp(node *ast.UnaryOp) ast.Node {
 func (p *AstPrinter) VisitBlock(node *ast.Block) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[block]")
+	p.print(node, "block", "")
 	iter.Each(node.Exprs, func(e ast.Node) { e.Visit(p) })
 	return node
 }
@@ -123,7 +233,14 @@ func (p *AstPrinter) VisitBlock(node *ast.Block) ast.Node {
 func (p *AstPrinter) VisitFnDecl(node *ast.FnDecl) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[fn-decl]")
+	attrs := []string{}
+	for _, a := range node.Annotations {
+		attrs = append(attrs, a.Literal)
+	}
+	if node.Recursive {
+		attrs = append(attrs, ":recursive")
+	}
+	p.print(node, "fn-decl", "", attrs...)
 	node.Name.If(func(n *ast.VarIdent) { n.Visit(p) })
 	iter.Each(node.Params, func(n *ast.FnDeclParam) { n.Visit(p) })
 	node.TypeExpr.Visit(p)
@@ -131,10 +248,24 @@ func (p *AstPrinter) VisitFnDecl(node *ast.FnDecl) ast.Node {
 	return node
 }
 
+func (p *AstPrinter) VisitExternFnDecl(node *ast.ExternFnDecl) ast.Node {
+	p.inc()
+	defer p.dec()
+	attrs := []string{}
+	for _, b := range node.Bindings {
+		attrs = append(attrs, b.Literal)
+	}
+	p.print(node, "extern-fn-decl", "", attrs...)
+	node.Name.Visit(p)
+	iter.Each(node.Params, func(n *ast.FnDeclParam) { n.Visit(p) })
+	node.TypeExpr.Visit(p)
+	return node
+}
+
 func (p *AstPrinter) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[fn-decl-param]")
+	p.print(node, "fn-decl-param", "")
 	node.Name.Visit(p)
 	node.TypeExpr.Visit(p)
 	return node
@@ -143,16 +274,25 @@ func (p *AstPrinter) VisitFnDeclParam(node *ast.FnDeclParam) ast.Node {
 func (p *AstPrinter) VisitTypeFn(node *ast.TypeFn) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[type-fn]")
+	p.print(node, "type-fn", "")
 	iter.Each(node.Parameters, func(n ast.Node) { n.Visit(p) })
 	node.ReturnExpr.Visit(p)
 	return node
 }
 
+func (p *AstPrinter) VisitAccess(node *ast.Access) ast.Node {
+	p.inc()
+	defer p.dec()
+	p.print(node, "access", "")
+	node.Target.Visit(p)
+	node.Name.Visit(p)
+	return node
+}
+
 func (p *AstPrinter) VisitApplication(node *ast.Application) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[application]")
+	p.print(node, "application", "")
 	node.Target.Visit(p)
 	iter.Each(node.Args, func(n ast.Node) { n.Visit(p) })
 	return node
@@ -161,7 +301,7 @@ func (p *AstPrinter) VisitApplication(node *ast.Application) ast.Node {
 func (p *AstPrinter) VisitReturn(node *ast.Return) ast.Node {
 	p.inc()
 	defer p.dec()
-	p.print(node, "[return]")
+	p.print(node, "return", "")
 	node.ValueExpr.If(func(n ast.Node) { n.Visit(p) })
 	return node
 }
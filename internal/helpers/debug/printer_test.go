@@ -0,0 +1,34 @@
+package debug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/syntax"
+	"github.com/renatopp/golden/internal/helpers/debug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintAst(t *testing.T) {
+	tokens, err := syntax.NewLexer("test.gold", []byte("let a = 1 + -2\n")).Lex()
+	require.NoError(t, err)
+	root, err := syntax.NewParser(tokens).Parse()
+	require.NoError(t, err)
+
+	print := func(format debug.Format) string {
+		sb := &strings.Builder{}
+		require.NoError(t, debug.PrintAst(sb, "test.gold", root, format))
+		return sb.String()
+	}
+
+	assert.Equal(t, "[module]\n  [let]\n    [var-ident:a]\n    [bin-op:+]\n      [int:1]\n      [unary-op:-]\n        [int:2]\n\n", print(debug.FormatText))
+	assert.Equal(t, "(module\n  (let\n    (var-ident a)\n    (bin-op +\n      (int 1)\n      (unary-op -\n        (int 2)))))\n", print(debug.FormatSexpr))
+
+	dot := print(debug.FormatDot)
+	assert.True(t, strings.HasPrefix(dot, "digraph \"test.gold\" {\n"))
+	assert.Contains(t, dot, "  n4 [label=\"int: 1\"];\n  n3 -> n4;\n")
+	assert.Contains(t, dot, "  n5 [label=\"unary-op: -\"];\n  n3 -> n5;\n  n6 [label=\"int: 2\"];\n  n5 -> n6;\n")
+
+	assert.Error(t, debug.PrintAst(&strings.Builder{}, "test.gold", root, debug.FormatJson))
+}
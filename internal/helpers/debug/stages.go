@@ -0,0 +1,322 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/compiler/env"
+	"github.com/renatopp/golden/internal/compiler/ir"
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/codegen"
+)
+
+func printJson(out io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+//
+// Tokens
+//
+
+type jsonToken struct {
+	Kind    string `json:"kind"`
+	Literal string `json:"literal"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Offset  int    `json:"offset"`
+}
+
+func PrintTokens(out io.Writer, file *builder.File, tokens []*token.Token, format Format) error {
+	switch format {
+	case FormatText:
+		fmt.Fprintf(out, "Tokens for module %s:\n", file.Path)
+		for _, t := range tokens {
+			fmt.Fprintf(out, "- %d:%d [%s] (%s)\n", t.Loc.FromLine, t.Loc.FromColumn, t.Display(), Escape(t.Literal))
+		}
+		fmt.Fprintln(out)
+
+	case FormatJson:
+		res := []jsonToken{}
+		for _, t := range tokens {
+			res = append(res, jsonToken{t.Display(), t.Literal, t.Loc.FromLine, t.Loc.FromColumn, t.Loc.FromOffset})
+		}
+		return printJson(out, map[string]any{"module": file.Path, "tokens": res})
+
+	case FormatSexpr:
+		fmt.Fprintf(out, "(tokens %s", quote(file.Path))
+		for _, t := range tokens {
+			fmt.Fprintf(out, "\n  (%s %s :at \"%d:%d\")", quote(t.Display()), quote(t.Literal), t.Loc.FromLine, t.Loc.FromColumn)
+		}
+		fmt.Fprintln(out, ")")
+
+	default:
+		return unsupported("tokens", format)
+	}
+	return nil
+}
+
+//
+// Scopes
+//
+
+type jsonScope struct {
+	Types  map[string]string `json:"types"`
+	Values map[string]string `json:"values"`
+	Parent *jsonScope        `json:"parent,omitempty"`
+}
+
+// Prints the bindings of the scope and its parents, sorted by name.
+func PrintScope(out io.Writer, file *builder.File, scope *env.Scope, format Format) error {
+	switch format {
+	case FormatText:
+		fmt.Fprintf(out, "Scope of module %s:\n", file.Path)
+		for s := scope; s != nil; s = s.Parent {
+			if s != scope {
+				fmt.Fprintln(out, "Parent scope:")
+			}
+			types, values := scopeBindings(s)
+			for _, k := range slices.Sorted(maps.Keys(types)) {
+				fmt.Fprintf(out, "- (T) %s → %s\n", k, types[k])
+			}
+			for _, k := range slices.Sorted(maps.Keys(values)) {
+				fmt.Fprintf(out, "- (V) %s → %s\n", k, values[k])
+			}
+		}
+		fmt.Fprintln(out)
+
+	case FormatJson:
+		root := &jsonScope{}
+		for s, cur := scope, root; s != nil; s = s.Parent {
+			cur.Types, cur.Values = scopeBindings(s)
+			if s.Parent != nil {
+				cur.Parent = &jsonScope{}
+				cur = cur.Parent
+			}
+		}
+		return printJson(out, map[string]any{"module": file.Path, "scope": root})
+
+	case FormatSexpr:
+		fmt.Fprintf(out, "(module %s", quote(file.Path))
+		depth := 1
+		for s := scope; s != nil; s = s.Parent {
+			indent := strings.Repeat("  ", depth)
+			fmt.Fprintf(out, "\n%s(scope", indent)
+			types, values := scopeBindings(s)
+			for _, k := range slices.Sorted(maps.Keys(types)) {
+				fmt.Fprintf(out, "\n%s  (type %s %s)", indent, k, quote(types[k]))
+			}
+			for _, k := range slices.Sorted(maps.Keys(values)) {
+				fmt.Fprintf(out, "\n%s  (value %s %s)", indent, k, quote(values[k]))
+			}
+			depth++
+		}
+		fmt.Fprintln(out, strings.Repeat(")", depth))
+
+	default:
+		return unsupported("scopes", format)
+	}
+	return nil
+}
+
+// Returns the signatures of the types and values bound in the scope.
+func scopeBindings(scope *env.Scope) (map[string]string, map[string]string) {
+	types, values := map[string]string{}, map[string]string{}
+	for k, v := range scope.Types.Bindings {
+		types[k] = "<nil>"
+		if v.Type != nil {
+			types[k] = v.Type.GetSignature()
+		}
+	}
+	for k, v := range scope.Values.Bindings {
+		values[k] = "<nil>"
+		if v.Type != nil {
+			values[k] = v.Type.GetSignature()
+		}
+	}
+	return types, values
+}
+
+//
+// Dependencies
+//
+
+type jsonModule struct {
+	Name string   `json:"name"`
+	Path string   `json:"path"`
+	Deps []string `json:"deps"`
+}
+
+// Prints the module graph, in dependency order, with the modules imported by
+// each module.
+func PrintDependencyGraph(out io.Writer, order []*builder.File, format Format) error {
+	deps := func(file *builder.File) []string { return file.Dependencies() }
+
+	switch format {
+	case FormatText:
+		fmt.Fprintln(out, "Order of dependencies:")
+		for _, f := range order {
+			fmt.Fprintf(out, "- %s\n", f.Path)
+			for _, d := range deps(f) {
+				fmt.Fprintf(out, "  → %s\n", d)
+			}
+		}
+		fmt.Fprintln(out)
+
+	case FormatJson:
+		res := []jsonModule{}
+		for _, f := range order {
+			res = append(res, jsonModule{f.Name, f.Path, deps(f)})
+		}
+		return printJson(out, map[string]any{"modules": res})
+
+	case FormatSexpr:
+		fmt.Fprint(out, "(modules")
+		for _, f := range order {
+			fmt.Fprintf(out, "\n  (module %s %s", f.Name, quote(f.Path))
+			for _, d := range deps(f) {
+				fmt.Fprintf(out, " (dep %s)", quote(d))
+			}
+			fmt.Fprint(out, ")")
+		}
+		fmt.Fprintln(out, ")")
+
+	case FormatDot:
+		fmt.Fprintln(out, "digraph modules {")
+		fmt.Fprintln(out, "  node [shape=box, fontname=monospace];")
+		for _, f := range order {
+			fmt.Fprintf(out, "  %s [label=%s];\n", quote(f.Path), quote(f.Name+"\n"+f.FileName))
+			for _, d := range deps(f) {
+				fmt.Fprintf(out, "  %s -> %s;\n", quote(f.Path), quote(d))
+			}
+		}
+		fmt.Fprintln(out, "}")
+
+	default:
+		return unsupported("dependencies", format)
+	}
+	return nil
+}
+
+//
+// IR
+//
+
+type jsonFunction struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`
+	Blocks []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	Name       string   `json:"name"`
+	Instrs     []string `json:"instrs"`
+	Successors []string `json:"successors"`
+}
+
+// Prints the lowered module. DOT graphs contain the control flow graph of
+// each function.
+func PrintIR(out io.Writer, mod *ir.Module, format Format) error {
+	switch format {
+	case FormatText:
+		fmt.Fprintf(out, "IR for module %s:\n", mod.Path)
+		fmt.Fprintln(out, ir.Dump(mod))
+
+	case FormatJson:
+		globals := map[string]string{}
+		for _, g := range mod.Globals {
+			globals[g.Name] = g.Type.GetSignature()
+		}
+		externs := map[string]any{}
+		for _, f := range mod.Foreigns {
+			externs[f.Name] = map[string]any{"type": f.Type.GetSignature(), "bindings": f.Bindings}
+		}
+		functions := []jsonFunction{}
+		for _, f := range mod.Functions {
+			fn := jsonFunction{Name: f.Name, Type: f.Type.GetSignature(), Blocks: []jsonBlock{}}
+			for _, b := range f.Blocks {
+				fn.Blocks = append(fn.Blocks, jsonBlock{b.String(), blockInstrs(b), blockSuccessors(b)})
+			}
+			functions = append(functions, fn)
+		}
+		return printJson(out, map[string]any{"module": mod.Path, "globals": globals, "externs": externs, "functions": functions})
+
+	case FormatSexpr:
+		fmt.Fprintf(out, "(module %s %s", mod.Name, quote(mod.Path))
+		for _, g := range mod.Globals {
+			fmt.Fprintf(out, "\n  (global @%s %s)", g.Name, quote(g.Type.GetSignature()))
+		}
+		for _, f := range mod.Foreigns {
+			bindings := codegen.JoinList("", f.Bindings, func(b string) string { return " " + quote(b) })
+			fmt.Fprintf(out, "\n  (extern @%s %s%s)", f.Name, quote(f.Type.GetSignature()), bindings)
+		}
+		for _, f := range mod.Functions {
+			fmt.Fprintf(out, "\n  (function @%s %s", f.Name, quote(f.Type.GetSignature()))
+			for _, b := range f.Blocks {
+				fmt.Fprintf(out, "\n    (block %s", b)
+				for _, i := range blockInstrs(b) {
+					fmt.Fprintf(out, "\n      %s", quote(i))
+				}
+				fmt.Fprint(out, ")")
+			}
+			fmt.Fprint(out, ")")
+		}
+		fmt.Fprintln(out, ")")
+
+	case FormatDot:
+		fmt.Fprintf(out, "digraph %s {\n", quote(mod.Path))
+		fmt.Fprintln(out, "  node [shape=box, fontname=monospace];")
+		for _, f := range mod.Functions {
+			fmt.Fprintf(out, "  subgraph %s {\n", quote("cluster_"+f.Name))
+			fmt.Fprintf(out, "    label=%s;\n", quote("@"+f.Name))
+			for _, b := range f.Blocks {
+				label := b.String() + ":\\l" + codegen.JoinList("", blockInstrs(b), func(i string) string { return escapeLabel(i) + "\\l" })
+				fmt.Fprintf(out, "    \"%s.%s\" [label=\"%s\"];\n", f.Name, b, label)
+				for _, s := range blockSuccessors(b) {
+					fmt.Fprintf(out, "    \"%s.%s\" -> \"%s.%s\";\n", f.Name, b, f.Name, s)
+				}
+			}
+			fmt.Fprintln(out, "  }")
+		}
+		fmt.Fprintln(out, "}")
+
+	default:
+		return unsupported("IR", format)
+	}
+	return nil
+}
+
+// Returns the instructions of the block, including the terminator.
+func blockInstrs(b *ir.Block) []string {
+	res := []string{}
+	for _, i := range b.Instrs {
+		res = append(res, fmt.Sprint(i))
+	}
+	if b.Term != nil {
+		res = append(res, fmt.Sprint(b.Term))
+	}
+	return res
+}
+
+func blockSuccessors(b *ir.Block) []string {
+	res := []string{}
+	for _, s := range b.Successors() {
+		res = append(res, s.String())
+	}
+	return res
+}
+
+// Escapes the text to be part of a DOT label, which uses `\l` to break left
+// aligned lines.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,130 @@
+// Package diff compares texts line by line.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Insert
+	Delete
+)
+
+// Op is a line of the comparison, kept, inserted or deleted.
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+// Returns the operations that change the lines of a into the lines of b,
+// using the longest common subsequence of lines.
+func Lines(a, b string) []Op {
+	la, lb := split(a), split(b)
+
+	// lcs[i][j] is the length of the common subsequence of la[i:] and lb[j:]
+	lcs := make([][]int, len(la)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(lb)+1)
+	}
+	for i := len(la) - 1; i >= 0; i-- {
+		for j := len(lb) - 1; j >= 0; j-- {
+			if la[i] == lb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := []Op{}
+	i, j := 0, 0
+	for i < len(la) || j < len(lb) {
+		switch {
+		case i < len(la) && j < len(lb) && la[i] == lb[j]:
+			ops = append(ops, Op{Equal, la[i]})
+			i++
+			j++
+		// deletions come before the insertions that replace them
+		case i < len(la) && (j == len(lb) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, Op{Delete, la[i]})
+			i++
+		default:
+			ops = append(ops, Op{Insert, lb[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// Returns the differences between the texts in the unified format, with the
+// given number of context lines around each change. Equal texts return an
+// empty string.
+func Unified(name, a, b string, context int) string {
+	ops := Lines(a, b)
+
+	// groups the changes with their context in hunks
+	type hunk struct{ from, to int }
+	hunks := []hunk{}
+	for k, op := range ops {
+		if op.Kind == Equal {
+			continue
+		}
+		from, to := max(0, k-context), min(len(ops), k+context+1)
+		if n := len(hunks); n > 0 && hunks[n-1].to >= from {
+			hunks[n-1].to = to
+		} else {
+			hunks = append(hunks, hunk{from, to})
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", name, name)
+	for _, h := range hunks {
+		// line numbers of the first line of the hunk in each text
+		lineA, lineB := 1, 1
+		for _, op := range ops[:h.from] {
+			if op.Kind != Insert {
+				lineA++
+			}
+			if op.Kind != Delete {
+				lineB++
+			}
+		}
+		countA, countB := 0, 0
+		for _, op := range ops[h.from:h.to] {
+			if op.Kind != Insert {
+				countA++
+			}
+			if op.Kind != Delete {
+				countB++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+		for _, op := range ops[h.from:h.to] {
+			switch op.Kind {
+			case Equal:
+				sb.WriteString(" " + op.Line + "\n")
+			case Insert:
+				sb.WriteString("+" + op.Line + "\n")
+			case Delete:
+				sb.WriteString("-" + op.Line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+func split(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
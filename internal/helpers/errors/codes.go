@@ -0,0 +1,170 @@
+package errors
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode identifies a specific error. The values are stable, they are
+// shown as G0301 and they must never be reused or renumbered, since they are
+// referenced by issues, documentation and suppression lists.
+//
+// Codes are grouped by hundreds:
+//
+//	G00xx: internal and general errors
+//	G01xx: files, modules and options
+//	G02xx: lexical and syntax errors
+//	G03xx: type errors
+//	G04xx: name resolution errors
+//	G05xx: constant evaluation errors
+//	G06xx: warnings
+//	G07xx: runtime errors
+type ErrorCode uint64
+
+const (
+	InternalError      ErrorCode = 1
+	NotImplemented     ErrorCode = 2
+	SerializationError ErrorCode = 3
+
+	FileNotFound         ErrorCode = 101
+	InvalidFileExtension ErrorCode = 102
+	FileNotReadable      ErrorCode = 103
+	InvalidModulePath    ErrorCode = 104
+	CircularDependency   ErrorCode = 106
+	MissingMain          ErrorCode = 107
+	InvalidMainSignature ErrorCode = 108
+	UnknownPass          ErrorCode = 109
+
+	UnexpectedCharacter    ErrorCode = 201
+	MissingAnnotationName  ErrorCode = 202
+	UnterminatedString     ErrorCode = 203
+	InvalidEscapeSequence  ErrorCode = 204
+	UnexpectedToken        ErrorCode = 210
+	ExpectedExpression     ErrorCode = 211
+	InvalidNumberLiteral   ErrorCode = 212
+	MissingParameterType   ErrorCode = 213
+	ExpectedTypeExpression ErrorCode = 214
+
+	ArgumentCountMismatch  ErrorCode = 301
+	TypeMismatch           ErrorCode = 302
+	MissingReturn          ErrorCode = 303
+	UnknownAnnotation      ErrorCode = 304
+	ConflictingAnnotations ErrorCode = 305
+	NotCallable            ErrorCode = 306
+	InvalidExternBinding   ErrorCode = 307
+
+	NameNotFound           ErrorCode = 401
+	TypeNotFound           ErrorCode = 402
+	NameAlreadyDefined     ErrorCode = 403
+	CircularInitialization ErrorCode = 404
+	ReservedName           ErrorCode = 405
+
+	IntegerOverflow ErrorCode = 501
+	DivisionByZero  ErrorCode = 502
+
+	UnreachableCode ErrorCode = 601
+
+	RuntimeError       ErrorCode = 701
+	LimitExceeded      ErrorCode = 702
+	CapabilityDisabled ErrorCode = 703
+)
+
+var codeToName = map[ErrorCode]string{
+	InternalError:      "internal error",
+	NotImplemented:     "not implemented",
+	SerializationError: "serialization error",
+
+	FileNotFound:         "file not found",
+	InvalidFileExtension: "invalid file extension",
+	FileNotReadable:      "file not readable",
+	InvalidModulePath:    "invalid module path",
+	CircularDependency:   "circular dependency",
+	MissingMain:          "missing main function",
+	InvalidMainSignature: "invalid main signature",
+	UnknownPass:          "unknown optimization pass",
+
+	UnexpectedCharacter:    "unexpected character",
+	MissingAnnotationName:  "missing annotation name",
+	UnterminatedString:     "unterminated string",
+	InvalidEscapeSequence:  "invalid escape sequence",
+	UnexpectedToken:        "unexpected token",
+	ExpectedExpression:     "expected expression",
+	InvalidNumberLiteral:   "invalid number literal",
+	MissingParameterType:   "missing parameter type",
+	ExpectedTypeExpression: "expected type expression",
+
+	ArgumentCountMismatch:  "argument count mismatch",
+	TypeMismatch:           "type mismatch",
+	MissingReturn:          "missing return",
+	UnknownAnnotation:      "unknown annotation",
+	ConflictingAnnotations: "conflicting annotations",
+	NotCallable:            "value is not callable",
+	InvalidExternBinding:   "invalid extern binding",
+
+	NameNotFound:           "name not found",
+	TypeNotFound:           "type not found",
+	NameAlreadyDefined:     "name already defined",
+	CircularInitialization: "circular initialization",
+	ReservedName:           "reserved name",
+
+	IntegerOverflow: "integer overflow",
+	DivisionByZero:  "division by zero",
+
+	UnreachableCode: "unreachable code",
+
+	RuntimeError:       "runtime error",
+	LimitExceeded:      "limit exceeded",
+	CapabilityDisabled: "capability disabled",
+}
+
+//go:embed explanations/*.md
+var explanations embed.FS
+
+func (c ErrorCode) String() string {
+	return fmt.Sprintf("G%04d", uint64(c))
+}
+
+// Short description of the error.
+func (c ErrorCode) Name() string {
+	if name, ok := codeToName[c]; ok {
+		return name
+	}
+	return "unknown error"
+}
+
+// Returns the long-form explanation of the error, with examples, as
+// printed by `golden explain`. Line endings are normalized, since the files
+// may be checked out with CRLF.
+func (c ErrorCode) Explain() (string, bool) {
+	data, err := explanations.ReadFile("explanations/" + c.String() + ".md")
+	if err != nil {
+		return "", false
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), true
+}
+
+// Returns all the known codes, in order.
+func Codes() []ErrorCode {
+	codes := []ErrorCode{}
+	for code := range codeToName {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Parses a code in the G0301 format. The prefix is optional and case
+// insensitive.
+func ParseCode(s string) (ErrorCode, bool) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "G")
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	code := ErrorCode(n)
+	_, ok := codeToName[code]
+	return code, ok
+}
@@ -0,0 +1,77 @@
+package errors_test
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/renatopp/golden/internal/backend/javascript"
+	"github.com/renatopp/golden/internal/builder"
+	"github.com/renatopp/golden/internal/builder/buildertest"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var example = regexp.MustCompile("(?s)## Example\n\n```golden\n(.*?)```")
+
+// Compiles the source, returning the codes of the error and warnings.
+func compile(t *testing.T, source string) []errors.ErrorCode {
+	codes := []errors.ErrorCode{}
+	for _, d := range diagnose(t, source) {
+		codes = append(codes, d.Code)
+	}
+	return codes
+}
+
+// Compiles the source as main.gold, returning the error and warnings.
+func diagnose(t *testing.T, source string) []errors.GoldenError {
+	dir := buildertest.Project(t, map[string]string{"main.gold": source})
+	opts := buildertest.Options(t, filepath.Join(dir, "main.gold"))
+	opts.OutputTarget = javascript.NewBackend()
+	opts.NoCache = true
+
+	diagnostics := []errors.GoldenError{}
+	opts.OnWarning.Subscribe(func(w errors.GoldenError) { diagnostics = append(diagnostics, w) })
+	if _, err := builder.NewBuilder(opts).Build(); err != nil {
+		diagnostics = append(diagnostics, errors.ToGoldenError(err))
+	}
+	return diagnostics
+}
+
+func TestExplanations(t *testing.T) {
+	for _, code := range errors.Codes() {
+		text, ok := code.Explain()
+		require.True(t, ok, "missing explanation of %s", code)
+		assert.Regexp(t, "^# "+code.String()+": "+code.Name()+"\n", text)
+
+		parsed, ok := errors.ParseCode(code.String())
+		assert.True(t, ok)
+		assert.Equal(t, code, parsed)
+	}
+}
+
+func TestExplanationExamples(t *testing.T) {
+	for _, code := range errors.Codes() {
+		text, _ := code.Explain()
+		match := example.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		t.Run(code.String(), func(t *testing.T) {
+			assert.Contains(t, compile(t, match[1]), code)
+		})
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	code, ok := errors.ParseCode("g0301")
+	assert.True(t, ok)
+	assert.Equal(t, errors.ArgumentCountMismatch, code)
+
+	_, ok = errors.ParseCode("G9999")
+	assert.False(t, ok)
+
+	_, ok = errors.ParseCode("type error")
+	assert.False(t, ok)
+}
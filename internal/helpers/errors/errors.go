@@ -2,57 +2,41 @@ package errors
 
 import (
 	"fmt"
-	"os"
 	"runtime/debug"
-	"strings"
+	"slices"
 
 	"github.com/renatopp/golden/internal/compiler/ast"
 	"github.com/renatopp/golden/internal/compiler/token"
 	"github.com/renatopp/golden/internal/helpers/safe"
 )
 
-type ErrorCode uint64
+type Severity int
 
 const (
-	InternalError ErrorCode = iota
-	NotImplemented
-	InvalidFileError
-	InvalidFolderError
-	CircularReferenceError
-	ParserError
-	TypeError
-	NameNotFound
-	NameAlreadyDefined
-	InvalidEntryFile
-	TemporaryImplementationError
+	SeverityError Severity = iota
+	SeverityWarning
 )
 
-var codeToName = map[ErrorCode]string{
-	InternalError:                "internal error",
-	NotImplemented:               "not implemented",
-	InvalidFileError:             "invalid file error",
-	InvalidFolderError:           "invalid folder error",
-	CircularReferenceError:       "circular reference error",
-	ParserError:                  "parser error",
-	TypeError:                    "type error",
-	NameNotFound:                 "name not found",
-	NameAlreadyDefined:           "name already defined",
-	InvalidEntryFile:             "invalid entry file",
-	TemporaryImplementationError: "temporary implementation error",
+// Label points to a secondary location related to the error, such as the
+// previous definition of a name.
+type Label struct {
+	Loc *token.Span
+	Msg string
 }
 
-//
-//
-//
-
 // GoldenError is a custom error type that contains information about the error
 type GoldenError struct {
-	Loc   safe.Optional[*token.Span]
-	Token safe.Optional[*token.Token]
-	Node  safe.Optional[ast.Node]
-	Code  ErrorCode
-	Msg   string
-	Stack string
+	Loc      safe.Optional[*token.Span]
+	Token    safe.Optional[*token.Token]
+	Node     safe.Optional[ast.Node]
+	Code     ErrorCode
+	Severity Severity
+	Msg      string
+	Labels   []Label  // Secondary locations
+	Notes    []string // Additional context about the error
+	Help     string   // Suggestion to fix the error
+	Fixes    []Fix    // Changes to the source that fix the error
+	Stack    string
 }
 
 func NewError(code ErrorCode, msg string, args ...any) GoldenError {
@@ -62,8 +46,17 @@ func NewError(code ErrorCode, msg string, args ...any) GoldenError {
 	}
 }
 
+// Warnings are reported without interrupting the compilation.
+func NewWarning(code ErrorCode, msg string, args ...any) GoldenError {
+	e := NewError(code, msg, args...)
+	e.Severity = SeverityWarning
+	return e
+}
+
 func (e GoldenError) Error() string { return e.Msg }
 
+func (e GoldenError) IsWarning() bool { return e.Severity == SeverityWarning }
+
 func (e GoldenError) WithLoc(loc *token.Span) GoldenError {
 	e.Loc = safe.Some(loc)
 	return e
@@ -77,7 +70,7 @@ func (e GoldenError) WithToken(token *token.Token) GoldenError {
 
 func (e GoldenError) WithNode(node ast.Node) GoldenError {
 	e.Token = safe.Some(node.GetToken())
-	e.Loc = safe.Some(e.Token.Unwrap().Loc)
+	e.Loc = safe.Some(node.GetSpan())
 	e.Node = safe.Some(node)
 	return e
 }
@@ -92,6 +85,35 @@ func (e GoldenError) WithMessage(msg string, args ...any) GoldenError {
 	return e
 }
 
+// Adds a secondary location to the error. Labels without location are
+// ignored.
+func (e GoldenError) WithLabel(loc *token.Span, msg string, args ...any) GoldenError {
+	if loc == nil {
+		return e
+	}
+	e.Labels = append(slices.Clone(e.Labels), Label{Loc: loc, Msg: fmt.Sprintf(msg, args...)})
+	return e
+}
+
+func (e GoldenError) WithNote(msg string, args ...any) GoldenError {
+	e.Notes = append(slices.Clone(e.Notes), fmt.Sprintf(msg, args...))
+	return e
+}
+
+func (e GoldenError) WithHelp(msg string, args ...any) GoldenError {
+	e.Help = fmt.Sprintf(msg, args...)
+	return e
+}
+
+// Adds a change that fixes the error. Fixes without edits are ignored.
+func (e GoldenError) WithFix(msg string, edits ...Edit) GoldenError {
+	if len(edits) == 0 {
+		return e
+	}
+	e.Fixes = append(slices.Clone(e.Fixes), Fix{Msg: msg, Edits: edits})
+	return e
+}
+
 func (e GoldenError) WithStack(stack string) GoldenError {
 	e.Stack = stack
 	return e
@@ -116,8 +138,6 @@ func WithRecovery(f func()) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = ToGoldenError(r)
-			// err = gerr.WithStack(string(debug.Stack()))
-			println(string(debug.Stack()))
 		}
 	}()
 	f()
@@ -148,70 +168,7 @@ func Throw(code ErrorCode, msg string, args ...any) {
 	panic(NewError(code, msg, args...))
 }
 
-//
-//
-//
-
-func PrettyPrint(e error) {
-	switch e := e.(type) {
-	case GoldenError:
-		prettyGoldenError(e)
-	case *GoldenError:
-		prettyGoldenError(*e)
-	default:
-		prettySimpleError(e)
-	}
-}
-
-func prettySimpleError(e error) {
-	fmt.Printf("Error: %s", e.Error())
-}
-
-func prettyGoldenError(e GoldenError) {
-	if !e.Loc.Has() {
-		prettySimpleError(e)
-		return
-	}
-
-	loc := e.Loc.Unwrap()
-	if loc == nil || loc.Filename == "" {
-		prettySimpleError(e)
-		return
-	}
-
-	filePath := loc.Filename
-	source, err := os.ReadFile(filePath)
-	if err != nil {
-		prettySimpleError(e)
-		return
-	}
-
-	lines := strings.Split(string(source), "\n")
-	fromLine := loc.FromLine
-	fromColumn := loc.FromColumn
-	// toLine := loc.ToLine
-	toColumn := loc.ToColumn
-	columnSpan := max(1, toColumn-fromColumn)
-
-	if fromLine < 1 {
-		fromLine = 1
-	}
-	if fromLine > len(lines) {
-		fromLine = len(lines)
-	}
-
-	targetLine := lines[fromLine-1]
-
-	code := codeToName[e.Code]
-
-	fmt.Printf("[%v] %s at line:%d, column:%d\n", code, filePath, fromLine, fromColumn)
-	fmt.Printf("\n")
-	fmt.Printf("    %s\n", targetLine)
-	fmt.Printf("    %s\n", (strings.Repeat(" ", fromColumn-1) + strings.Repeat("^", columnSpan)))
-	fmt.Printf("\n")
-	fmt.Printf("Error: %s", e.Msg)
-
-	if e.Stack != "" {
-		fmt.Printf("\n%s\n", e.Stack)
-	}
+// Throws an error built with labels, notes or help.
+func ThrowError(e GoldenError) {
+	panic(e)
 }
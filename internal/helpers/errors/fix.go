@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/renatopp/golden/internal/compiler/token"
+)
+
+// Edit replaces the part of the source covered by the span with the text.
+// Empty spans insert the text.
+type Edit struct {
+	Loc  *token.Span
+	Text string
+}
+
+// Fix is a change to the source that fixes an error, as applied by
+// `golden fix` or offered by editors. The edits of a fix are applied
+// together, and they may span more than one file.
+type Fix struct {
+	Msg   string
+	Edits []Edit
+}
+
+func Replace(loc *token.Span, text string) Edit {
+	return Edit{Loc: loc, Text: text}
+}
+
+func InsertBefore(loc *token.Span, text string) Edit {
+	return Edit{Loc: loc.Start(), Text: text}
+}
+
+func InsertAfter(loc *token.Span, text string) Edit {
+	return Edit{Loc: loc.End(), Text: text}
+}
+
+func Delete(loc *token.Span) Edit {
+	return Edit{Loc: loc}
+}
+
+// Checks if any edit of the fix changes the same part of the source as one
+// of the given edits.
+func (f Fix) Overlaps(edits []Edit) bool {
+	for _, a := range f.Edits {
+		for _, b := range edits {
+			if a.Loc.Filename == b.Loc.Filename && overlaps(a.Loc, b.Loc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlaps(a, b *token.Span) bool {
+	if a.FromOffset == b.FromOffset {
+		return true
+	}
+	return a.FromOffset < b.ToOffset && b.FromOffset < a.ToOffset
+}
+
+// Applies the edits to the source of a file. The edits must not overlap and
+// must be inside of the source, otherwise the source is not changed.
+func ApplyEdits(source []byte, edits []Edit) ([]byte, error) {
+	edits = slices.Clone(edits)
+	slices.SortStableFunc(edits, func(a, b Edit) int { return a.Loc.FromOffset - b.Loc.FromOffset })
+
+	res := []byte{}
+	cursor := 0
+	for i, e := range edits {
+		from, to := e.Loc.FromOffset, e.Loc.ToOffset
+		if i > 0 && overlaps(edits[i-1].Loc, e.Loc) {
+			return source, fmt.Errorf("overlapping edits at %s:%d:%d", e.Loc.Filename, e.Loc.FromLine, e.Loc.FromColumn)
+		}
+		if from < cursor || from > to || to > len(source) {
+			return source, fmt.Errorf("invalid edit at %s:%d:%d", e.Loc.Filename, e.Loc.FromLine, e.Loc.FromColumn)
+		}
+		res = append(res, source[cursor:from]...)
+		res = append(res, e.Text...)
+		cursor = to
+	}
+	res = append(res, source[cursor:]...)
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/compiler/token"
+	"github.com/renatopp/golden/internal/helpers/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func span(from, to int) *token.Span {
+	return &token.Span{Filename: "main.gold", FromOffset: from, ToOffset: to}
+}
+
+func TestApplyEdits(t *testing.T) {
+	source := []byte("let a = twise(2)")
+
+	res, err := errors.ApplyEdits(source, []errors.Edit{
+		errors.Replace(span(8, 13), "twice"),
+		errors.InsertBefore(span(0, 3), "-- x\n"),
+		errors.Delete(span(14, 15)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "-- x\nlet a = twice()", string(res))
+
+	_, err = errors.ApplyEdits(source, []errors.Edit{errors.Replace(span(8, 13), "a"), errors.Delete(span(10, 14))})
+	assert.ErrorContains(t, err, "overlapping edits")
+
+	_, err = errors.ApplyEdits(source, []errors.Edit{errors.Delete(span(10, 30))})
+	assert.ErrorContains(t, err, "invalid edit")
+}
+
+func TestFixes(t *testing.T) {
+	cases := []struct {
+		source string
+		fixed  string
+	}{
+		{"fn twice(a Int) Int { a * 2 }\nfn main() { twise(2) }\n", "fn twice(a Int) Int { a * 2 }\nfn main() { twice(2) }\n"},
+		{"fn add(a Int, b) Int { a + b }\nfn main() {}\n", "fn add(a Int, b Int) Int { a + b }\nfn main() {}\n"},
+		{"fn answer() Int {\n  let x = 42\n}\nfn main() {}\n", "fn answer() Int {\n  let x = 42\n  return x\n}\nfn main() {}\n"},
+		{"@inlne\nfn main() {}\n", "@inline\nfn main() {}\n"},
+	}
+
+	for _, c := range cases {
+		diagnostics := diagnose(t, c.source)
+		require.NotEmpty(t, diagnostics)
+		d := diagnostics[len(diagnostics)-1]
+		require.NotEmpty(t, d.Fixes, "no fix for %s: %s", d.Code, d.Msg)
+
+		res, err := errors.ApplyEdits([]byte(c.source), d.Fixes[0].Edits)
+		require.NoError(t, err)
+		assert.Equal(t, c.fixed, string(res))
+		assert.Empty(t, diagnose(t, string(res)))
+	}
+}
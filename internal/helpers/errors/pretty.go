@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/renatopp/golden/internal/compiler/token"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+)
+
+// Colors are used when printing to a terminal, unless the NO_COLOR
+// environment variable is set (https://no-color.org).
+func ColorsEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func PrettyPrint(e error) {
+	fmt.Print(Render(e, ColorsEnabled()))
+}
+
+// Renders the error as printed by PrettyPrint. Golden errors include the
+// excerpts of the source covered by their locations, followed by the notes
+// and help.
+func Render(e error, colors bool) string {
+	return RenderWith(e, RenderOptions{Colors: colors})
+}
+
+type RenderOptions struct {
+	Colors   bool
+	ReadFile func(file string) ([]byte, error) // Source of the excerpts, the disk by default
+	FileName func(file string) string          // Name shown for the file, its path by default
+}
+
+// Renders the error like Render, with the sources and file names given by
+// the options, such as the sources compiled from memory.
+func RenderWith(e error, opts RenderOptions) string {
+	r := &renderer{colors: opts.Colors, readFile: opts.ReadFile, fileName: opts.FileName}
+	if r.readFile == nil {
+		r.readFile = os.ReadFile
+	}
+	if r.fileName == nil {
+		r.fileName = func(file string) string { return file }
+	}
+	switch e := e.(type) {
+	case GoldenError:
+		r.golden(e)
+	case *GoldenError:
+		r.golden(*e)
+	default:
+		r.write("%s: %s", r.paint(colorRed+colorBold, "Error"), e.Error())
+	}
+	return r.out.String()
+}
+
+//
+//
+//
+
+// A location to be underlined in the excerpt.
+type marker struct {
+	loc     *token.Span
+	primary bool
+	msg     string
+}
+
+type renderer struct {
+	out      strings.Builder
+	colors   bool
+	color    string // Color of the primary markers
+	gutter   int    // Width of the line numbers
+	readFile func(file string) ([]byte, error)
+	fileName func(file string) string
+}
+
+func (r *renderer) write(format string, args ...any) {
+	fmt.Fprintf(&r.out, format, args...)
+}
+
+func (r *renderer) paint(color, s string) string {
+	if !r.colors || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (r *renderer) golden(e GoldenError) {
+	label := "error"
+	r.color = colorRed
+	if e.IsWarning() {
+		label = "warning"
+		r.color = colorYellow
+	}
+
+	r.write("%s%s %s",
+		r.paint(r.color+colorBold, fmt.Sprintf("%s[%s]", label, e.Code)),
+		r.paint(colorBold, ":"),
+		r.paint(colorBold, e.Msg),
+	)
+
+	markers := []marker{}
+	if loc := e.Loc.Or(nil); loc != nil && loc.Filename != "" {
+		markers = append(markers, marker{loc: loc, primary: true})
+	}
+	for _, l := range e.Labels {
+		markers = append(markers, marker{loc: l.Loc, msg: l.Msg})
+	}
+
+	r.gutter = 1
+	for _, m := range markers {
+		r.gutter = max(r.gutter, len(strconv.Itoa(m.loc.ToLine)))
+	}
+
+	// excerpts are grouped by file, starting by the file of the primary
+	// location
+	files := []string{}
+	for _, m := range markers {
+		if !slices.Contains(files, m.loc.Filename) {
+			files = append(files, m.loc.Filename)
+		}
+	}
+	for _, file := range files {
+		group := []marker{}
+		for _, m := range markers {
+			if m.loc.Filename == file {
+				group = append(group, m)
+			}
+		}
+		r.excerpt(file, group)
+	}
+
+	if len(e.Notes) > 0 || e.Help != "" || len(e.Fixes) > 0 {
+		r.write("\n%s", r.paint(colorBlue+colorBold, r.pad()+" |"))
+	}
+	for _, note := range e.Notes {
+		r.write("\n%s %s", r.paint(colorBlue+colorBold, r.pad()+" ="), r.paint(colorBold, "note:")+" "+note)
+	}
+	if e.Help != "" {
+		r.write("\n%s %s", r.paint(colorBlue+colorBold, r.pad()+" ="), r.paint(colorCyan+colorBold, "help:")+" "+e.Help)
+	}
+	for _, fix := range e.Fixes {
+		r.write("\n%s %s", r.paint(colorBlue+colorBold, r.pad()+" ="), r.paint(colorCyan+colorBold, "fix:")+" "+fix.Msg+", apply with `golden fix`")
+	}
+
+	if e.Stack != "" {
+		r.write("\n\n%s\n", e.Stack)
+	}
+}
+
+// Prints the lines of the file covered by the markers, with the markers
+// underlining them. Files that cannot be read only have their location
+// printed.
+func (r *renderer) excerpt(file string, markers []marker) {
+	first := markers[0].loc
+	r.write("\n%s %s:%d:%d", r.paint(colorBlue+colorBold, r.pad()+"-->"), r.fileName(file), first.FromLine, first.FromColumn)
+
+	source, err := r.readFile(file)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(source), "\n")
+
+	numbers := []int{}
+	for _, m := range markers {
+		from, to := lineRange(m.loc, len(lines))
+		for i := from; i <= to; i++ {
+			numbers = append(numbers, i)
+		}
+	}
+	slices.Sort(numbers)
+	numbers = slices.Compact(numbers)
+
+	bar := r.paint(colorBlue+colorBold, " |")
+	r.write("\n%s%s", r.pad(), bar)
+	for i, n := range numbers {
+		if i > 0 && n > numbers[i-1]+1 {
+			r.write("\n%s", r.paint(colorBlue+colorBold, "..."))
+		}
+
+		line := strings.TrimRight(lines[n-1], "\r")
+		num := fmt.Sprintf("%*d", r.gutter, n)
+		r.write("\n%s%s %s", r.paint(colorBlue+colorBold, num), bar, line)
+
+		for _, m := range markers {
+			from, to := lineRange(m.loc, len(lines))
+			if n < from || n > to {
+				continue
+			}
+
+			char, color := "-", colorBlue+colorBold
+			if m.primary {
+				char, color = "^", r.color+colorBold
+			}
+			underline := underline(line, m.loc, n, char)
+			if n == to && m.msg != "" {
+				underline += " " + m.msg
+			}
+			r.write("\n%s%s %s", r.pad(), bar, r.paint(color, underline))
+		}
+	}
+}
+
+func (r *renderer) pad() string {
+	return strings.Repeat(" ", r.gutter)
+}
+
+// Returns the first and last lines covered by the span, ignoring the last
+// line when the span ends with a line break.
+func lineRange(loc *token.Span, count int) (int, int) {
+	from := min(max(loc.FromLine, 1), count)
+	to := min(max(loc.ToLine, from), count)
+	if to > from && loc.ToColumn <= 1 {
+		to--
+	}
+	return from, to
+}
+
+// Returns the marker under the part of the line covered by the span. Lines
+// in the middle of a multi-line span are marked from the first non-space
+// character.
+func underline(line string, loc *token.Span, lineNumber int, char string) string {
+	width := utf8.RuneCountInString(line)
+	from := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+	to := width + 1
+	if lineNumber == loc.FromLine {
+		from = loc.FromColumn
+	}
+	if lineNumber == loc.ToLine {
+		to = loc.ToColumn
+	}
+	from = max(from, 1)
+	return strings.Repeat(" ", from-1) + strings.Repeat(char, max(1, to-from))
+}
@@ -1,8 +1,14 @@
 package events
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
+// Signals may be emitted from several goroutines, such as the loaders of the
+// modules, so subscribers must be safe to call concurrently.
 type Signal struct {
+	mu              sync.Mutex
 	subscribers     []func()
 	onceSubscribers []func()
 }
@@ -15,14 +21,20 @@ func NewSignal() *Signal {
 }
 
 func (s *Signal) Subscribe(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = append(s.subscribers, fn)
 }
 
 func (s *Signal) SubscribeOnce(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.onceSubscribers = append(s.onceSubscribers, fn)
 }
 
 func (s *Signal) Unsubscribe(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	fnPointer := reflect.ValueOf(fn).Pointer()
 
 	for i, f := range s.subscribers {
@@ -43,17 +55,32 @@ func (s *Signal) Unsubscribe(fn func()) {
 }
 
 func (s *Signal) Emit() {
-	for _, fn := range s.subscribers {
+	s.mu.Lock()
+	subscribers := append([]func(){}, s.subscribers...)
+	onceSubscribers := s.onceSubscribers
+	s.onceSubscribers = []func(){}
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
 		fn()
 	}
 
-	for _, fn := range s.onceSubscribers {
+	for _, fn := range onceSubscribers {
 		fn()
 	}
-	s.onceSubscribers = []func(){}
+}
+
+// Reports whether the signal has subscribers, so emitters may skip the work
+// of computing the values nobody receives.
+func (s *Signal) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0 || len(s.onceSubscribers) > 0
 }
 
 func (s *Signal) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = []func(){}
 	s.onceSubscribers = []func(){}
 }
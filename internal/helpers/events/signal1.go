@@ -1,8 +1,14 @@
 package events
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
+// Signals may be emitted from several goroutines, such as the loaders of the
+// modules, so subscribers must be safe to call concurrently.
 type Signal1[T any] struct {
+	mu              sync.Mutex
 	subscribers     []func(T)
 	onceSubscribers []func(T)
 }
@@ -15,14 +21,20 @@ func NewSignal1[T any]() *Signal1[T] {
 }
 
 func (s *Signal1[T]) Subscribe(fn func(T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = append(s.subscribers, fn)
 }
 
 func (s *Signal1[T]) SubscribeOnce(fn func(T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.onceSubscribers = append(s.onceSubscribers, fn)
 }
 
 func (s *Signal1[T]) Unsubscribe(fn func(T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	fnPointer := reflect.ValueOf(fn).Pointer()
 
 	for i, f := range s.subscribers {
@@ -43,17 +55,32 @@ func (s *Signal1[T]) Unsubscribe(fn func(T)) {
 }
 
 func (s *Signal1[T]) Emit(a T) {
-	for _, fn := range s.subscribers {
+	s.mu.Lock()
+	subscribers := append([]func(T){}, s.subscribers...)
+	onceSubscribers := s.onceSubscribers
+	s.onceSubscribers = []func(T){}
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
 		fn(a)
 	}
 
-	for _, fn := range s.onceSubscribers {
+	for _, fn := range onceSubscribers {
 		fn(a)
 	}
-	s.onceSubscribers = []func(T){}
+}
+
+// Reports whether the signal has subscribers, so emitters may skip the work
+// of computing the values nobody receives.
+func (s *Signal1[T]) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0 || len(s.onceSubscribers) > 0
 }
 
 func (s *Signal1[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = []func(T){}
 	s.onceSubscribers = []func(T){}
 }
@@ -1,8 +1,14 @@
 package events
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
+// Signals may be emitted from several goroutines, such as the loaders of the
+// modules, so subscribers must be safe to call concurrently.
 type Signal2[T, R any] struct {
+	mu              sync.Mutex
 	subscribers     []func(T, R)
 	onceSubscribers []func(T, R)
 }
@@ -15,14 +21,20 @@ func NewSignal2[T, R any]() *Signal2[T, R] {
 }
 
 func (s *Signal2[T, R]) Subscribe(fn func(T, R)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = append(s.subscribers, fn)
 }
 
 func (s *Signal2[T, R]) SubscribeOnce(fn func(T, R)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.onceSubscribers = append(s.onceSubscribers, fn)
 }
 
 func (s *Signal2[T, R]) Unsubscribe(fn func(T, R)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	fnPointer := reflect.ValueOf(fn).Pointer()
 
 	for i, f := range s.subscribers {
@@ -43,17 +55,32 @@ func (s *Signal2[T, R]) Unsubscribe(fn func(T, R)) {
 }
 
 func (s *Signal2[T, R]) Emit(a T, b R) {
-	for _, fn := range s.subscribers {
+	s.mu.Lock()
+	subscribers := append([]func(T, R){}, s.subscribers...)
+	onceSubscribers := s.onceSubscribers
+	s.onceSubscribers = []func(T, R){}
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
 		fn(a, b)
 	}
 
-	for _, fn := range s.onceSubscribers {
+	for _, fn := range onceSubscribers {
 		fn(a, b)
 	}
-	s.onceSubscribers = []func(T, R){}
+}
+
+// Reports whether the signal has subscribers, so emitters may skip the work
+// of computing the values nobody receives.
+func (s *Signal2[T, R]) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0 || len(s.onceSubscribers) > 0
 }
 
 func (s *Signal2[T, R]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = []func(T, R){}
 	s.onceSubscribers = []func(T, R){}
 }
@@ -1,8 +1,14 @@
 package events
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
+// Signals may be emitted from several goroutines, such as the loaders of the
+// modules, so subscribers must be safe to call concurrently.
 type Signal3[T, R, V any] struct {
+	mu              sync.Mutex
 	subscribers     []func(T, R, V)
 	onceSubscribers []func(T, R, V)
 }
@@ -15,14 +21,20 @@ func NewSignal3[T, R, V any]() *Signal3[T, R, V] {
 }
 
 func (s *Signal3[T, R, V]) Subscribe(fn func(T, R, V)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = append(s.subscribers, fn)
 }
 
 func (s *Signal3[T, R, V]) SubscribeOnce(fn func(T, R, V)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.onceSubscribers = append(s.onceSubscribers, fn)
 }
 
 func (s *Signal3[T, R, V]) Unsubscribe(fn func(T, R, V)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	fnPointer := reflect.ValueOf(fn).Pointer()
 
 	for i, f := range s.subscribers {
@@ -43,17 +55,32 @@ func (s *Signal3[T, R, V]) Unsubscribe(fn func(T, R, V)) {
 }
 
 func (s *Signal3[T, R, V]) Emit(a T, b R, c V) {
-	for _, fn := range s.subscribers {
+	s.mu.Lock()
+	subscribers := append([]func(T, R, V){}, s.subscribers...)
+	onceSubscribers := s.onceSubscribers
+	s.onceSubscribers = []func(T, R, V){}
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
 		fn(a, b, c)
 	}
 
-	for _, fn := range s.onceSubscribers {
+	for _, fn := range onceSubscribers {
 		fn(a, b, c)
 	}
-	s.onceSubscribers = []func(T, R, V){}
+}
+
+// Reports whether the signal has subscribers, so emitters may skip the work
+// of computing the values nobody receives.
+func (s *Signal3[T, R, V]) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0 || len(s.onceSubscribers) > 0
 }
 
 func (s *Signal3[T, R, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.subscribers = []func(T, R, V){}
 	s.onceSubscribers = []func(T, R, V){}
 }
@@ -20,11 +20,16 @@ var Separator = "/"
 var WorkingDir = ""
 var GlobalLangDir = ""
 
+// Directory of the standard library. Its modules are embedded in the compiler
+// and read from the binary, nothing is stored at this path.
+var StdDir = ""
+
 func init() {
 	Separator = string(filepath.Separator)
 	WorkingDir, _ = os.Getwd()
 	GlobalLangDir, _ = os.UserCacheDir()
 	GlobalLangDir, _ = GetAbsolutePath(filepath.Join(GlobalLangDir, ".golden"))
+	StdDir = filepath.Join(GlobalLangDir, "std")
 }
 
 // Checks ---------------------------------------------------------------------
@@ -77,7 +82,17 @@ func IsModuleNameValid(moduleName string) bool {
 }
 
 func IsProjectPath(path string) bool {
-	return strings.HasPrefix(path, WorkingDir)
+	return IsPathInside(WorkingDir, path)
+}
+
+// Checks if the path is inside the given root directory
+func IsPathInside(root, path string) bool {
+	return strings.HasPrefix(path, root)
+}
+
+// Checks if the module is part of the standard library
+func IsStdPath(path string) bool {
+	return IsPathInside(StdDir+Separator, path)
 }
 
 // General Utilities ----------------------------------------------------------
@@ -124,7 +139,12 @@ func GetAbsolutePath(path string) (string, error) {
 
 // Get the relative path of a file from the project root
 func GetProjectRelativePath(path string) string {
-	return strings.TrimPrefix(path, WorkingDir)
+	return GetRelativePath(WorkingDir, path)
+}
+
+// Get the relative path of a file from the given root directory
+func GetRelativePath(root, path string) string {
+	return strings.TrimPrefix(path, root)
 }
 
 // From a generic file path, returns the file extension (with dot)
@@ -0,0 +1,20 @@
+package ids
+
+import "sync/atomic"
+
+// Generator allocates sequential identifiers, starting after `start`. It is
+// safe for concurrent use, but the identifiers are only deterministic when
+// they are requested in a deterministic order.
+type Generator struct {
+	last atomic.Uint64
+}
+
+func NewGenerator(start uint64) *Generator {
+	g := &Generator{}
+	g.last.Store(start)
+	return g
+}
+
+func (g *Generator) Next() uint64 {
+	return g.last.Add(1)
+}
@@ -1,6 +1,7 @@
 package str
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/renatopp/golden/internal/helpers/iter"
@@ -105,3 +106,57 @@ func Repeat(s string, n int) string {
 	}
 	return strings.Repeat(s, n)
 }
+
+// Returns the edit distance (optimal string alignment) between the two
+// strings, the number of insertions, deletions, substitutions and swaps of
+// adjacent characters to change one into the other. Swaps are the most common
+// typo, so they count as a single edit.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	before := make([]int, len(rb)+1) // row of the character before the previous one
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], before[j-2]+1)
+			}
+		}
+		before, prev, cur = prev, cur, before
+	}
+	return prev[len(rb)]
+}
+
+// Returns the candidates close enough to the target to be a likely typo,
+// ordered from the closest one.
+func Closest(target string, candidates []string) []string {
+	limit := max(1, len([]rune(target))/3)
+	distances := map[string]int{}
+	res := []string{}
+	for _, c := range candidates {
+		d := Distance(strings.ToLower(target), strings.ToLower(c))
+		if c == target || d > limit {
+			continue
+		}
+		if _, ok := distances[c]; !ok {
+			res = append(res, c)
+		}
+		distances[c] = d
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if distances[res[i]] != distances[res[j]] {
+			return distances[res[i]] < distances[res[j]]
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
@@ -0,0 +1,38 @@
+package str_test
+
+import (
+	"testing"
+
+	"github.com/renatopp/golden/internal/helpers/str"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"value", "value", 0},
+		{"", "abc", 3},
+		{"value", "valeu", 1},
+		{"ab", "ba", 1},
+		{"println", "pritnln", 1},
+		{"value", "vlue", 1},
+		{"value", "valves", 2},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, str.Distance(tt.a, tt.b), "%s -> %s", tt.a, tt.b)
+		assert.Equal(t, tt.expected, str.Distance(tt.b, tt.a), "%s -> %s", tt.b, tt.a)
+	}
+}
+
+func TestClosest(t *testing.T) {
+	assert.Equal(t, []string{"value"}, str.Closest("valeu", []string{"value", "main"}))
+	assert.Equal(t, []string{"add"}, str.Closest("dad", []string{"add", "sub"}))
+	assert.Equal(t, []string{"count"}, str.Closest("coutn", []string{"counts", "count", "limit"}))
+	assert.Empty(t, str.Closest("value", []string{"value", "other"}))
+	assert.Empty(t, str.Closest("ab", []string{"xy"}))
+}
@@ -6,19 +6,16 @@ import (
 )
 
 func GenerateString(tmpl *template.Template, data any) string {
-	var buf bytes.Buffer
-	err := tmpl.Execute(&buf, data)
-	if err != nil {
-		panic(err)
-	}
-	return buf.String()
+	return string(GenerateBytes(tmpl, data))
 }
 
+// Generates the template, with the line endings of the generated code
+// normalized to `\n`, regardless of the line endings of the template file.
 func GenerateBytes(tmpl *template.Template, data any) []byte {
 	var buf bytes.Buffer
 	err := tmpl.Execute(&buf, data)
 	if err != nil {
 		panic(err)
 	}
-	return buf.Bytes()
+	return bytes.ReplaceAll(buf.Bytes(), []byte("\r\n"), []byte("\n"))
 }